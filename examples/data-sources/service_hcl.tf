@@ -0,0 +1,15 @@
+# Generate the configuration of an existing service that isn't managed by
+# Terraform, to adopt it with an `import` block.
+data "fastly_service_hcl" "example" {
+  service_id    = "SU1Z0isxPaozGVKXdv0eY"
+  resource_name = "example"
+}
+
+resource "local_file" "example" {
+  content  = data.fastly_service_hcl.example.hcl
+  filename = "${path.module}/example_service.tf"
+}
+
+output "required_variables" {
+  value = data.fastly_service_hcl.example.variables
+}
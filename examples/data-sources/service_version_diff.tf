@@ -0,0 +1,27 @@
+# The service has been activated before, and further changes are left in a
+# draft version for review.
+resource "fastly_service_vcl" "example" {
+  name     = "Example Service"
+  activate = false
+
+  domain {
+    name = "example.com"
+  }
+
+  backend {
+    address = "127.0.0.1"
+    name    = "localhost"
+  }
+
+  force_destroy = true
+}
+
+data "fastly_service_version_diff" "example" {
+  service_id   = fastly_service_vcl.example.id
+  from_version = fastly_service_vcl.example.active_version
+  to_version   = fastly_service_vcl.example.cloned_version
+}
+
+output "pending_changes" {
+  value = data.fastly_service_version_diff.example.changes
+}
@@ -0,0 +1,10 @@
+data "fastly_tls_activations" "expiring" {
+  domain_glob                     = "*.example.com"
+  certificate_expires_within_days = 30
+}
+
+output "expiring_activations" {
+  value = {
+    for a in data.fastly_tls_activations.expiring.activations : a.domain => a.certificate_not_after
+  }
+}
@@ -0,0 +1,9 @@
+data "fastly_tls_domains" "example" {
+  configuration_id = fastly_tls_configuration.example.id
+}
+
+output "domain_certificates" {
+  value = {
+    for d in data.fastly_tls_domains.example.domains : d.domain => d.certificates[*].not_after
+  }
+}
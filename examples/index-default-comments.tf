@@ -0,0 +1,3 @@
+provider "fastly" {
+  default_version_comment = "Deployed from {{env.GITHUB_SHA}} (${terraform.workspace}) at {{timestamp}}"
+}
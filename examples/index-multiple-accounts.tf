@@ -0,0 +1,31 @@
+provider "fastly" {
+  api_key = var.fastly_api_key
+
+  credentials {
+    name    = "production"
+    api_key = var.fastly_production_api_key
+  }
+}
+
+# Created with the credentials of the "production" account.
+resource "fastly_service_vcl" "production" {
+  account = "production"
+  name    = "production"
+
+  domain {
+    name = "www.example.com"
+  }
+
+  force_destroy = true
+}
+
+# Created with the provider's api_key.
+resource "fastly_service_vcl" "staging" {
+  name = "staging"
+
+  domain {
+    name = "staging.example.com"
+  }
+
+  force_destroy = true
+}
@@ -0,0 +1,45 @@
+# IMPORTANT: Deleting a Secret Store requires first deleting its resource_link.
+# This requires a two-step `terraform apply` as we can't guarantee deletion order.
+# e.g. resource_link deletion within fastly_service_compute might not finish first.
+resource "fastly_secretstore" "example" {
+  name = "my_secret_store"
+}
+
+variable "api_token" {
+  type      = string
+  sensitive = true
+}
+
+resource "fastly_secretstore_secret" "example" {
+  store_id = fastly_secretstore.example.id
+  name     = "api_token"
+
+  # Write-only arguments are never persisted to the Terraform state.
+  # Increment secret_wo_version to upload a new value.
+  secret_wo         = var.api_token
+  secret_wo_version = 1
+}
+
+resource "fastly_service_compute" "example" {
+  name = "my_compute_service"
+
+  domain {
+    name = "demo.example.com"
+  }
+
+  package {
+    filename         = "package.tar.gz"
+    source_code_hash = data.fastly_package_hash.example.hash
+  }
+
+  resource_link {
+    name        = "my_resource_link"
+    resource_id = fastly_secretstore.example.id
+  }
+
+  force_destroy = true
+}
+
+data "fastly_package_hash" "example" {
+  filename = "package.tar.gz"
+}
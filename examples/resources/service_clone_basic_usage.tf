@@ -0,0 +1,22 @@
+resource "fastly_service_clone" "staging" {
+  source_service_id = "SU1Z0isxPaozGVKXdv0eY"
+  name              = "example (staging)"
+  domains           = ["staging.example.com"]
+
+  backend_addresses = {
+    origin = "staging-origin.example.com"
+  }
+
+  logging_endpoint_names = {
+    "production logs" = "staging logs"
+  }
+
+  dictionary_items {
+    name = "settings"
+    items = {
+      environment = "staging"
+    }
+  }
+
+  force_destroy = true
+}
@@ -0,0 +1,49 @@
+resource "fastly_service_vcl" "example" {
+  name     = "demofastly"
+  activate = false
+
+  domain {
+    name    = "demo.notexample.com"
+    comment = "demo"
+  }
+
+  backend {
+    address = "127.0.0.1"
+    name    = "localhost"
+    port    = 80
+  }
+
+  force_destroy = true
+}
+
+resource "fastly_service_rollout" "example" {
+  service_id = fastly_service_vcl.example.id
+  version    = fastly_service_vcl.example.cloned_version
+
+  # The IP address of the Fastly staging environment for the service.
+  staging_ip = var.fastly_staging_ip
+
+  staging_check {
+    max_attempts     = 5
+    attempt_interval = "10s"
+
+    probe {
+      url             = "https://demo.notexample.com/health"
+      expected_status = 200
+    }
+  }
+
+  activation_check {
+    initial_delay = "30s"
+
+    probe {
+      url           = "https://demo.notexample.com/"
+      expected_body = "Welcome"
+    }
+  }
+
+  timeouts {
+    create = "15m"
+    update = "15m"
+  }
+}
@@ -0,0 +1,24 @@
+resource "fastly_service_vcl" "example" {
+  name = "Example Service"
+
+  # Changes are applied to a new draft version, which is activated below.
+  activate = false
+
+  domain {
+    name = "example.com"
+  }
+
+  backend {
+    address = "127.0.0.1"
+    name    = "localhost"
+  }
+
+  force_destroy = true
+}
+
+resource "fastly_service_version_activation" "example" {
+  service_id           = fastly_service_vcl.example.id
+  version              = fastly_service_vcl.example.cloned_version
+  rollback_on_destroy  = true
+  wait_for_propagation = "30s"
+}
@@ -0,0 +1,14 @@
+resource "fastly_service_version_activation" "staging" {
+  service_id  = fastly_service_vcl.example.id
+  version     = fastly_service_vcl.example.cloned_version
+  environment = "staging"
+}
+
+# Smoke tests (e.g. a data source or provisioner) can depend on the staging
+# activation, and the production activation can depend on the smoke tests.
+resource "fastly_service_version_activation" "production" {
+  service_id = fastly_service_vcl.example.id
+  version    = fastly_service_vcl.example.cloned_version
+
+  depends_on = [fastly_service_version_activation.staging]
+}
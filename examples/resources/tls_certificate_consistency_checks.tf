@@ -0,0 +1,12 @@
+resource "fastly_tls_private_key" "key" {
+  key_pem = file("${path.module}/key.pem")
+  name    = "tf-demo"
+}
+
+resource "fastly_tls_certificate" "cert" {
+  certificate_body = file("${path.module}/certificate.pem")
+
+  # Fail the plan if the certificate wasn't issued for the key, or for these domains.
+  expected_public_key_sha256 = fastly_tls_private_key.key.public_key_sha256
+  domains                    = ["example.com", "www.example.com"]
+}
@@ -0,0 +1,11 @@
+resource "fastly_tls_certificate" "demo" {
+  certificate_body = file("${path.module}/certificate.pem")
+
+  # Fail the plan once the certificate expires within 30 days.
+  expiry_warning_days     = 30
+  expiry_warning_severity = "error"
+}
+
+output "certificate_expiry" {
+  value = fastly_tls_certificate.demo.not_after
+}
@@ -0,0 +1,27 @@
+resource "fastly_service_vcl" "demo" {
+  name = "my-service"
+
+  domain {
+    name = "example.com"
+  }
+
+  backend {
+    address = "127.0.0.1"
+    name    = "localhost"
+  }
+
+  force_destroy = true
+}
+
+# Replacing the key and certificate rotates them without downtime.
+resource "fastly_tls_certificate_rotation" "demo" {
+  name             = "demo"
+  key_pem          = file("${path.module}/example.com.key")
+  certificate_body = file("${path.module}/example.com.crt")
+}
+
+resource "fastly_tls_activation" "demo" {
+  certificate_id = fastly_tls_certificate_rotation.demo.certificate_id
+  domain         = "example.com"
+  depends_on     = [fastly_service_vcl.demo]
+}
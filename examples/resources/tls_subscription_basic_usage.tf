@@ -60,25 +60,16 @@ resource "fastly_tls_subscription" "example" {
 }
 
 resource "aws_route53_record" "domain_validation" {
-  depends_on = [fastly_tls_subscription.example]
-
-  for_each = {
-    # The following `for` expression (due to the outer {}) will produce an object with key/value pairs.
-    # The 'key' is the domain name we've configured (e.g. a.example.com, b.example.com)
-    # The 'value' is a specific 'challenge' object whose record_name matches the domain (e.g. record_name is _acme-challenge.a.example.com).
-    for domain in fastly_tls_subscription.example.domains :
-    domain => element([
-      for obj in fastly_tls_subscription.example.managed_dns_challenges :
-      obj if obj.record_name == "_acme-challenge.${domain}" # We use an `if` conditional to filter the list to a single element
-    ], 0)                                                   # `element()` returns the first object in the list which should be the relevant 'challenge' object we need
-  }
+  # The domains of `dns_records` are known at plan time, so they can be used as keys (e.g. a.example.com, b.example.com).
+  # The value is the 'challenge' record of the domain (e.g. record_name is _acme-challenge.a.example.com).
+  for_each = { for record in fastly_tls_subscription.example.dns_records : record.domain => record }
 
   name            = each.value.record_name
   type            = each.value.record_type
   zone_id         = aws_route53_zone.production.zone_id
   allow_overwrite = true
   records         = [each.value.record_value]
-  ttl             = 60
+  ttl             = each.value.ttl
 }
 
 # This is a resource that other resources can depend on if they require the certificate to be issued.
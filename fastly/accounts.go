@@ -0,0 +1,117 @@
+package fastly
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// accountImportSeparator separates the account from the ID of an imported
+// resource, e.g. `production:SU1Z0isxPaozGVKXdv0eY`.
+const accountImportSeparator = ":"
+
+// addAccountSupport adds the `account` argument to every resource and data
+// source of the provider, and wraps their functions so that they are called
+// with the client of the selected account.
+//
+// Resources and data sources are written against `meta.(*APIClient)`, so they
+// don't need to know which account they manage.
+func addAccountSupport(provider *schema.Provider) {
+	for _, r := range provider.ResourcesMap {
+		r.Schema["account"] = &schema.Schema{
+			Type:        schema.TypeString,
+			Optional:    true,
+			ForceNew:    true,
+			Description: "The name of the provider `credentials` of the Fastly account to manage the resource in. Defaults to the account of the provider's `api_key`",
+		}
+		wrapResourceAccount(r)
+	}
+	for _, r := range provider.DataSourcesMap {
+		r.Schema["account"] = &schema.Schema{
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "The name of the provider `credentials` of the Fastly account to read from. Defaults to the account of the provider's `api_key`",
+		}
+		wrapResourceAccount(r)
+	}
+}
+
+// accountClient returns the client of the account selected by the `account`
+// argument. meta is returned as is when the provider isn't configured.
+func accountClient(account string, meta any) (any, error) {
+	client, ok := meta.(*APIClient)
+	if !ok {
+		return meta, nil
+	}
+	return client.forAccount(account)
+}
+
+// wrapResourceAccount wraps the functions of a resource so that they are
+// called with the client of the account selected by `account`.
+func wrapResourceAccount(r *schema.Resource) {
+	wrap := func(f func(context.Context, *schema.ResourceData, any) diag.Diagnostics) func(context.Context, *schema.ResourceData, any) diag.Diagnostics {
+		if f == nil {
+			return nil
+		}
+		return func(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+			client, err := accountClient(d.Get("account").(string), meta)
+			if err != nil {
+				return diag.FromErr(err)
+			}
+			return f(ctx, d, client)
+		}
+	}
+	r.CreateContext = wrap(r.CreateContext)
+	r.ReadContext = wrap(r.ReadContext)
+	r.UpdateContext = wrap(r.UpdateContext)
+	r.DeleteContext = wrap(r.DeleteContext)
+	r.CreateWithoutTimeout = wrap(r.CreateWithoutTimeout)
+	r.ReadWithoutTimeout = wrap(r.ReadWithoutTimeout)
+	r.UpdateWithoutTimeout = wrap(r.UpdateWithoutTimeout)
+	r.DeleteWithoutTimeout = wrap(r.DeleteWithoutTimeout)
+
+	if customizeDiff := r.CustomizeDiff; customizeDiff != nil {
+		r.CustomizeDiff = func(ctx context.Context, rd *schema.ResourceDiff, meta any) error {
+			client, err := accountClient(rd.Get("account").(string), meta)
+			if err != nil {
+				return err
+			}
+			return customizeDiff(ctx, rd, client)
+		}
+	}
+
+	if r.Importer != nil && r.Importer.StateContext != nil {
+		importState := r.Importer.StateContext
+		r.Importer.StateContext = func(ctx context.Context, d *schema.ResourceData, meta any) ([]*schema.ResourceData, error) {
+			if err := importAccount(d, meta); err != nil {
+				return nil, err
+			}
+			client, err := accountClient(d.Get("account").(string), meta)
+			if err != nil {
+				return nil, err
+			}
+			return importState(ctx, d, client)
+		}
+	}
+}
+
+// importAccount selects the account of an imported resource. Resources of an
+// account other than the default one are imported with an ID prefixed with
+// the name of the account's credentials, e.g. `production:<id>`.
+func importAccount(d *schema.ResourceData, meta any) error {
+	client, ok := meta.(*APIClient)
+	if !ok {
+		return nil
+	}
+	account, id, found := strings.Cut(d.Id(), accountImportSeparator)
+	if !found {
+		return nil
+	}
+	if _, ok := client.accounts[account]; !ok {
+		return nil
+	}
+	d.SetId(id)
+	return d.Set("account", account)
+}
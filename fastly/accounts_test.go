@@ -0,0 +1,252 @@
+package fastly
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+
+	gofastly "github.com/fastly/go-fastly/v12/fastly"
+)
+
+func TestAddAccountSupport(t *testing.T) {
+	provider := Provider()
+	for name, r := range provider.ResourcesMap {
+		if s, ok := r.Schema["account"]; !ok || !s.ForceNew {
+			t.Errorf("expected resource %s to have a ForceNew account argument", name)
+		}
+	}
+	for name, r := range provider.DataSourcesMap {
+		if _, ok := r.Schema["account"]; !ok {
+			t.Errorf("expected data source %s to have an account argument", name)
+		}
+	}
+
+	// Every function must select the client of the account, which fails for
+	// unknown credentials before the function is called.
+	client := testAccountsClient(t)
+	resources := map[string]*schema.Resource{}
+	for name, r := range provider.ResourcesMap {
+		resources["resource "+name] = r
+	}
+	for name, r := range provider.DataSourcesMap {
+		resources["data source "+name] = r
+	}
+	for name, r := range resources {
+		for op, f := range map[string]func(context.Context, *schema.ResourceData, any) diag.Diagnostics{
+			"CreateContext":        r.CreateContext,
+			"ReadContext":          r.ReadContext,
+			"UpdateContext":        r.UpdateContext,
+			"DeleteContext":        r.DeleteContext,
+			"CreateWithoutTimeout": r.CreateWithoutTimeout,
+			"ReadWithoutTimeout":   r.ReadWithoutTimeout,
+			"UpdateWithoutTimeout": r.UpdateWithoutTimeout,
+			"DeleteWithoutTimeout": r.DeleteWithoutTimeout,
+		} {
+			if f == nil {
+				continue
+			}
+			d := schema.TestResourceDataRaw(t, r.Schema, map[string]any{"account": "unknown"})
+			if err := callWithRecover(func() error { return diagToErr(f(context.Background(), d, client)) }); err == nil || !strings.Contains(err.Error(), `no credentials named "unknown"`) {
+				t.Errorf("expected %s of %s to select the account, got %v", op, name, err)
+			}
+		}
+	}
+}
+
+// callWithRecover calls f, returning a panic as an error.
+func callWithRecover(f func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return f()
+}
+
+func TestWrapResourceAccount(t *testing.T) {
+	client := testAccountsClient(t)
+
+	var got any
+	r := &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"account": {Type: schema.TypeString, Optional: true},
+		},
+		ReadContext: func(_ context.Context, _ *schema.ResourceData, meta any) diag.Diagnostics {
+			got = meta
+			return nil
+		},
+	}
+	wrapResourceAccount(r)
+
+	for account, want := range map[string]*APIClient{
+		"":           client,
+		"production": client.accounts["production"],
+	} {
+		d := schema.TestResourceDataRaw(t, r.Schema, map[string]any{"account": account})
+		if diags := r.ReadContext(context.Background(), d, client); diags.HasError() {
+			t.Fatalf("unexpected error: %s", diagToErr(diags))
+		}
+		if got != want {
+			t.Errorf("expected account %q to select its client", account)
+		}
+	}
+
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]any{"account": "missing"})
+	if diags := r.ReadContext(context.Background(), d, client); !diags.HasError() {
+		t.Errorf("expected an error for unknown credentials")
+	}
+}
+
+func TestImportAccount(t *testing.T) {
+	client := testAccountsClient(t)
+	s := map[string]*schema.Schema{
+		"account": {Type: schema.TypeString, Optional: true},
+	}
+
+	for id, want := range map[string][2]string{
+		"production:SU1Z0isxPaozGVKXdv0eY": {"SU1Z0isxPaozGVKXdv0eY", "production"},
+		"SU1Z0isxPaozGVKXdv0eY":            {"SU1Z0isxPaozGVKXdv0eY", ""},
+		"other:SU1Z0isxPaozGVKXdv0eY":      {"other:SU1Z0isxPaozGVKXdv0eY", ""},
+	} {
+		d := schema.TestResourceDataRaw(t, s, map[string]any{})
+		d.SetId(id)
+		if err := importAccount(d, client); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if got := [2]string{d.Id(), d.Get("account").(string)}; got != want {
+			t.Errorf("expected %q to import as %v, got %v", id, want, got)
+		}
+	}
+}
+
+func TestConfigClient_credentials(t *testing.T) {
+	client := testAccountsClient(t)
+
+	production := client.accounts["production"]
+	if production.conn.Address != "http://localhost" {
+		t.Errorf("expected credentials to default to the provider's base URL, got %q", production.conn.Address)
+	}
+	if staging := client.accounts["staging"]; staging.conn.Address != "http://staging.localhost" {
+		t.Errorf("expected credentials to use their base URL, got %q", staging.conn.Address)
+	}
+	if production.maxConcurrentRequests != client.maxConcurrentRequests {
+		t.Errorf("expected accounts to share the settings of the default client")
+	}
+
+	// The API rate limits each API key separately, so the accounts only share
+	// the underlying transport, not the rate limit state of the retries.
+	retries := client.conn.HTTPClient.Transport.(*retryTransport)
+	productionRetries := production.conn.HTTPClient.Transport.(*retryTransport)
+	if retries == productionRetries {
+		t.Errorf("expected accounts to have their own retries")
+	}
+	if retries.transport != productionRetries.transport {
+		t.Errorf("expected accounts to share the transport")
+	}
+
+	for name, credentials := range map[string][]CredentialsConfig{
+		"duplicate": {{Name: "production", APIKey: "a"}, {Name: "production", APIKey: "b"}},
+		"no key":    {{Name: "production"}},
+	} {
+		c := Config{APIKey: "someapikey", BaseURL: "http://localhost", Credentials: credentials}
+		if _, diags := c.Client(); !diags.HasError() {
+			t.Errorf("expected an error for %s credentials", name)
+		}
+	}
+}
+
+func TestAccFastlyAccounts_serviceVCL(t *testing.T) {
+	var service gofastly.ServiceDetail
+	name := fmt.Sprintf("tf-test-%s", acctest.RandString(10))
+	domain := fmt.Sprintf("fastly-test.%s.com", name)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheckSecondaryAccount(t)
+			testAccPreCheck(t)
+		},
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      testAccCheckServiceVCLDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccAccountsServiceVCLConfig(name, domain),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("fastly_service_vcl.foo", "account", testAccSecondaryAccount),
+					testAccCheckServiceExists("fastly_service_vcl.foo", &service),
+					testAccCheckFastlyServiceVCLAttributes(&service, name, []string{domain}),
+					testAccCheckServiceNotInDefaultAccount("fastly_service_vcl.foo"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckServiceNotInDefaultAccount(n string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("not found: %s", n)
+		}
+
+		conn := testAccProvider.Meta().(*APIClient).conn
+		_, err := conn.GetServiceDetails(context.TODO(), &gofastly.GetServiceInput{
+			ServiceID: rs.Primary.ID,
+		})
+		if err == nil {
+			return fmt.Errorf("expected Service (%s) not to be in the default account", rs.Primary.ID)
+		}
+		return nil
+	}
+}
+
+func testAccAccountsServiceVCLConfig(name, domain string) string {
+	return fmt.Sprintf(`
+provider "fastly" {
+  credentials {
+    name    = "%s"
+    api_key = "%s"
+  }
+}
+
+resource "fastly_service_vcl" "foo" {
+  account = "%s"
+  name    = "%s"
+
+  domain {
+    name = "%s"
+  }
+
+  backend {
+    address = "aws.amazon.com"
+    name    = "amazon docs"
+  }
+
+  force_destroy = true
+}`, testAccSecondaryAccount, os.Getenv("FASTLY_SECONDARY_API_KEY"), testAccSecondaryAccount, name, domain)
+}
+
+// testAccountsClient returns a client with the credentials of a production
+// and a staging account.
+func testAccountsClient(t *testing.T) *APIClient {
+	c := Config{
+		APIKey:  "someapikey",
+		BaseURL: "http://localhost",
+		Credentials: []CredentialsConfig{
+			{Name: "production", APIKey: "productionapikey"},
+			{Name: "staging", APIKey: "stagingapikey", BaseURL: "http://staging.localhost"},
+		},
+	}
+	client, diags := c.Client()
+	if diags.HasError() {
+		t.Fatalf("failed to create client: %s", diagToErr(diags))
+	}
+	return client
+}
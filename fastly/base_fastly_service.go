@@ -52,6 +52,19 @@ func (d *BaseServiceDefinition) GetAttributeHandler() []ServiceAttributeDefiniti
 	return d.Attributes
 }
 
+// versionlessAttributes are the attributes of a service resource that can be
+// updated without creating a new version.
+var versionlessAttributes = map[string]bool{
+	"activation_check":       true,
+	"comment":                true,
+	"drift_policy":           true,
+	"name":                   true,
+	"preflight_checks":       true,
+	"source_snapshot":        true,
+	"source_snapshot_blocks": true,
+	"version_comment":        true,
+}
+
 // resourceService returns a Terraform resource schema for VCL or Compute.
 func resourceService(serviceDef ServiceDefinition) *schema.Resource {
 	s := &schema.Resource{
@@ -62,11 +75,11 @@ func resourceService(serviceDef ServiceDefinition) *schema.Resource {
 		Importer:      resourceImport(),
 		CustomizeDiff: customdiff.All(
 			customdiff.ComputedIf("cloned_version", func(_ context.Context, d *schema.ResourceDiff, _ any) bool {
-				// If anything other than name, comment and version_comment has changed, the current version will be
-				// cloned in resourceServiceUpdate so set it as recomputed. These three fields can be updated without
-				// creating a new version
+				// If anything other than the versionless attributes has changed, the current version will be cloned in
+				// resourceServiceUpdate so set it as recomputed. These attributes can be updated without creating a new
+				// version
 				for _, changedKey := range d.GetChangedKeysPrefix("") {
-					if changedKey == "name" || changedKey == "comment" || changedKey == "version_comment" {
+					if versionlessAttributes[strings.SplitN(changedKey, ".", 2)[0]] {
 						continue
 					}
 					return true
@@ -86,6 +99,11 @@ func resourceService(serviceDef ServiceDefinition) *schema.Resource {
 			validateUniqueNames("backend"),
 			validateUniqueNames("rate_limiter"),
 			validateUniqueNames("snippet"),
+			customizeDiffServiceComments,
+			validateServiceVCL,
+			preflightServiceChecks,
+			customizeDiffServiceSnapshot(serviceDef),
+			customizeDiffServiceDrift,
 		),
 		Schema: map[string]*schema.Schema{
 			"activate": {
@@ -94,6 +112,7 @@ func resourceService(serviceDef ServiceDefinition) *schema.Resource {
 				Default:     true,
 				Optional:    true,
 			},
+			"activation_check": activationCheckSchema(),
 			// Active Version represents the currently activated version in Fastly. In
 			// Terraform, we abstract this number away from the users and manage
 			// creation and activating. It's used internally, but also exported for
@@ -118,9 +137,11 @@ func resourceService(serviceDef ServiceDefinition) *schema.Resource {
 			"comment": {
 				Type:        schema.TypeString,
 				Optional:    true,
-				Default:     "Managed by Terraform",
-				Description: "Description field for the service. Default `Managed by Terraform`",
+				Computed:    true,
+				Description: "Description field for the service. Defaults to the provider's `default_service_comment` when the service is created, or else `Managed by Terraform`",
 			},
+			"drift":        driftSchema(),
+			"drift_policy": driftPolicySchema(),
 			"force_destroy": {
 				Type:          schema.TypeBool,
 				Optional:      true,
@@ -142,6 +163,7 @@ func resourceService(serviceDef ServiceDefinition) *schema.Resource {
 				Required:    true,
 				Description: "The unique name for the Service to create",
 			},
+			"preflight_checks": preflightChecksSchema(),
 			"reuse": {
 				Type:          schema.TypeBool,
 				Optional:      true,
@@ -165,11 +187,16 @@ func resourceService(serviceDef ServiceDefinition) *schema.Resource {
 			"version_comment": {
 				Type:        schema.TypeString,
 				Optional:    true,
-				Description: "Description field for the version",
+				Computed:    true,
+				Description: "Description field for the version. Defaults to the provider's `default_version_comment` for each new version",
 			},
 		},
 	}
 
+	for k, v := range serviceSnapshotSchema() {
+		s.Schema[k] = v
+	}
+
 	// This loops over all the attribute handlers in the service definition and calls Register.
 	// Register adds schema attributes to the overall schema for the resource. This allows each AttributeHandler to
 	// define its own attributes while allowing the overall set to be composed.
@@ -177,6 +204,14 @@ func resourceService(serviceDef ServiceDefinition) *schema.Resource {
 		_ = a.Register(s)
 	}
 
+	// Blocks restored from a snapshot are read into state, but aren't managed
+	// by Terraform until their type is declared in configuration.
+	for _, a := range serviceDef.GetAttributeHandler() {
+		if key, ok := serviceAttributeKey(a); ok {
+			s.Schema[key].DiffSuppressFunc = suppressServiceSnapshotBlockDiff(key)
+		}
+	}
+
 	return s
 }
 
@@ -338,7 +373,11 @@ func resourceServiceUpdate(ctx context.Context, d *schema.ResourceData, meta any
 	// whether their current state and proposed changes mean a new version must be created.
 	// So where changes are required, a new version must be created first, and updates posted to that
 	// version. We only need one change to trigger this, so a break is OK.
-	var needsChange bool
+	// A new service restored from a snapshot always needs its first version
+	// to be populated.
+	restoreSnapshot := d.IsNewResource() && d.Get("source_snapshot").(string) != ""
+
+	needsChange := restoreSnapshot
 	for _, a := range serviceDef.GetAttributeHandler() {
 		if a.HasChange(d) {
 			needsChange = true
@@ -346,12 +385,19 @@ func resourceServiceUpdate(ctx context.Context, d *schema.ResourceData, meta any
 		}
 	}
 
+	// New versions are commented with the provider's default version comment,
+	// unless the resource sets its own.
+	versionComment, defaultVersionComment, err := serviceVersionComment(d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
 	// Update the cloned version's comment. No new version is required for this.
-	if d.HasChange("version_comment") && (!needsChange || d.IsNewResource()) {
+	if (d.HasChange("version_comment") || (defaultVersionComment && d.IsNewResource())) && (!needsChange || d.IsNewResource()) {
 		opts := gofastly.UpdateVersionInput{
 			ServiceID:      d.Id(),
 			ServiceVersion: d.Get("cloned_version").(int),
-			Comment:        gofastly.ToPointer(d.Get("version_comment").(string)),
+			Comment:        gofastly.ToPointer(versionComment),
 		}
 
 		log.Printf("[DEBUG] Update Version opts: %#v", opts)
@@ -414,20 +460,17 @@ func resourceServiceUpdate(ctx context.Context, d *schema.ResourceData, meta any
 				latestVersion = *newVersion.Number
 
 				// New versions are not immediately found in the API, or are not
-				// immediately mutable, so we need to sleep a few and let Fastly ready
-				// itself. Typically, 7 seconds is enough.
-				log.Print("[DEBUG] Sleeping 7 seconds to allow Fastly Version to be available")
-
-				// TODO: Replace sleep with either resource.Retry() or WaitForState().
-				// https://github.com/bflad/tfproviderlint/tree/main/passes/R018
-				time.Sleep(7 * time.Second)
+				// immediately mutable, so poll until Fastly has readied the version.
+				if err := waitForVersionReady(ctx, conn, d.Id(), latestVersion, meta.(*APIClient).cloneVersionWait); err != nil {
+					return diag.FromErr(err)
+				}
 
 				// Update the cloned version's comment.
-				if d.Get("version_comment").(string) != "" {
+				if versionComment != "" {
 					opts := gofastly.UpdateVersionInput{
 						ServiceID:      d.Id(),
 						ServiceVersion: latestVersion,
-						Comment:        gofastly.ToPointer(d.Get("version_comment").(string)),
+						Comment:        gofastly.ToPointer(versionComment),
 					}
 
 					log.Printf("[DEBUG] Update Version opts: %#v", opts)
@@ -439,22 +482,27 @@ func resourceServiceUpdate(ctx context.Context, d *schema.ResourceData, meta any
 			}
 		}
 
+		// Versions can't be deleted, so a draft version that fails to be updated
+		// or validated is annotated with the reason instead, rather than being
+		// mistaken for pending changes.
+		failed := func(err error) diag.Diagnostics {
+			annotateFailedVersion(ctx, conn, d.Id(), latestVersion, versionComment, err)
+			return diag.FromErr(err)
+		}
+
 		// This delegates the bulk of processing to attribute handlers which manage state
 		// for their own attributes.
-		for _, a := range serviceDef.GetAttributeHandler() {
-			if a.MustProcess(d, initialVersion) {
-				// Check if the Update has been cancelled and return early if so
-				if err := ctx.Err(); err != nil {
-					if errors.Is(err, context.Canceled) {
-						return nil
-					}
-
-					return diag.FromErr(err)
-				}
+		if err := processServiceAttributes(ctx, d, serviceDef.GetAttributeHandler(), initialVersion, latestVersion, meta.(*APIClient).maxConcurrentRequests, conn); err != nil {
+			// Check if the Update has been cancelled and return early if so
+			if errors.Is(err, context.Canceled) && ctx.Err() != nil {
+				return nil
+			}
+			return failed(err)
+		}
 
-				if err := a.Process(ctx, d, latestVersion, conn); err != nil {
-					return diag.FromErr(err)
-				}
+		if restoreSnapshot {
+			if err := restoreServiceSnapshot(ctx, d, serviceDef, latestVersion, meta.(*APIClient).maxConcurrentRequests, conn); err != nil {
+				return failed(err)
 			}
 		}
 
@@ -481,7 +529,7 @@ func resourceServiceUpdate(ctx context.Context, d *schema.ResourceData, meta any
 			}
 
 			if !valid {
-				return diag.Errorf("invalid configuration for Fastly Service (%s): %s", d.Id(), msg)
+				return failed(fmt.Errorf("invalid configuration for Fastly Service (%s): %s", d.Id(), msg))
 			}
 		}
 
@@ -513,6 +561,8 @@ func resourceServiceUpdate(ctx context.Context, d *schema.ResourceData, meta any
 	versionNotYetActivated := d.Get("cloned_version") != d.Get("active_version")
 	latestVersion := d.Get("cloned_version").(int)
 	if shouldActivate && versionNotYetActivated {
+		previousVersion := d.Get("active_version").(int)
+
 		log.Printf("[DEBUG] Activating Fastly Service (%s), Version (%v)", d.Id(), latestVersion)
 		_, err := conn.ActivateVersion(gofastly.NewContextForResourceID(ctx, d.Id()), &gofastly.ActivateVersionInput{
 			ServiceID:      d.Id(),
@@ -528,6 +578,10 @@ func resourceServiceUpdate(ctx context.Context, d *schema.ResourceData, meta any
 		if err != nil {
 			return diag.FromErr(err)
 		}
+
+		if diags := checkServiceActivation(ctx, d, conn, newActivationCheckHTTPClient(nil), latestVersion, previousVersion); diags.HasError() {
+			return diags
+		}
 	} else {
 		log.Printf("[INFO] Skipping activation of Fastly Service (%s), Version (%v)", d.Id(), latestVersion)
 		log.Print("[INFO] The Terraform definition is explicitly specified to not activate the changes on Fastly")
@@ -538,6 +592,49 @@ func resourceServiceUpdate(ctx context.Context, d *schema.ResourceData, meta any
 	return resourceServiceRead(ctx, d, meta, serviceDef)
 }
 
+// waitForVersionReady polls the given service version until it is found and
+// unlocked, backing off exponentially between attempts. It returns without
+// sleeping if the version is immediately available.
+func waitForVersionReady(ctx context.Context, conn *gofastly.Client, serviceID string, serviceVersion int, cfg CloneVersionWaitConfig) error {
+	cfg = cfg.withDefaults()
+
+	deadline := time.Now().Add(cfg.MaxWait)
+	backoff := cfg.InitialBackoff
+
+	for attempt := 1; ; attempt++ {
+		version, err := conn.GetVersion(gofastly.NewContextForResourceID(ctx, serviceID), &gofastly.GetVersionInput{
+			ServiceID:      serviceID,
+			ServiceVersion: serviceVersion,
+		})
+		switch {
+		case err == nil && (version.Locked == nil || !*version.Locked):
+			log.Printf("[DEBUG] Fastly Service (%s), Version (%d) is available after %d attempt(s)", serviceID, serviceVersion, attempt)
+			return nil
+		case err == nil:
+			log.Printf("[DEBUG] Fastly Service (%s), Version (%d) is locked, waiting %s", serviceID, serviceVersion, backoff)
+		default:
+			var httpErr *gofastly.HTTPError
+			if !errors.As(err, &httpErr) || !httpErr.IsNotFound() {
+				return err
+			}
+			log.Printf("[DEBUG] Fastly Service (%s), Version (%d) not found yet, waiting %s", serviceID, serviceVersion, backoff)
+		}
+
+		if time.Now().Add(backoff).After(deadline) {
+			return fmt.Errorf("timed out after %s waiting for Fastly Service (%s), Version (%d) to become available", cfg.MaxWait, serviceID, serviceVersion)
+		}
+
+		if err := sleepContext(ctx, backoff); err != nil {
+			return err
+		}
+
+		backoff *= 2
+		if backoff > cfg.MaxBackoff {
+			backoff = cfg.MaxBackoff
+		}
+	}
+}
+
 // resourceServiceRead provides service resource Read functionality.
 func resourceServiceRead(ctx context.Context, d *schema.ResourceData, meta any, serviceDef ServiceDefinition) diag.Diagnostics {
 	log.Printf("[DEBUG] Refreshing Service Configuration for (%s)", d.Id())
@@ -620,8 +717,18 @@ func resourceServiceRead(ctx context.Context, d *schema.ResourceData, meta any,
 	// NOTE: We only force a refresh if `activate = true` in config.
 	// This is because if the user has set it to false, then the expectation is
 	// for the version to drift and so there will be no active version to use.
+	var drift string
 	if s.ActiveVersion.Number != nil {
 		if activeVersionFromPriorState != *s.ActiveVersion.Number && activate {
+			// The version was activated outside of Terraform (e.g. a hotfix made
+			// in the Fastly UI), which is recorded so that the drift policy is
+			// applied by the plan. There is no prior active version when
+			// importing.
+			if activeVersionFromPriorState != 0 {
+				var driftDiags diag.Diagnostics
+				drift, driftDiags = checkServiceDrift(ctx, d, conn, s.ActiveVersion, activeVersionFromPriorState)
+				diags = append(diags, driftDiags...)
+			}
 			err = d.Set("force_refresh", true)
 			if err != nil {
 				return diag.FromErr(err)
@@ -632,6 +739,9 @@ func resourceServiceRead(ctx context.Context, d *schema.ResourceData, meta any,
 			return diag.FromErr(err)
 		}
 	}
+	if err := d.Set("drift", drift); err != nil {
+		return diag.FromErr(err)
+	}
 
 	// NOTE: service "name" and "comment" are versionless (mutable).
 	// Therefore, we only allow them to be updated if "activate = true".
@@ -733,6 +843,57 @@ func resourceServiceRead(ctx context.Context, d *schema.ResourceData, meta any,
 	return diags
 }
 
+// readServiceConfiguration reads the configuration of a service version into
+// the data of the matching service resource, the same way as when the service
+// is imported, so that every attribute handler reads its blocks from the API.
+// The active version is read if version is 0.
+func readServiceConfiguration(ctx context.Context, meta any, serviceID string, version int) (*schema.ResourceData, ServiceDefinition, diag.Diagnostics) {
+	conn := meta.(*APIClient).conn
+
+	s, err := conn.GetServiceDetails(gofastly.NewContextForResourceID(ctx, serviceID), &gofastly.GetServiceInput{
+		ServiceID: serviceID,
+	})
+	if err != nil {
+		return nil, nil, diag.Errorf("error looking up Fastly Service (%s): %s", serviceID, err)
+	}
+
+	var serviceDef ServiceDefinition
+	switch gofastly.ToValue(s.Type) {
+	case ServiceTypeVCL:
+		serviceDef = vclService
+	case ServiceTypeCompute:
+		serviceDef = computeService
+	default:
+		return nil, nil, diag.Errorf("unsupported type %q of Fastly Service (%s)", gofastly.ToValue(s.Type), serviceID)
+	}
+
+	d := resourceService(serviceDef).Data(nil)
+	d.SetId(serviceID)
+	if err := d.Set("imported", true); err != nil {
+		return nil, nil, diag.FromErr(err)
+	}
+	// NOTE: A specific version is read the same way as a draft version when
+	// `activate` is false.
+	if err := d.Set("activate", version == 0); err != nil {
+		return nil, nil, diag.FromErr(err)
+	}
+	if version != 0 {
+		if err := d.Set("cloned_version", version); err != nil {
+			return nil, nil, diag.FromErr(err)
+		}
+	}
+
+	diags := resourceServiceRead(ctx, d, meta, serviceDef)
+	if diags.HasError() {
+		return nil, nil, diags
+	}
+	if d.Id() == "" {
+		return nil, nil, append(diags, diag.Errorf("Fastly Service (%s) has been deleted", serviceID)...)
+	}
+
+	return d, serviceDef, diags
+}
+
 // resourceServiceDelete provides service resource Delete functionality.
 func resourceServiceDelete(ctx context.Context, d *schema.ResourceData, meta any, _ ServiceDefinition) diag.Diagnostics {
 	conn := meta.(*APIClient).conn
@@ -0,0 +1,122 @@
+package fastly
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	gofastly "github.com/fastly/go-fastly/v12/fastly"
+)
+
+// newTestVersionServer returns a fake Fastly API that responds to GetVersion
+// requests using the given handler, along with a counter of requests made.
+func newTestVersionServer(t *testing.T, handler func(attempt int32, w http.ResponseWriter)) (*gofastly.Client, *int32) {
+	t.Helper()
+
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/service/123/version/2" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		handler(atomic.AddInt32(&requests, 1), w)
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := gofastly.NewClientForEndpoint("someapikey", server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client, &requests
+}
+
+func writeTestVersion(w http.ResponseWriter, locked bool) {
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprintf(w, `{"service_id":"123","number":2,"locked":%t}`, locked)
+}
+
+func TestWaitForVersionReady_immediatelyAvailable(t *testing.T) {
+	client, requests := newTestVersionServer(t, func(_ int32, w http.ResponseWriter) {
+		writeTestVersion(w, false)
+	})
+
+	cfg := CloneVersionWaitConfig{
+		InitialBackoff: 5 * time.Second,
+		MaxBackoff:     5 * time.Second,
+		MaxWait:        time.Minute,
+	}
+
+	start := time.Now()
+	if err := waitForVersionReady(context.Background(), client, "123", 2, cfg); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if elapsed := time.Since(start); elapsed >= cfg.InitialBackoff {
+		t.Errorf("expected no sleep, took %s", elapsed)
+	}
+	if got := atomic.LoadInt32(requests); got != 1 {
+		t.Errorf("expected 1 request, got %d", got)
+	}
+}
+
+func TestWaitForVersionReady_eventuallyAvailable(t *testing.T) {
+	client, requests := newTestVersionServer(t, func(attempt int32, w http.ResponseWriter) {
+		switch attempt {
+		case 1:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"msg":"Record not found"}`)
+		case 2:
+			writeTestVersion(w, true)
+		default:
+			writeTestVersion(w, false)
+		}
+	})
+
+	cfg := CloneVersionWaitConfig{
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     2 * time.Millisecond,
+		MaxWait:        time.Second,
+	}
+
+	if err := waitForVersionReady(context.Background(), client, "123", 2, cfg); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got := atomic.LoadInt32(requests); got != 3 {
+		t.Errorf("expected 3 requests, got %d", got)
+	}
+}
+
+func TestWaitForVersionReady_timeout(t *testing.T) {
+	client, _ := newTestVersionServer(t, func(_ int32, w http.ResponseWriter) {
+		writeTestVersion(w, true)
+	})
+
+	cfg := CloneVersionWaitConfig{
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     5 * time.Millisecond,
+		MaxWait:        50 * time.Millisecond,
+	}
+
+	if err := waitForVersionReady(context.Background(), client, "123", 2, cfg); err == nil {
+		t.Fatal("expected a timeout error")
+	}
+}
+
+func TestWaitForVersionReady_error(t *testing.T) {
+	client, requests := newTestVersionServer(t, func(_ int32, w http.ResponseWriter) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"msg":"Provided credentials are missing or invalid"}`)
+	})
+
+	if err := waitForVersionReady(context.Background(), client, "123", 2, CloneVersionWaitConfig{}); err == nil {
+		t.Fatal("expected an error")
+	}
+	if got := atomic.LoadInt32(requests); got != 1 {
+		t.Errorf("expected 1 request, got %d", got)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"sync"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 
@@ -14,12 +15,16 @@ import (
 // DirectorServiceAttributeHandler provides a base implementation for ServiceAttributeDefinition.
 type DirectorServiceAttributeHandler struct {
 	*DefaultServiceAttributeHandler
+
+	// mu serializes reads of the resource data, as directors may be updated
+	// concurrently (see processServiceAttributes).
+	mu sync.Mutex
 }
 
 // NewServiceDirector constructs a service attribute.
 func NewServiceDirector(sa ServiceMetadata) ServiceAttributeDefinition {
 	return ToServiceAttributeDefinition(&DirectorServiceAttributeHandler{
-		&DefaultServiceAttributeHandler{
+		DefaultServiceAttributeHandler: &DefaultServiceAttributeHandler{
 			key:             "director",
 			serviceMetadata: sa,
 		},
@@ -203,7 +208,9 @@ func (h *DirectorServiceAttributeHandler) Update(ctx context.Context, d *schema.
 	}
 
 	if _, ok := modified["backends"]; ok {
+		h.mu.Lock()
 		odb, ndb := getDirectorBackendChange(d, resource)
+		h.mu.Unlock()
 
 		remove := odb.Difference(ndb).List()
 		for _, b := range remove {
@@ -17,16 +17,84 @@ import (
 //
 // NOTE: The fields correlate to the root TCL schema.
 type Config struct {
-	APIKey     string
-	BaseURL    string
-	ForceHTTP2 bool
-	NoAuth     bool
-	UserAgent  string
+	APIKey           string
+	BaseURL          string
+	CloneVersionWait CloneVersionWaitConfig
+	// Credentials are the API keys of additional Fastly accounts, which
+	// resources and data sources select by name with `account`.
+	Credentials []CredentialsConfig
+	// DefaultServiceComment and DefaultVersionComment are the comment
+	// templates of services and versions that don't set their own.
+	DefaultServiceComment string
+	DefaultVersionComment string
+	ForceHTTP2            bool
+	// MaxConcurrentRequests limits the API requests made concurrently when
+	// applying the nested blocks of a service.
+	MaxConcurrentRequests int
+	NoAuth                bool
+	Retry                 RetryConfig
+	// Transport replaces the default HTTP transport when set. The acceptance
+	// tests use it to record and replay API interactions.
+	Transport http.RoundTripper
+	UserAgent string
+}
+
+// CredentialsConfig is the API key of an additional Fastly account.
+//
+// NOTE: An empty BaseURL is replaced with the BaseURL of the Config.
+type CredentialsConfig struct {
+	Name    string
+	APIKey  string
+	BaseURL string
+}
+
+// CloneVersionWaitConfig controls how long the provider polls for a newly
+// cloned service version to become available before modifying it.
+//
+// NOTE: Zero values are replaced with the defaults below.
+type CloneVersionWaitConfig struct {
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	MaxWait        time.Duration
+}
+
+const (
+	// DefaultCloneVersionInitialBackoff is the delay before the first retry.
+	DefaultCloneVersionInitialBackoff = 500 * time.Millisecond
+	// DefaultCloneVersionMaxBackoff is the maximum delay between retries.
+	DefaultCloneVersionMaxBackoff = 5 * time.Second
+	// DefaultCloneVersionMaxWait is the total time to wait for a cloned version.
+	DefaultCloneVersionMaxWait = 60 * time.Second
+)
+
+// withDefaults returns a copy of the configuration with zero values replaced
+// by the defaults.
+func (c CloneVersionWaitConfig) withDefaults() CloneVersionWaitConfig {
+	if c.InitialBackoff <= 0 {
+		c.InitialBackoff = DefaultCloneVersionInitialBackoff
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = DefaultCloneVersionMaxBackoff
+	}
+	if c.MaxBackoff < c.InitialBackoff {
+		c.MaxBackoff = c.InitialBackoff
+	}
+	if c.MaxWait <= 0 {
+		c.MaxWait = DefaultCloneVersionMaxWait
+	}
+	return c
 }
 
 // APIClient is a HTTP API Client.
 type APIClient struct {
 	conn *gofastly.Client
+	// accounts are the clients of the additional accounts configured with
+	// `credentials`, by name. They share the settings of the default client.
+	accounts              map[string]*APIClient
+	cloneVersionWait      CloneVersionWaitConfig
+	defaultServiceComment string
+	defaultVersionComment string
+	maxConcurrentRequests int
 }
 
 // Client returns a FastlyClient.
@@ -39,11 +107,76 @@ func (c *Config) Client() (*APIClient, diag.Diagnostics) {
 
 	gofastly.UserAgent = c.UserAgent
 
-	fastlyClient, err := gofastly.NewClientForEndpoint(c.APIKey, c.BaseURL)
+	// All clients share the transport, so that accounts reuse connections to
+	// the same API endpoint. Each client has its own retries, as the API
+	// rate limits each API key separately.
+	transport := c.transport()
+
+	fastlyClient, err := newFastlyClient(c.APIKey, c.BaseURL, newRetryTransport(transport, c.Retry))
 	if err != nil {
 		return nil, diag.FromErr(err)
 	}
 
+	client.conn = fastlyClient
+	client.cloneVersionWait = c.CloneVersionWait.withDefaults()
+	client.defaultServiceComment = c.DefaultServiceComment
+	client.defaultVersionComment = c.DefaultVersionComment
+	client.maxConcurrentRequests = c.MaxConcurrentRequests
+	if client.maxConcurrentRequests < 1 {
+		client.maxConcurrentRequests = DefaultMaxConcurrentRequests
+	}
+
+	client.accounts = make(map[string]*APIClient, len(c.Credentials))
+	for _, creds := range c.Credentials {
+		if _, ok := client.accounts[creds.Name]; ok {
+			return nil, diag.Errorf("credentials %q are configured more than once", creds.Name)
+		}
+		if creds.APIKey == "" {
+			return nil, diag.Errorf("no API key for Fastly in credentials %q", creds.Name)
+		}
+		baseURL := creds.BaseURL
+		if baseURL == "" {
+			baseURL = c.BaseURL
+		}
+		conn, err := newFastlyClient(creds.APIKey, baseURL, newRetryTransport(transport, c.Retry))
+		if err != nil {
+			return nil, diag.Errorf("error creating client for credentials %q: %s", creds.Name, err)
+		}
+		account := client
+		account.conn = conn
+		account.accounts = nil
+		client.accounts[creds.Name] = &account
+	}
+
+	return &client, nil
+}
+
+// forAccount returns the client of the named account, or the default client
+// if the name is empty.
+func (c *APIClient) forAccount(name string) (*APIClient, error) {
+	if name == "" {
+		return c, nil
+	}
+	if account, ok := c.accounts[name]; ok {
+		return account, nil
+	}
+	return nil, fmt.Errorf("no credentials named %q are configured in the provider", name)
+}
+
+// newFastlyClient returns a go-fastly client for the given API key and
+// endpoint that sends its requests through transport.
+func newFastlyClient(apiKey, baseURL string, transport http.RoundTripper) (*gofastly.Client, error) {
+	fastlyClient, err := gofastly.NewClientForEndpoint(apiKey, baseURL)
+	if err != nil {
+		return nil, err
+	}
+	fastlyClient.HTTPClient.Transport = transport
+	return fastlyClient, nil
+}
+
+// transport returns the HTTP transport shared by the API clients, which wrap
+// it with their own retries.
+func (c *Config) transport() http.RoundTripper {
 	// NOTE: We're fixing two issues here.
 	// 1 (critical). go-fastly uses cleanhttp module that would disable keepalive connection:
 	// https://github.com/hashicorp/go-cleanhttp/blob/v0.5.2/cleanhttp.go#L14-L15
@@ -74,12 +207,16 @@ func (c *Config) Client() (*APIClient, diag.Diagnostics) {
 	// so leave it to default values for now.
 	http2DefaultTransport := &http2.Transport{}
 
-	if c.ForceHTTP2 {
-		fastlyClient.HTTPClient.Transport = logging.NewSubsystemLoggingHTTPTransport("Fastly", http2DefaultTransport)
-	} else {
-		fastlyClient.HTTPClient.Transport = logging.NewSubsystemLoggingHTTPTransport("Fastly", httpDefaultTransport)
+	var transport http.RoundTripper
+	switch {
+	case c.Transport != nil:
+		transport = logging.NewSubsystemLoggingHTTPTransport("Fastly", c.Transport)
+	case c.ForceHTTP2:
+		transport = logging.NewSubsystemLoggingHTTPTransport("Fastly", http2DefaultTransport)
+	default:
+		transport = logging.NewSubsystemLoggingHTTPTransport("Fastly", httpDefaultTransport)
 	}
 
-	client.conn = fastlyClient
-	return &client, nil
+	// NOTE: Retries wrap the logging transport so that every attempt is logged.
+	return transport
 }
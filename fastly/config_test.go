@@ -31,11 +31,12 @@ func TestForceHttp2(t *testing.T) {
 	}
 	client2, _ := c2.Client()
 
-	tv1 := reflect.ValueOf(client1.conn.HTTPClient.Transport).Elem()
+	// NOTE: The logging transport is wrapped by the retry transport.
+	tv1 := reflect.ValueOf(client1.conn.HTTPClient.Transport.(*retryTransport).transport).Elem()
 	// http.Transport
 	ts1 := reflect.Indirect(tv1.FieldByName("transport").Elem()).Type().String()
 
-	tv2 := reflect.ValueOf(client2.conn.HTTPClient.Transport).Elem()
+	tv2 := reflect.ValueOf(client2.conn.HTTPClient.Transport.(*retryTransport).transport).Elem()
 	// http2.Transport
 	ts2 := reflect.Indirect(tv2.FieldByName("transport").Elem()).Type().String()
 
@@ -43,3 +44,20 @@ func TestForceHttp2(t *testing.T) {
 		t.Errorf("failed to create client with force_http2: %#v, %#v", ts1, ts2)
 	}
 }
+
+func TestMaxConcurrentRequests(t *testing.T) {
+	c := Config{
+		APIKey:  "someapikey",
+		BaseURL: "http://localhost",
+	}
+	client, _ := c.Client()
+	if client.maxConcurrentRequests != DefaultMaxConcurrentRequests {
+		t.Errorf("expected default of %d concurrent requests, got %d", DefaultMaxConcurrentRequests, client.maxConcurrentRequests)
+	}
+
+	c.MaxConcurrentRequests = 8
+	client, _ = c.Client()
+	if client.maxConcurrentRequests != 8 {
+		t.Errorf("expected 8 concurrent requests, got %d", client.maxConcurrentRequests)
+	}
+}
@@ -117,20 +117,23 @@ func getTLSActivationFilters(d *schema.ResourceData) []TLSActivationPredicate {
 }
 
 func listTLSActivations(ctx context.Context, conn *fastly.Client, filters ...TLSActivationPredicate) ([]*fastly.TLSActivation, error) {
+	return listTLSActivationsWithInput(ctx, conn, fastly.ListTLSActivationsInput{PageSize: 10}, filters...)
+}
+
+// listTLSActivationsWithInput lists the activations matching the filters of
+// input and the predicates, page by page.
+func listTLSActivationsWithInput(ctx context.Context, conn *fastly.Client, input fastly.ListTLSActivationsInput, filters ...TLSActivationPredicate) ([]*fastly.TLSActivation, error) {
 	var activations []*fastly.TLSActivation
-	pageNumber := 1
+	input.PageNumber = 1
 	for {
-		list, err := conn.ListTLSActivations(ctx, &fastly.ListTLSActivationsInput{
-			PageNumber: pageNumber,
-			PageSize:   10,
-		})
+		list, err := conn.ListTLSActivations(ctx, &input)
 		if err != nil {
 			return nil, err
 		}
 		if len(list) == 0 {
 			break
 		}
-		pageNumber++
+		input.PageNumber++
 
 		for _, activation := range list {
 			if filterTLSActivations(activation, filters) {
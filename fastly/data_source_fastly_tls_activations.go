@@ -0,0 +1,211 @@
+package fastly
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	"github.com/fastly/go-fastly/v12/fastly"
+
+	"github.com/fastly/terraform-provider-fastly/fastly/hashcode"
+)
+
+func dataSourceFastlyTLSActivations() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceFastlyTLSActivationsRead,
+		Schema: map[string]*schema.Schema{
+			"activations": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The TLS Activations matching the filters, sorted by domain.",
+				Elem:        tlsActivationElem(),
+			},
+			"certificate_expires_within_days": {
+				Type:             schema.TypeInt,
+				Optional:         true,
+				Description:      "Only return activations whose TLS Certificate expires within this many days. Expired certificates match.",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(1)),
+			},
+			"certificate_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return activations of this TLS Certificate.",
+			},
+			"configuration_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return activations using this TLS Configuration.",
+			},
+			"domain_glob": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Description:      "Only return activations of domains matching this case-insensitive glob (e.g. `*.example.com`). `*` matches any sequence of characters, including dots.",
+				ValidateDiagFunc: validateTLSDomainGlob(),
+			},
+		},
+	}
+}
+
+func dataSourceFastlyTLSActivationsRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	conn := meta.(*APIClient).conn
+
+	log.Printf("[DEBUG] Reading TLS Activations")
+
+	input := fastly.ListTLSActivationsInput{
+		FilterTLSCertificateID:   d.Get("certificate_id").(string),
+		FilterTLSConfigurationID: d.Get("configuration_id").(string),
+		Include:                  "tls_certificate",
+		PageSize:                 10,
+	}
+
+	var filters []TLSActivationPredicate
+	if v, ok := d.GetOk("domain_glob"); ok {
+		filters = append(filters, tlsActivationDomainMatches(v.(string)))
+	}
+	if v, ok := d.GetOk("certificate_expires_within_days"); ok {
+		filters = append(filters, tlsActivationCertificateExpiresBefore(time.Now().AddDate(0, 0, v.(int))))
+	}
+
+	activations, err := listTLSActivationsWithInput(ctx, conn, input, filters...)
+	if err != nil {
+		return diag.Errorf("error fetching TLS Activations: %s", err)
+	}
+
+	result := flattenTLSActivations(activations)
+
+	hashBase, _ := json.Marshal(result)
+	d.SetId(strconv.Itoa(hashcode.String(string(hashBase))))
+
+	if err := d.Set("activations", result); err != nil {
+		return diag.Errorf("error setting activations: %s", err)
+	}
+
+	return nil
+}
+
+// tlsActivationElem is the schema of the activations listed by the
+// fastly_tls_activations and fastly_tls_domains data sources.
+func tlsActivationElem() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"certificate_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "ID of the TLS Certificate used.",
+			},
+			"certificate_not_after": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Timestamp (GMT) after which the TLS Certificate used expires.",
+			},
+			"configuration_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "ID of the TLS Configuration used.",
+			},
+			"created_at": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Timestamp (GMT) when TLS was enabled.",
+			},
+			"domain": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Domain that TLS was enabled on.",
+			},
+			"id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Fastly Activation ID.",
+			},
+			"mutual_authentication_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "ID of the Mutual TLS Authentication used, if any.",
+			},
+		},
+	}
+}
+
+// flattenTLSActivations models activations into a format suitable for saving
+// to Terraform state, sorted by domain. The expiry of an activation's
+// certificate is only known if the certificate was included.
+func flattenTLSActivations(activations []*fastly.TLSActivation) []map[string]any {
+	activations = append([]*fastly.TLSActivation{}, activations...)
+	sort.SliceStable(activations, func(i, j int) bool {
+		if a, b := tlsActivationDomainID(activations[i]), tlsActivationDomainID(activations[j]); a != b {
+			return a < b
+		}
+		return activations[i].ID < activations[j].ID
+	})
+
+	result := make([]map[string]any, len(activations))
+	for i, activation := range activations {
+		m := map[string]any{
+			"id":     activation.ID,
+			"domain": tlsActivationDomainID(activation),
+		}
+		if activation.CreatedAt != nil {
+			m["created_at"] = activation.CreatedAt.Format(time.RFC3339)
+		}
+		if activation.Configuration != nil {
+			m["configuration_id"] = activation.Configuration.ID
+		}
+		if activation.MutualAuthentication != nil {
+			m["mutual_authentication_id"] = activation.MutualAuthentication.ID
+		}
+		if certificate := activation.Certificate; certificate != nil {
+			m["certificate_id"] = certificate.ID
+			if certificate.NotAfter != nil {
+				m["certificate_not_after"] = certificate.NotAfter.Format(time.RFC3339)
+			}
+		}
+		result[i] = m
+	}
+
+	return result
+}
+
+func tlsActivationDomainID(activation *fastly.TLSActivation) string {
+	if activation.Domain == nil {
+		return ""
+	}
+	return activation.Domain.ID
+}
+
+// tlsActivationDomainMatches returns a predicate matching the activations of
+// the domains that match pattern.
+func tlsActivationDomainMatches(pattern string) TLSActivationPredicate {
+	return func(activation *fastly.TLSActivation) bool {
+		return matchTLSDomainGlob(pattern, tlsActivationDomainID(activation))
+	}
+}
+
+// tlsActivationCertificateExpiresBefore returns a predicate matching the
+// activations whose certificate expires at or before deadline. The
+// activations must include their certificate.
+func tlsActivationCertificateExpiresBefore(deadline time.Time) TLSActivationPredicate {
+	return func(activation *fastly.TLSActivation) bool {
+		return activation.Certificate != nil && tlsCertificateExpiresBefore(activation.Certificate.NotAfter, deadline)
+	}
+}
+
+func tlsCertificateExpiresBefore(notAfter *time.Time, deadline time.Time) bool {
+	return notAfter != nil && !notAfter.After(deadline)
+}
+
+// matchTLSDomainGlob reports whether domain matches the case-insensitive
+// glob pattern.
+func matchTLSDomainGlob(pattern, domain string) bool {
+	matched, err := path.Match(strings.ToLower(pattern), strings.ToLower(domain))
+	return err == nil && matched
+}
@@ -0,0 +1,148 @@
+package fastly
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/stretchr/testify/require"
+
+	gofastly "github.com/fastly/go-fastly/v12/fastly"
+)
+
+func TestTLSActivationPredicates(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	soon := now.AddDate(0, 0, 10)
+	later := now.AddDate(0, 0, 90)
+
+	activation := func(id, domain string, notAfter time.Time) *gofastly.TLSActivation {
+		return &gofastly.TLSActivation{
+			ID:            id,
+			Certificate:   &gofastly.CustomTLSCertificate{ID: "cert-" + id, NotAfter: &notAfter},
+			Configuration: &gofastly.TLSConfiguration{ID: "config"},
+			CreatedAt:     &now,
+			Domain:        &gofastly.TLSDomain{ID: domain},
+		}
+	}
+	activations := []*gofastly.TLSActivation{
+		activation("3", "www.example.org", soon),
+		activation("2", "API.example.com", later),
+		activation("1", "www.example.com", now.AddDate(0, 0, -1)),
+	}
+
+	var got []string
+	for _, a := range activations {
+		if filterTLSActivations(a, []TLSActivationPredicate{
+			tlsActivationDomainMatches("*.EXAMPLE.com"),
+			tlsActivationCertificateExpiresBefore(now.AddDate(0, 0, 30)),
+		}) {
+			got = append(got, a.ID)
+		}
+	}
+	if want := []string{"1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected activations %v, got %v", want, got)
+	}
+
+	flattened := flattenTLSActivations(activations)
+	var domains []string
+	for _, m := range flattened {
+		domains = append(domains, m["domain"].(string))
+	}
+	if want := []string{"API.example.com", "www.example.com", "www.example.org"}; !reflect.DeepEqual(domains, want) {
+		t.Errorf("expected activations sorted by domain %v, got %v", want, domains)
+	}
+	if want := soon.Format(time.RFC3339); flattened[2]["certificate_not_after"] != want {
+		t.Errorf("expected certificate_not_after %s, got %v", want, flattened[2]["certificate_not_after"])
+	}
+
+	if diags := validateTLSDomainGlob()("[example.com", nil); !diags.HasError() {
+		t.Errorf("expected an invalid glob to fail validation")
+	}
+}
+
+func TestAccDataSourceFastlyTLSActivations_basic(t *testing.T) {
+	domain := fmt.Sprintf("%s.com", acctest.RandomWithPrefix(testResourcePrefix))
+	key, cert, err := generateKeyAndCert(domain)
+	require.NoError(t, err)
+	key = strings.ReplaceAll(key, "\n", `\n`)
+	cert = strings.ReplaceAll(cert, "\n", `\n`)
+
+	datasourceName := "data.fastly_tls_activations.subject"
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		ProviderFactories: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceFastlyTLSActivationsConfig(key, cert, domain),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(datasourceName, "activations.#", "1"),
+					resource.TestCheckResourceAttrPair(datasourceName, "activations.0.id", "fastly_tls_activation.test", "id"),
+					resource.TestCheckResourceAttr(datasourceName, "activations.0.domain", domain),
+					resource.TestCheckResourceAttrPair(datasourceName, "activations.0.certificate_id", "fastly_tls_certificate.test", "id"),
+					resource.TestCheckResourceAttrSet(datasourceName, "activations.0.certificate_not_after"),
+					resource.TestCheckResourceAttrSet(datasourceName, "activations.0.configuration_id"),
+				),
+			},
+		},
+	})
+}
+
+func testAccDataSourceFastlyTLSActivationsConfig(key, cert, domain string) string {
+	name := acctest.RandomWithPrefix(testResourcePrefix)
+
+	return fmt.Sprintf(
+		`
+resource "fastly_service_vcl" "test" {
+  name = "%s"
+
+  domain {
+    name = "%s"
+  }
+
+  backend {
+    address = "127.0.0.1"
+    name    = "localhost"
+  }
+
+  force_destroy = true
+}
+
+resource "fastly_tls_private_key" "test" {
+  key_pem = "%s"
+  name = "%s"
+}
+
+resource "fastly_tls_certificate" "test" {
+  certificate_body = "%s"
+  name = "%s"
+  depends_on = [fastly_tls_private_key.test]
+}
+
+resource "fastly_tls_activation" "test" {
+  certificate_id = fastly_tls_certificate.test.id
+  domain = "%s"
+  depends_on = [fastly_service_vcl.test]
+}
+
+data "fastly_tls_activations" "subject" {
+  certificate_id                  = fastly_tls_activation.test.certificate_id
+  domain_glob                     = "*.com"
+  certificate_expires_within_days = 100
+}
+
+`,
+		name,
+		domain,
+		key,
+		name,
+		cert,
+		name,
+		domain,
+	)
+}
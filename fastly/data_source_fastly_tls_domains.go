@@ -0,0 +1,357 @@
+package fastly
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	"github.com/fastly/go-fastly/v12/fastly"
+
+	"github.com/fastly/terraform-provider-fastly/fastly/hashcode"
+)
+
+func dataSourceFastlyTLSDomains() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceFastlyTLSDomainsListRead,
+		Schema: map[string]*schema.Schema{
+			"certificate_expires_within_days": {
+				Type:             schema.TypeInt,
+				Optional:         true,
+				Description:      "Only return domains with a TLS Certificate that expires within this many days. Expired certificates match.",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(1)),
+			},
+			"configuration_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return domains with an activation using this TLS Configuration.",
+			},
+			"domain_glob": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Description:      "Only return domains matching this case-insensitive glob (e.g. `*.example.com`). `*` matches any sequence of characters, including dots.",
+				ValidateDiagFunc: validateTLSDomainGlob(),
+			},
+			"domains": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The TLS Domains matching the filters, sorted by domain name.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"activations": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Description: "The TLS Activations of the domain.",
+							Elem:        tlsActivationElem(),
+						},
+						"certificates": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Description: "The TLS Certificates whose SANs include the domain, sorted by ID.",
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"id": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "Alphanumeric string identifying the TLS Certificate.",
+									},
+									"issued_to": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "The hostname for which a certificate was issued.",
+									},
+									"issuer": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "The certificate authority that issued the certificate.",
+									},
+									"name": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "Human-readable name used to identify the TLS Certificate.",
+									},
+									"not_after": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "Timestamp (GMT) after which the certificate expires.",
+									},
+									"not_before": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "Timestamp (GMT) before which the certificate isn't valid.",
+									},
+									"replace": {
+										Type:        schema.TypeBool,
+										Computed:    true,
+										Description: "A recommendation from Fastly indicating the key associated with this certificate is in need of rotation.",
+									},
+									"serial_number": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "A value assigned by the issuer that is unique to a certificate.",
+									},
+								},
+							},
+						},
+						"configuration_ids": {
+							Type:        schema.TypeSet,
+							Computed:    true,
+							Description: "IDs of the TLS Configurations used by the activations of the domain.",
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+						"domain": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The domain name.",
+						},
+						"subscriptions": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Description: "The TLS Subscriptions that include the domain, sorted by ID.",
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"certificate_authority": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "The entity that issues and certifies the TLS certificates for the subscription.",
+									},
+									"configuration_id": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "ID of the TLS Configuration of the subscription.",
+									},
+									"created_at": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "Timestamp (GMT) when the subscription was created.",
+									},
+									"id": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "ID of the TLS Subscription.",
+									},
+									"state": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "The current state of the subscription. The list of possible states are: `pending`, `processing`, `issued`, and `renewing`.",
+									},
+									"updated_at": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "Timestamp (GMT) when the subscription was last updated.",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceFastlyTLSDomainsListRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	conn := meta.(*APIClient).conn
+
+	log.Printf("[DEBUG] Reading TLS Domains")
+
+	// The relationships of the listed domains only hold IDs, so the
+	// activations, certificates and subscriptions are listed separately and
+	// joined to the domains.
+	domains, err := listTLSDomains(ctx, conn)
+	if err != nil {
+		return diag.Errorf("error fetching TLS Domains: %s", err)
+	}
+	activations, err := listTLSActivations(ctx, conn)
+	if err != nil {
+		return diag.Errorf("error fetching TLS Activations: %s", err)
+	}
+	certificates, err := listTLSCertificates(ctx, conn)
+	if err != nil {
+		return diag.Errorf("error fetching TLS Certificates: %s", err)
+	}
+	subscriptions, err := listTLSSubscriptions(ctx, conn)
+	if err != nil {
+		return diag.Errorf("error fetching TLS Subscriptions: %s", err)
+	}
+	joinTLSDomains(domains, activations, certificates, subscriptions)
+
+	var filters []TLSDomainPredicate
+	if v, ok := d.GetOk("domain_glob"); ok {
+		pattern := v.(string)
+		filters = append(filters, func(domain *fastly.TLSDomain) bool {
+			return matchTLSDomainGlob(pattern, domain.ID)
+		})
+	}
+	if v, ok := d.GetOk("configuration_id"); ok {
+		filters = append(filters, tlsDomainUsesConfiguration(v.(string)))
+	}
+	if v, ok := d.GetOk("certificate_expires_within_days"); ok {
+		filters = append(filters, tlsDomainCertificateExpiresBefore(time.Now().AddDate(0, 0, v.(int))))
+	}
+
+	var filtered []*fastly.TLSDomain
+	for _, domain := range domains {
+		if filterTLSDomain(domain, filters) {
+			filtered = append(filtered, domain)
+		}
+	}
+
+	result := flattenTLSDomains(filtered)
+
+	hashBase, _ := json.Marshal(result)
+	d.SetId(strconv.Itoa(hashcode.String(string(hashBase))))
+
+	if err := d.Set("domains", result); err != nil {
+		return diag.Errorf("error setting domains: %s", err)
+	}
+
+	return nil
+}
+
+// joinTLSDomains replaces the relationships of domains with the matching
+// activations, certificates and subscriptions. Activations are matched by
+// their domain, and their certificate is replaced with the matching
+// certificate. Relationships without a match are left as they are.
+func joinTLSDomains(domains []*fastly.TLSDomain, activations []*fastly.TLSActivation, certificates []*fastly.CustomTLSCertificate, subscriptions []*fastly.TLSSubscription) {
+	certificatesByID := make(map[string]*fastly.CustomTLSCertificate, len(certificates))
+	for _, certificate := range certificates {
+		certificatesByID[certificate.ID] = certificate
+	}
+	subscriptionsByID := make(map[string]*fastly.TLSSubscription, len(subscriptions))
+	for _, subscription := range subscriptions {
+		subscriptionsByID[subscription.ID] = subscription
+	}
+	activationsByDomain := make(map[string][]*fastly.TLSActivation)
+	for _, activation := range activations {
+		if activation.Certificate != nil {
+			if certificate, ok := certificatesByID[activation.Certificate.ID]; ok {
+				activation.Certificate = certificate
+			}
+		}
+		domain := tlsActivationDomainID(activation)
+		activationsByDomain[domain] = append(activationsByDomain[domain], activation)
+	}
+
+	for _, domain := range domains {
+		domain.Activations = activationsByDomain[domain.ID]
+		for i, certificate := range domain.Certificates {
+			if c, ok := certificatesByID[certificate.ID]; ok {
+				domain.Certificates[i] = c
+			}
+		}
+		for i, subscription := range domain.Subscriptions {
+			if s, ok := subscriptionsByID[subscription.ID]; ok {
+				domain.Subscriptions[i] = s
+			}
+		}
+	}
+}
+
+// tlsDomainUsesConfiguration returns a predicate matching the domains with
+// an activation using the configuration.
+func tlsDomainUsesConfiguration(configurationID string) TLSDomainPredicate {
+	return func(domain *fastly.TLSDomain) bool {
+		for _, activation := range domain.Activations {
+			if activation.Configuration != nil && activation.Configuration.ID == configurationID {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// tlsDomainCertificateExpiresBefore returns a predicate matching the domains
+// with a certificate that expires at or before deadline. The domains must be
+// joined to their certificates.
+func tlsDomainCertificateExpiresBefore(deadline time.Time) TLSDomainPredicate {
+	return func(domain *fastly.TLSDomain) bool {
+		for _, certificate := range domain.Certificates {
+			if tlsCertificateExpiresBefore(certificate.NotAfter, deadline) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// flattenTLSDomains models joined domains into a format suitable for saving
+// to Terraform state, sorted by domain name.
+func flattenTLSDomains(domains []*fastly.TLSDomain) []map[string]any {
+	domains = append([]*fastly.TLSDomain{}, domains...)
+	sort.Slice(domains, func(i, j int) bool {
+		return domains[i].ID < domains[j].ID
+	})
+
+	result := make([]map[string]any, len(domains))
+	for i, domain := range domains {
+		configurationIDs := []string{}
+		for _, activation := range domain.Activations {
+			if activation.Configuration != nil {
+				configurationIDs = append(configurationIDs, activation.Configuration.ID)
+			}
+		}
+
+		certificates := append([]*fastly.CustomTLSCertificate{}, domain.Certificates...)
+		sort.Slice(certificates, func(i, j int) bool {
+			return certificates[i].ID < certificates[j].ID
+		})
+		flatCertificates := make([]map[string]any, len(certificates))
+		for j, certificate := range certificates {
+			m := map[string]any{
+				"id":            certificate.ID,
+				"issued_to":     certificate.IssuedTo,
+				"issuer":        certificate.Issuer,
+				"name":          certificate.Name,
+				"replace":       certificate.Replace,
+				"serial_number": certificate.SerialNumber,
+			}
+			if certificate.NotAfter != nil {
+				m["not_after"] = certificate.NotAfter.Format(time.RFC3339)
+			}
+			if certificate.NotBefore != nil {
+				m["not_before"] = certificate.NotBefore.Format(time.RFC3339)
+			}
+			flatCertificates[j] = m
+		}
+
+		subscriptions := append([]*fastly.TLSSubscription{}, domain.Subscriptions...)
+		sort.Slice(subscriptions, func(i, j int) bool {
+			return subscriptions[i].ID < subscriptions[j].ID
+		})
+		flatSubscriptions := make([]map[string]any, len(subscriptions))
+		for j, subscription := range subscriptions {
+			m := map[string]any{
+				"id":                    subscription.ID,
+				"certificate_authority": subscription.CertificateAuthority,
+				"state":                 subscription.State,
+			}
+			if subscription.Configuration != nil {
+				m["configuration_id"] = subscription.Configuration.ID
+			}
+			if subscription.CreatedAt != nil {
+				m["created_at"] = subscription.CreatedAt.Format(time.RFC3339)
+			}
+			if subscription.UpdatedAt != nil {
+				m["updated_at"] = subscription.UpdatedAt.Format(time.RFC3339)
+			}
+			flatSubscriptions[j] = m
+		}
+
+		result[i] = map[string]any{
+			"activations":       flattenTLSActivations(domain.Activations),
+			"certificates":      flatCertificates,
+			"configuration_ids": configurationIDs,
+			"domain":            domain.ID,
+			"subscriptions":     flatSubscriptions,
+		}
+	}
+
+	return result
+}
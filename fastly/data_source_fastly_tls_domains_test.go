@@ -0,0 +1,138 @@
+package fastly
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/stretchr/testify/require"
+
+	gofastly "github.com/fastly/go-fastly/v12/fastly"
+)
+
+func TestJoinTLSDomains(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	soon := now.AddDate(0, 0, 10)
+	later := now.AddDate(0, 0, 90)
+
+	// The listed domains only hold the IDs of their relationships.
+	domains := []*gofastly.TLSDomain{
+		{
+			ID:           "www.example.org",
+			Certificates: []*gofastly.CustomTLSCertificate{{ID: "cert-org"}},
+		},
+		{
+			ID:            "www.example.com",
+			Certificates:  []*gofastly.CustomTLSCertificate{{ID: "cert-com"}},
+			Subscriptions: []*gofastly.TLSSubscription{{ID: "sub"}},
+		},
+	}
+	activations := []*gofastly.TLSActivation{
+		{
+			ID:            "act-com",
+			Certificate:   &gofastly.CustomTLSCertificate{ID: "cert-com"},
+			Configuration: &gofastly.TLSConfiguration{ID: "config-com"},
+			Domain:        &gofastly.TLSDomain{ID: "www.example.com"},
+		},
+		{
+			ID:            "act-org",
+			Certificate:   &gofastly.CustomTLSCertificate{ID: "cert-org"},
+			Configuration: &gofastly.TLSConfiguration{ID: "config-org"},
+			Domain:        &gofastly.TLSDomain{ID: "www.example.org"},
+		},
+	}
+	certificates := []*gofastly.CustomTLSCertificate{
+		{ID: "cert-com", Name: "com", NotAfter: &soon},
+		{ID: "cert-org", Name: "org", NotAfter: &later},
+	}
+	subscriptions := []*gofastly.TLSSubscription{
+		{ID: "sub", State: "issued", Configuration: &gofastly.TLSConfiguration{ID: "config-com"}},
+	}
+	joinTLSDomains(domains, activations, certificates, subscriptions)
+
+	for name, tc := range map[string]struct {
+		filters []TLSDomainPredicate
+		want    []string
+	}{
+		"none":          {nil, []string{"www.example.org", "www.example.com"}},
+		"configuration": {[]TLSDomainPredicate{tlsDomainUsesConfiguration("config-org")}, []string{"www.example.org"}},
+		"expiry":        {[]TLSDomainPredicate{tlsDomainCertificateExpiresBefore(now.AddDate(0, 0, 30))}, []string{"www.example.com"}},
+		"both":          {[]TLSDomainPredicate{tlsDomainUsesConfiguration("config-org"), tlsDomainCertificateExpiresBefore(now.AddDate(0, 0, 30))}, nil},
+	} {
+		var got []string
+		for _, domain := range domains {
+			if filterTLSDomain(domain, tc.filters) {
+				got = append(got, domain.ID)
+			}
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: expected domains %v, got %v", name, tc.want, got)
+		}
+	}
+
+	flattened := flattenTLSDomains(domains)
+	com := flattened[0]
+	if com["domain"] != "www.example.com" {
+		t.Fatalf("expected domains sorted by name, got %v first", com["domain"])
+	}
+	if got := com["certificates"].([]map[string]any)[0]["name"]; got != "com" {
+		t.Errorf("expected the certificate to be joined, got name %v", got)
+	}
+	if got := com["subscriptions"].([]map[string]any)[0]["state"]; got != "issued" {
+		t.Errorf("expected the subscription to be joined, got state %v", got)
+	}
+	if got := com["activations"].([]map[string]any)[0]["certificate_not_after"]; got != soon.Format(time.RFC3339) {
+		t.Errorf("expected the activation's certificate to be joined, got certificate_not_after %v", got)
+	}
+	if got := com["configuration_ids"]; !reflect.DeepEqual(got, []string{"config-com"}) {
+		t.Errorf("expected configuration_ids [config-com], got %v", got)
+	}
+}
+
+func TestAccFastlyDataSourceTLSDomains_basic(t *testing.T) {
+	name := acctest.RandomWithPrefix(testResourcePrefix)
+	domain := fmt.Sprintf("%s.example", name)
+
+	key, cert, err := generateKeyAndCert(domain)
+	require.NoError(t, err)
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		ProviderFactories:         testAccProviders,
+		PreventPostDestroyRefresh: true,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccFastlyDataSourceTLSDomainResources(key, cert, name),
+			},
+			{
+				Config: testAccFastlyDataSourceTLSDomainsWithDataSource(key, cert, domain, name),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.fastly_tls_domains.subject", "domains.#", "1"),
+					resource.TestCheckResourceAttr("data.fastly_tls_domains.subject", "domains.0.domain", domain),
+					resource.TestCheckResourceAttr("data.fastly_tls_domains.subject", "domains.0.certificates.#", "1"),
+					resource.TestCheckResourceAttrPair(
+						"data.fastly_tls_domains.subject", "domains.0.certificates.0.id",
+						"fastly_tls_certificate.example", "id",
+					),
+					resource.TestCheckResourceAttrSet("data.fastly_tls_domains.subject", "domains.0.certificates.0.not_after"),
+				),
+			},
+		},
+	})
+}
+
+func testAccFastlyDataSourceTLSDomainsWithDataSource(key string, cert string, domain string, name string) string {
+	return fmt.Sprintf(`
+%s
+data "fastly_tls_domains" "subject" {
+  domain_glob                     = "%s"
+  certificate_expires_within_days = 100
+}
+`, testAccFastlyDataSourceTLSDomainResources(key, cert, name), strings.ToUpper(domain))
+}
@@ -0,0 +1,437 @@
+package fastly
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// serviceHCLIgnoredAttributes are the attributes of a service resource that
+// only control the behaviour of the provider, and so aren't generated.
+var serviceHCLIgnoredAttributes = map[string]bool{
+	"activate":         true,
+	"activation_check": true,
+	"drift_policy":     true,
+	"force_destroy":    true,
+	"preflight_checks": true,
+	"reuse":            true,
+	"source_snapshot":  true,
+	"stage":            true,
+}
+
+// serviceHCLVariables are the attributes that can't be read from the API, and
+// are replaced by a variable reference along with sensitive attributes.
+var serviceHCLVariables = map[string]string{
+	"package.filename": "The path to the Wasm deployment package of the service.",
+}
+
+// serviceHCLComputedDefaults are the optional computed attributes of a
+// service resource that are generated unless they have the value the
+// provider defaults them to.
+var serviceHCLComputedDefaults = map[string]any{
+	"comment":         ManagedByTerraform,
+	"version_comment": "",
+}
+
+var serviceHCLInvalidName = regexp.MustCompile(`[^a-z0-9_]+`)
+
+func dataSourceFastlyServiceHCL() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceFastlyServiceHCLRead,
+		Schema: map[string]*schema.Schema{
+			"hcl": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The generated configuration: an `import` block, a `variable` block for each attribute that can't be generated, and the `fastly_service_vcl` or `fastly_service_compute` resource.",
+			},
+			"resource_name": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Computed:         true,
+				Description:      "The name of the generated resource. Defaults to the name of the service, converted to a valid resource name (e.g. `My Service` becomes `my_service`).",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringMatch(regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_-]*$`), "must be a valid resource name")),
+			},
+			"service_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Alphanumeric string identifying the service.",
+			},
+			"service_type": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The type of the service, either `vcl` or `wasm`.",
+			},
+			"variables": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The names of the variables referenced by the generated configuration, which must be given a value. These are sensitive attributes (e.g. logging endpoint credentials), which are not included in the configuration, and attributes that can't be read from the API (e.g. the `filename` of a Compute package).",
+			},
+			"version": {
+				Type:             schema.TypeInt,
+				Optional:         true,
+				Description:      "The version of the service to generate the configuration from. Defaults to the active version, or the latest version if no version has been activated.",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(1)),
+			},
+		},
+	}
+}
+
+func dataSourceFastlyServiceHCLRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	serviceID := d.Get("service_id").(string)
+
+	log.Printf("[DEBUG] Generating HCL for Fastly Service (%s)", serviceID)
+	rd, serviceDef, diags := readServiceConfiguration(ctx, meta, serviceID, d.Get("version").(int))
+	if diags.HasError() {
+		return diags
+	}
+
+	resourceType := "fastly_service_vcl"
+	if serviceDef.GetType() == ServiceTypeCompute {
+		resourceType = "fastly_service_compute"
+	}
+	importID := serviceID
+	if v, ok := d.GetOk("version"); ok {
+		importID = fmt.Sprintf("%s@%d", serviceID, v.(int))
+	}
+
+	resourceName := d.Get("resource_name").(string)
+	if resourceName == "" {
+		resourceName = serviceHCLResourceName(rd.Get("name").(string))
+	}
+
+	content, variables := generateServiceHCL(rd, resourceService(serviceDef).Schema, resourceType, resourceName, importID)
+
+	d.SetId(serviceID)
+	for k, v := range map[string]any{
+		"hcl":           content,
+		"resource_name": resourceName,
+		"service_type":  serviceDef.GetType(),
+		"variables":     variables,
+	} {
+		if err := d.Set(k, v); err != nil {
+			return append(diags, diag.FromErr(err)...)
+		}
+	}
+
+	return diags
+}
+
+// serviceHCLResourceName converts a service name into a valid resource name.
+func serviceHCLResourceName(name string) string {
+	name = strings.Trim(serviceHCLInvalidName.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return "service"
+	}
+	if name[0] >= '0' && name[0] <= '9' {
+		return "service_" + name
+	}
+	return name
+}
+
+// serviceHCLGenerator builds the configuration of a service resource, and the
+// variables it references.
+type serviceHCLGenerator struct {
+	resourceName string
+	variables    []serviceHCLVariable
+	seen         map[string]int
+}
+
+type serviceHCLVariable struct {
+	name        string
+	description string
+	sensitive   bool
+}
+
+// generateServiceHCL returns the formatted configuration of the service read
+// into d, and the names of the variables it references.
+func generateServiceHCL(d *schema.ResourceData, s map[string]*schema.Schema, resourceType, resourceName, importID string) (string, []string) {
+	g := &serviceHCLGenerator{resourceName: resourceName, seen: map[string]int{}}
+
+	resource := hclwrite.NewBlock("resource", []string{resourceType, resourceName})
+	body := resource.Body()
+
+	var attributes, blocks []string
+	for _, k := range serviceHCLKeys(s) {
+		if serviceHCLIgnoredAttributes[k] {
+			continue
+		}
+		if _, ok := s[k].Elem.(*schema.Resource); ok {
+			blocks = append(blocks, k)
+		} else {
+			attributes = append(attributes, k)
+		}
+	}
+	for _, k := range attributes {
+		g.writeAttribute(body, k, nil, s[k], d.Get(k))
+	}
+	for _, k := range blocks {
+		g.writeBlocks(body, k, nil, s[k], d.Get(k))
+	}
+
+	f := hclwrite.NewEmptyFile()
+	root := f.Body()
+
+	imp := root.AppendNewBlock("import", nil)
+	imp.Body().SetAttributeTraversal("to", hcl.Traversal{
+		hcl.TraverseRoot{Name: resourceType},
+		hcl.TraverseAttr{Name: resourceName},
+	})
+	imp.Body().SetAttributeValue("id", cty.StringVal(importID))
+	root.AppendNewline()
+
+	names := make([]string, 0, len(g.variables))
+	for _, v := range g.variables {
+		names = append(names, v.name)
+
+		variable := root.AppendNewBlock("variable", []string{v.name})
+		variable.Body().SetAttributeTraversal("type", hcl.Traversal{hcl.TraverseRoot{Name: "string"}})
+		variable.Body().SetAttributeValue("description", cty.StringVal(v.description))
+		if v.sensitive {
+			variable.Body().SetAttributeValue("sensitive", cty.True)
+		}
+		root.AppendNewline()
+	}
+
+	root.AppendBlock(resource)
+
+	return string(hclwrite.Format(f.Bytes())), names
+}
+
+// writeBlocks writes a nested block for each element of a list or set,
+// ordered by name.
+func (g *serviceHCLGenerator) writeBlocks(body *hclwrite.Body, key string, path []string, s *schema.Schema, v any) {
+	var elements []any
+	switch l := v.(type) {
+	case *schema.Set:
+		elements = l.List()
+	case []any:
+		elements = l
+	}
+	sort.SliceStable(elements, func(i, j int) bool {
+		ni, _ := elements[i].(map[string]any)["name"].(string)
+		nj, _ := elements[j].(map[string]any)["name"].(string)
+		return ni < nj
+	})
+
+	r := s.Elem.(*schema.Resource)
+	for _, e := range elements {
+		m, ok := e.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		// The name of a block identifies the variables of its attributes.
+		blockPath := append(append([]string{}, path...), key)
+		if name, ok := m["name"].(string); ok && name != "" {
+			blockPath = append(blockPath, name)
+		}
+
+		body.AppendNewline()
+		block := body.AppendNewBlock(key, nil)
+
+		keys := serviceHCLKeys(r.Schema)
+		for _, k := range keys {
+			if _, ok := r.Schema[k].Elem.(*schema.Resource); !ok {
+				g.writeAttribute(block.Body(), k, blockPath, r.Schema[k], m[k])
+			}
+		}
+		for _, k := range keys {
+			if _, ok := r.Schema[k].Elem.(*schema.Resource); ok {
+				g.writeBlocks(block.Body(), k, blockPath, r.Schema[k], m[k])
+			}
+		}
+	}
+}
+
+// writeAttribute writes an attribute if it differs from its default. Computed
+// attributes are skipped (see serviceHCLComputedDefaults for exceptions), and
+// sensitive values are replaced by a reference to a variable.
+func (g *serviceHCLGenerator) writeAttribute(body *hclwrite.Body, key string, path []string, s *schema.Schema, v any) {
+	blockKey := key
+	if len(path) > 0 {
+		blockKey = path[0] + "." + key
+	}
+
+	if def, ok := serviceHCLComputedDefaults[blockKey]; ok {
+		if reflect.DeepEqual(def, v) {
+			return
+		}
+	} else if s.Computed {
+		return
+	}
+
+	if description, ok := serviceHCLVariables[blockKey]; ok {
+		g.writeVariable(body, key, path, description, false)
+		return
+	}
+
+	if serviceHCLIsDefault(s, v) {
+		return
+	}
+
+	if s.Sensitive {
+		description := fmt.Sprintf("The %s of %s.", key, strings.Join(path, " "))
+		g.writeVariable(body, key, path, description, true)
+		return
+	}
+
+	if str, ok := v.(string); ok {
+		body.SetAttributeRaw(key, serviceHCLStringTokens(str))
+		return
+	}
+	if val, ok := serviceHCLValue(s, v); ok {
+		body.SetAttributeValue(key, val)
+	}
+}
+
+func (g *serviceHCLGenerator) writeVariable(body *hclwrite.Body, key string, path []string, description string, sensitive bool) {
+	parts := append(append([]string{g.resourceName}, path...), key)
+	for i, p := range parts {
+		parts[i] = strings.Trim(serviceHCLInvalidName.ReplaceAllString(strings.ToLower(p), "_"), "_")
+	}
+	name := strings.Join(parts, "_")
+
+	// Names that only differ in characters that aren't valid in a variable
+	// name would otherwise reference the same variable.
+	g.seen[name]++
+	if n := g.seen[name]; n > 1 {
+		name = fmt.Sprintf("%s_%d", name, n)
+	}
+
+	body.SetAttributeTraversal(key, hcl.Traversal{
+		hcl.TraverseRoot{Name: "var"},
+		hcl.TraverseAttr{Name: name},
+	})
+	g.variables = append(g.variables, serviceHCLVariable{
+		name:        name,
+		description: description,
+		sensitive:   sensitive,
+	})
+}
+
+// serviceHCLIsDefault reports whether a value is the default of the schema,
+// or the zero value of its type if it has no default.
+func serviceHCLIsDefault(s *schema.Schema, v any) bool {
+	switch val := v.(type) {
+	case nil:
+		return true
+	case *schema.Set:
+		return val.Len() == 0
+	case []any:
+		return len(val) == 0
+	case map[string]any:
+		return len(val) == 0
+	}
+	if s.Default != nil {
+		return reflect.DeepEqual(s.Default, v)
+	}
+	return reflect.DeepEqual(s.ZeroValue(), v)
+}
+
+// serviceHCLValue converts a value of the given schema into a cty.Value.
+func serviceHCLValue(s *schema.Schema, v any) (cty.Value, bool) {
+	switch val := v.(type) {
+	case string:
+		return cty.StringVal(val), true
+	case bool:
+		return cty.BoolVal(val), true
+	case int:
+		return cty.NumberIntVal(int64(val)), true
+	case float64:
+		return cty.NumberFloatVal(val), true
+	case map[string]any:
+		m := map[string]cty.Value{}
+		for k, e := range val {
+			m[k] = cty.StringVal(fmt.Sprint(e))
+		}
+		return cty.MapVal(m), true
+	case *schema.Set, []any:
+		var l []any
+		if set, ok := val.(*schema.Set); ok {
+			l = set.List()
+		} else {
+			l = val.([]any)
+		}
+		elem, ok := s.Elem.(*schema.Schema)
+		if !ok {
+			return cty.NilVal, false
+		}
+		var values []cty.Value
+		for _, e := range l {
+			ev, ok := serviceHCLValue(elem, e)
+			if !ok {
+				return cty.NilVal, false
+			}
+			values = append(values, ev)
+		}
+		if s.Type == schema.TypeSet {
+			sort.Slice(values, func(i, j int) bool {
+				return values[i].GoString() < values[j].GoString()
+			})
+		}
+		return cty.ListVal(values), true
+	}
+	return cty.NilVal, false
+}
+
+// serviceHCLStringTokens returns the tokens of a string, using a heredoc for
+// multi-line strings such as VCL.
+func serviceHCLStringTokens(s string) hclwrite.Tokens {
+	if !strings.Contains(strings.TrimSuffix(s, "\n"), "\n") || !strings.HasSuffix(s, "\n") {
+		return hclwrite.TokensForValue(cty.StringVal(s))
+	}
+
+	delimiter := "EOT"
+	lines := strings.Split(s, "\n")
+	for containsLine(lines, delimiter) {
+		delimiter += "_"
+	}
+
+	// NOTE: Escape sequences aren't interpreted in a heredoc, but template
+	// sequences are.
+	s = strings.NewReplacer("${", "$${", "%{", "%%{").Replace(s)
+
+	return hclwrite.Tokens{
+		{Type: hclsyntax.TokenOHeredoc, Bytes: []byte("<<" + delimiter + "\n")},
+		{Type: hclsyntax.TokenStringLit, Bytes: []byte(s)},
+		{Type: hclsyntax.TokenCHeredoc, Bytes: []byte(delimiter)},
+	}
+}
+
+func containsLine(lines []string, line string) bool {
+	for _, l := range lines {
+		if strings.TrimSpace(l) == line {
+			return true
+		}
+	}
+	return false
+}
+
+// serviceHCLKeys returns the keys of a schema in the order they are generated:
+// `name` first, followed by the other keys in alphabetical order.
+func serviceHCLKeys(s map[string]*schema.Schema) []string {
+	keys := make([]string, 0, len(s))
+	for k := range s {
+		if k != "name" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	if _, ok := s["name"]; ok {
+		keys = append([]string{"name"}, keys...)
+	}
+	return keys
+}
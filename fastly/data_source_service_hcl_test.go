@@ -0,0 +1,185 @@
+package fastly
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestGenerateServiceHCL(t *testing.T) {
+	s := resourceService(vclService).Schema
+	d := schema.TestResourceDataRaw(t, s, map[string]any{
+		"name":    "Example Service",
+		"comment": ManagedByTerraform,
+		"backend": []any{
+			map[string]any{
+				"name":           "origin b",
+				"address":        "b.example.com",
+				"port":           443,
+				"ssl_client_key": "secret",
+			},
+			map[string]any{
+				"name":    "origin a",
+				"address": "a.example.com",
+			},
+		},
+		"director": []any{
+			map[string]any{
+				"name":     "dir",
+				"backends": []any{"origin b", "origin a"},
+			},
+		},
+		"domain": []any{
+			map[string]any{"name": "example.com"},
+		},
+		"snippet": []any{
+			map[string]any{
+				"name":    "snippet",
+				"type":    "recv",
+				"content": "set req.http.X-Template = \"${var}\";\nset req.http.X-Path = \"a\\b\";\n",
+			},
+		},
+	})
+	d.SetId("123")
+
+	content, variables := generateServiceHCL(d, s, "fastly_service_vcl", "example_service", "123@2")
+
+	f, diags := hclsyntax.ParseConfig([]byte(content), "generated.tf", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("generated configuration is invalid: %s\n%s", diags, content)
+	}
+
+	if want := []string{"example_service_backend_origin_b_ssl_client_key"}; !reflect.DeepEqual(variables, want) {
+		t.Errorf("expected variables %v, got %v", want, variables)
+	}
+
+	for _, want := range []string{
+		`to = fastly_service_vcl.example_service`,
+		`id = "123@2"`,
+		`variable "example_service_backend_origin_b_ssl_client_key" {`,
+		`sensitive   = true`,
+		`ssl_client_key = var.example_service_backend_origin_b_ssl_client_key`,
+		`backends = ["origin a", "origin b"]`,
+		"content = <<EOT\nset req.http.X-Template = \"$${var}\";\nset req.http.X-Path = \"a\\b\";\nEOT",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("expected generated configuration to contain %q:\n%s", want, content)
+		}
+	}
+
+	// Defaults and computed attributes are omitted.
+	for _, unwanted := range []string{"port = 80", "comment", "activate", "active_version", "connect_timeout"} {
+		if strings.Contains(content, unwanted) {
+			t.Errorf("expected generated configuration not to contain %q:\n%s", unwanted, content)
+		}
+	}
+
+	// Blocks are ordered by name.
+	if a, b := strings.Index(content, `"origin a"`), strings.Index(content, `"origin b"`); a > b {
+		t.Errorf("expected backends to be ordered by name:\n%s", content)
+	}
+
+	// The heredoc evaluates to the original content.
+	body := f.Body.(*hclsyntax.Body)
+	resourceBlock := body.Blocks[len(body.Blocks)-1]
+	for _, block := range resourceBlock.Body.Blocks {
+		if block.Type != "snippet" {
+			continue
+		}
+		v, diags := block.Body.Attributes["content"].Expr.Value(nil)
+		if diags.HasErrors() {
+			t.Fatalf("unexpected error evaluating snippet content: %s", diags)
+		}
+		if got := v.AsString(); got != d.Get("snippet").(*schema.Set).List()[0].(map[string]any)["content"] {
+			t.Errorf("unexpected snippet content %q", got)
+		}
+	}
+}
+
+func TestGenerateServiceHCL_package(t *testing.T) {
+	s := resourceService(computeService).Schema
+	d := schema.TestResourceDataRaw(t, s, map[string]any{
+		"name": "compute",
+		"package": []any{
+			map[string]any{"filename": ""},
+		},
+	})
+	d.SetId("123")
+
+	content, variables := generateServiceHCL(d, s, "fastly_service_compute", "compute", "123")
+	if want := []string{"compute_package_filename"}; !reflect.DeepEqual(variables, want) {
+		t.Errorf("expected variables %v, got %v", want, variables)
+	}
+	if !strings.Contains(content, "filename = var.compute_package_filename") {
+		t.Errorf("expected the package filename to reference a variable:\n%s", content)
+	}
+	if strings.Contains(content, "source_code_hash") {
+		t.Errorf("expected computed attributes to be omitted:\n%s", content)
+	}
+}
+
+func TestServiceHCLResourceName(t *testing.T) {
+	for name, want := range map[string]string{
+		"My Service":     "my_service",
+		"api.example.io": "api_example_io",
+		"123 Service":    "service_123_service",
+		"---":            "service",
+	} {
+		if got := serviceHCLResourceName(name); got != want {
+			t.Errorf("%q: expected %q, got %q", name, want, got)
+		}
+	}
+}
+
+func TestAccFastlyDataSourceServiceHCL_basic(t *testing.T) {
+	name := fmt.Sprintf("tf-test-%s", acctest.RandString(10))
+	domain := fmt.Sprintf("fastly-test.%s.com", name)
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      testAccCheckServiceVCLDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+resource "fastly_service_vcl" "example" {
+  name = "%s"
+
+  domain {
+    name = "%s"
+  }
+
+  backend {
+    address = "aws.amazon.com"
+    name    = "amazon docs"
+  }
+
+  force_destroy = true
+}
+
+data "fastly_service_hcl" "example" {
+  service_id    = fastly_service_vcl.example.id
+  resource_name = "example"
+  depends_on    = [fastly_service_vcl.example]
+}
+`, name, domain),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.fastly_service_hcl.example", "service_type", ServiceTypeVCL),
+					resource.TestCheckResourceAttr("data.fastly_service_hcl.example", "variables.#", "0"),
+					resource.TestMatchResourceAttr("data.fastly_service_hcl.example", "hcl", regexp.MustCompile(`resource "fastly_service_vcl" "example"`)),
+					resource.TestMatchResourceAttr("data.fastly_service_hcl.example", "hcl", regexp.MustCompile(regexp.QuoteMeta(fmt.Sprintf(`name = "%s"`, domain)))),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,251 @@
+package fastly
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"gopkg.in/yaml.v3"
+
+	gofastly "github.com/fastly/go-fastly/v12/fastly"
+)
+
+const (
+	// serviceVersionDiffAdded indicates an object only exists in to_version.
+	serviceVersionDiffAdded = "added"
+	// serviceVersionDiffChanged indicates an object exists in both versions
+	// with different attributes.
+	serviceVersionDiffChanged = "changed"
+	// serviceVersionDiffRemoved indicates an object only exists in from_version.
+	serviceVersionDiffRemoved = "removed"
+)
+
+func dataSourceFastlyServiceVersionDiff() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceFastlyServiceVersionDiffRead,
+		Schema: map[string]*schema.Schema{
+			"changes": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "List of objects that differ between the two versions, sorted by type and name.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"action": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "How the object differs in `to_version`. One of `added`, `removed` or `changed`.",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the object (e.g. the backend name, or the setting key). Empty for top-level attributes such as `comment`.",
+						},
+						"type": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Type of the object, as named by the diff API (e.g. `backends`, `snippets`, `settings`).",
+						},
+					},
+				},
+			},
+			"diff": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The text diff of the two versions returned by the Fastly API.",
+			},
+			"from_version": {
+				Type:             schema.TypeInt,
+				Required:         true,
+				Description:      "The version to diff from (e.g. the `active_version` of a service).",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(1)),
+			},
+			"has_changes": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the two versions differ.",
+			},
+			"service_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Alphanumeric string identifying the service.",
+			},
+			"to_version": {
+				Type:             schema.TypeInt,
+				Required:         true,
+				Description:      "The version to diff to (e.g. the `cloned_version` of a service).",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(1)),
+			},
+		},
+	}
+}
+
+func dataSourceFastlyServiceVersionDiffRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	conn := meta.(*APIClient).conn
+
+	serviceID := d.Get("service_id").(string)
+	from := d.Get("from_version").(int)
+	to := d.Get("to_version").(int)
+
+	log.Printf("[DEBUG] Reading diff of service %s from version %d to %d", serviceID, from, to)
+
+	diff, err := conn.GetDiff(ctx, &gofastly.GetDiffInput{
+		Format:    "text",
+		From:      from,
+		ServiceID: serviceID,
+		To:        to,
+	})
+	if err != nil {
+		return diag.Errorf("error fetching diff of service %s from version %d to %d: %s", serviceID, from, to, err)
+	}
+
+	changes, err := parseServiceVersionDiff(diff.Diff)
+	if err != nil {
+		return diag.Errorf("error parsing diff of service %s from version %d to %d: %s", serviceID, from, to, err)
+	}
+
+	d.SetId(fmt.Sprintf("%s/%d/%d", serviceID, from, to))
+
+	if err := d.Set("diff", diff.Diff); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("changes", changes); err != nil {
+		return diag.Errorf("error setting changes: %s", err)
+	}
+	if err := d.Set("has_changes", len(changes) > 0); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+// parseServiceVersionDiff converts the text diff returned by the Fastly API
+// into a list of changed objects.
+//
+// NOTE: The text diff is a line diff (prefixed with ' ', '-' or '+') of a YAML
+// document describing each version. As it includes every line, the
+// documents for both versions are rebuilt and compared.
+func parseServiceVersionDiff(diff string) ([]map[string]any, error) {
+	var from, to strings.Builder
+	for _, line := range strings.Split(diff, "\n") {
+		if line == "" {
+			continue
+		}
+		prefix, content := line[0], line[1:]
+		switch prefix {
+		case ' ':
+			from.WriteString(content + "\n")
+			to.WriteString(content + "\n")
+		case '-':
+			from.WriteString(content + "\n")
+		case '+':
+			to.WriteString(content + "\n")
+		default:
+			return nil, fmt.Errorf("unexpected diff line: %q", line)
+		}
+	}
+
+	var fromDoc, toDoc map[string]any
+	if err := yaml.Unmarshal([]byte(from.String()), &fromDoc); err != nil {
+		return nil, fmt.Errorf("invalid from version: %w", err)
+	}
+	if err := yaml.Unmarshal([]byte(to.String()), &toDoc); err != nil {
+		return nil, fmt.Errorf("invalid to version: %w", err)
+	}
+
+	changes := []map[string]any{}
+	for _, key := range sortedKeys(fromDoc, toDoc) {
+		changes = append(changes, diffServiceVersionObjects(key, fromDoc[key], toDoc[key])...)
+	}
+	return changes, nil
+}
+
+// diffServiceVersionObjects compares a top-level attribute of both versions.
+// Lists of named objects and maps are compared element by element.
+func diffServiceVersionObjects(typ string, from, to any) []map[string]any {
+	if reflect.DeepEqual(from, to) {
+		return nil
+	}
+
+	fromObjects, fromOK := namedServiceVersionObjects(from)
+	toObjects, toOK := namedServiceVersionObjects(to)
+	if !fromOK || !toOK {
+		action := serviceVersionDiffChanged
+		switch {
+		case from == nil:
+			action = serviceVersionDiffAdded
+		case to == nil:
+			action = serviceVersionDiffRemoved
+		}
+		return []map[string]any{newServiceVersionChange(action, typ, "")}
+	}
+
+	var changes []map[string]any
+	for _, name := range sortedKeys(fromObjects, toObjects) {
+		f, inFrom := fromObjects[name]
+		t, inTo := toObjects[name]
+		switch {
+		case !inFrom:
+			changes = append(changes, newServiceVersionChange(serviceVersionDiffAdded, typ, name))
+		case !inTo:
+			changes = append(changes, newServiceVersionChange(serviceVersionDiffRemoved, typ, name))
+		case !reflect.DeepEqual(f, t):
+			changes = append(changes, newServiceVersionChange(serviceVersionDiffChanged, typ, name))
+		}
+	}
+	return changes
+}
+
+// namedServiceVersionObjects indexes a map, or a list of objects with a name,
+// by name. An empty value (e.g. `backends: []`) has no objects.
+func namedServiceVersionObjects(v any) (map[string]any, bool) {
+	switch v := v.(type) {
+	case nil:
+		return map[string]any{}, true
+	case map[string]any:
+		return v, true
+	case []any:
+		objects := make(map[string]any, len(v))
+		for _, e := range v {
+			m, ok := e.(map[string]any)
+			if !ok {
+				return nil, false
+			}
+			name, ok := m["name"]
+			if !ok {
+				return nil, false
+			}
+			objects[fmt.Sprint(name)] = m
+		}
+		return objects, true
+	}
+	return nil, false
+}
+
+func newServiceVersionChange(action, typ, name string) map[string]any {
+	return map[string]any{
+		"action": action,
+		"name":   name,
+		"type":   typ,
+	}
+}
+
+// sortedKeys returns the union of the keys of both maps, sorted.
+func sortedKeys(a, b map[string]any) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,117 @@
+package fastly
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestParseServiceVersionDiff(t *testing.T) {
+	cases := []struct {
+		name string
+		diff string
+		want []map[string]any
+	}{
+		{
+			name: "no changes",
+			diff: " backends: []\n comment: ''\n settings:\n   general.default_ttl: 3600\n",
+			want: []map[string]any{},
+		},
+		{
+			name: "backend added",
+			diff: " acls: []\n-backends: []\n+backends:\n+- name: test-backend\n+  address: integ-test.go-fastly.com\n+  port: 80\n comment: ''\n",
+			want: []map[string]any{
+				{"action": "added", "name": "test-backend", "type": "backends"},
+			},
+		},
+		{
+			name: "objects removed and changed",
+			diff: " backends:\n-- name: backend-a\n-  port: 80\n - name: backend-b\n-  port: 80\n+  port: 443\n-comment: ''\n+comment: updated\n settings:\n-  general.default_ttl: 3600\n+  general.default_ttl: 60\n",
+			want: []map[string]any{
+				{"action": "removed", "name": "backend-a", "type": "backends"},
+				{"action": "changed", "name": "backend-b", "type": "backends"},
+				{"action": "changed", "name": "", "type": "comment"},
+				{"action": "changed", "name": "general.default_ttl", "type": "settings"},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := parseServiceVersionDiff(c.diff)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(got, c.want) {
+				t.Fatalf("Error matching:\nexpected: %#v\ngot: %#v", c.want, got)
+			}
+		})
+	}
+
+	if _, err := parseServiceVersionDiff("@@ -1 +1 @@\n"); err == nil {
+		t.Fatal("expected an error for an unsupported diff format")
+	}
+}
+
+func TestAccFastlyDataSourceServiceVersionDiff_basic(t *testing.T) {
+	name := fmt.Sprintf("tf-test-%s", acctest.RandString(10))
+	domain := fmt.Sprintf("fastly-test.%s.com", name)
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      testAccCheckServiceVCLDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccFastlyDataSourceServiceVersionDiffConfig(name, domain, true, ""),
+			},
+			{
+				Config: testAccFastlyDataSourceServiceVersionDiffConfig(name, domain, false, `
+  backend {
+    address = "httpbin.org"
+    name    = "pending"
+  }
+`) + `
+data "fastly_service_version_diff" "example" {
+  service_id   = fastly_service_vcl.example.id
+  from_version = fastly_service_vcl.example.active_version
+  to_version   = fastly_service_vcl.example.cloned_version
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.fastly_service_version_diff.example", "has_changes", "true"),
+					resource.TestCheckTypeSetElemNestedAttrs("data.fastly_service_version_diff.example", "changes.*", map[string]string{
+						"action": "added",
+						"name":   "pending",
+						"type":   "backends",
+					}),
+				),
+			},
+		},
+	})
+}
+
+func testAccFastlyDataSourceServiceVersionDiffConfig(name, domain string, activate bool, extra string) string {
+	return fmt.Sprintf(`
+resource "fastly_service_vcl" "example" {
+  name     = "%s"
+  activate = %t
+
+  domain {
+    name = "%s"
+  }
+
+  backend {
+    address = "aws.amazon.com"
+    name    = "amazon docs"
+  }
+%s
+  force_destroy = true
+}
+`, name, activate, domain, extra)
+}
@@ -3,9 +3,11 @@ package fastly
 import (
 	"context"
 	"os"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 
 	gofastly "github.com/fastly/go-fastly/v12/fastly"
 
@@ -37,18 +39,125 @@ func Provider() *schema.Provider {
 				DefaultFunc: schema.EnvDefaultFunc("FASTLY_API_URL", gofastly.DefaultEndpoint),
 				Description: "Fastly API URL",
 			},
+			"clone_version_wait": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "Controls how the provider waits for a newly cloned service version to become available before modifying it. The version is polled until it is found and unlocked, backing off exponentially between attempts.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"initial_backoff": {
+							Type:             schema.TypeString,
+							Optional:         true,
+							Default:          DefaultCloneVersionInitialBackoff.String(),
+							Description:      "The delay before polling the cloned version again, doubled after each attempt (e.g. `500ms`). Default: `500ms`",
+							ValidateDiagFunc: validateDuration(),
+						},
+						"max_backoff": {
+							Type:             schema.TypeString,
+							Optional:         true,
+							Default:          DefaultCloneVersionMaxBackoff.String(),
+							Description:      "The maximum delay between polling attempts (e.g. `5s`). Default: `5s`",
+							ValidateDiagFunc: validateDuration(),
+						},
+						"max_wait": {
+							Type:             schema.TypeString,
+							Optional:         true,
+							Default:          DefaultCloneVersionMaxWait.String(),
+							Description:      "The maximum total time to wait for the cloned version to become available (e.g. `1m`). Default: `1m0s`",
+							ValidateDiagFunc: validateDuration(),
+						},
+					},
+				},
+			},
+			"credentials": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "The API keys of additional Fastly accounts, so that a single provider configuration can manage resources across accounts. Resources and data sources select the account with their `account` argument, and use `api_key` if it isn't set",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"api_key": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Sensitive:   true,
+							Description: "Fastly API Key of the account",
+						},
+						"base_url": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Fastly API URL of the account. Defaults to the provider's `base_url`",
+						},
+						"name": {
+							Type:             schema.TypeString,
+							Required:         true,
+							Description:      "The name of the account, referenced by the `account` argument of resources and data sources",
+							ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsNotEmpty),
+						},
+					},
+				},
+			},
+			"default_service_comment": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Description:      "The comment of services created by the provider that don't set `comment`, instead of `Managed by Terraform`. It may contain the placeholders `{{workspace}}` (the `TF_WORKSPACE` or `TFC_WORKSPACE_NAME` environment variable, which must be set), `{{env.NAME}}` (the value of the `NAME` environment variable, e.g. a git SHA set by a CI pipeline) and `{{timestamp}}` (the current time in RFC 3339 format). The comment is rendered when a service is created, and is not updated afterwards",
+				ValidateDiagFunc: validateCommentTemplate(),
+			},
+			"default_version_comment": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Description:      "The comment of service versions created by the provider for services that don't set `version_comment`, so that each version can be traced back to the run that created it (e.g. `Deployed by {{env.CI_PIPELINE_ID}} at {{timestamp}}`). It supports the same placeholders as `default_service_comment`, and is rendered when each version is created",
+				ValidateDiagFunc: validateCommentTemplate(),
+			},
 			"force_http2": {
 				Type:        schema.TypeBool,
 				Optional:    true,
 				Default:     false,
 				Description: "Set this to `true` to disable HTTP/1.x fallback mechanism that the underlying Go library will attempt upon connection to `api.fastly.com:443` by default. This may slightly improve the provider's performance and reduce unnecessary TLS handshakes. Default: `false`",
 			},
+			"max_concurrent_requests": {
+				Type:             schema.TypeInt,
+				Optional:         true,
+				DefaultFunc:      schema.EnvDefaultFunc("FASTLY_MAX_CONCURRENT_REQUESTS", DefaultMaxConcurrentRequests),
+				Description:      "The maximum number of API requests made concurrently when applying changes to the nested blocks of a service (e.g. `backend` and logging blocks). Blocks that other blocks depend on (`condition`, `healthcheck`, `backend`, `product_enablement` and `director`) are still applied in order. It can also be sourced from the `FASTLY_MAX_CONCURRENT_REQUESTS` environment variable. Default: `1`",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IntBetween(1, 32)),
+			},
 			"no_auth": {
 				Type:        schema.TypeBool,
 				Optional:    true,
 				Default:     false,
 				Description: "Set to `true` if your configuration only consumes data sources that do not require authentication, such as `fastly_ip_ranges`",
 			},
+			"retry": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "Controls how API requests are retried when rejected by rate limiting (HTTP 429) or transient server errors (HTTP 500, 502, 503 and 504). The `Retry-After` and `Fastly-RateLimit-Reset` response headers are honoured. Requests that are not idempotent (`POST`, `PATCH`) are only retried when rate limited.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"max_attempts": {
+							Type:             schema.TypeInt,
+							Optional:         true,
+							Default:          DefaultRetryMaxAttempts,
+							Description:      "The maximum number of times a request is attempted. Set to `1` to disable retries. Default: `3`",
+							ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(1)),
+						},
+						"max_backoff": {
+							Type:             schema.TypeString,
+							Optional:         true,
+							Default:          DefaultRetryMaxBackoff.String(),
+							Description:      "The maximum delay between attempts (e.g. `30s`). Requests fail instead of waiting if the API asks to wait longer. Default: `30s`",
+							ValidateDiagFunc: validateDuration(),
+						},
+						"min_backoff": {
+							Type:             schema.TypeString,
+							Optional:         true,
+							Default:          DefaultRetryMinBackoff.String(),
+							Description:      "The delay before the first retry when the API does not specify one, doubled after each attempt (e.g. `1s`). Default: `1s`",
+							ValidateDiagFunc: validateDuration(),
+						},
+					},
+				},
+			},
 		},
 		DataSourcesMap: map[string]*schema.Resource{
 			"fastly_compute_acls":                            dataSourceFastlyComputeACLs(),
@@ -71,14 +180,18 @@ func Provider() *schema.Provider {
 			"fastly_ngwaf_workspaces":                        dataSourceFastlyNGWAFWorkspaces(),
 			"fastly_package_hash":                            dataSourceFastlyPackageHash(),
 			"fastly_secretstores":                            dataSourceFastlySecretStores(),
+			"fastly_service_hcl":                             dataSourceFastlyServiceHCL(),
+			"fastly_service_version_diff":                    dataSourceFastlyServiceVersionDiff(),
 			"fastly_services":                                dataSourceFastlyServices(),
 			"fastly_tls_activation":                          dataSourceFastlyTLSActivation(),
 			"fastly_tls_activation_ids":                      dataSourceFastlyTLSActivationIDs(),
+			"fastly_tls_activations":                         dataSourceFastlyTLSActivations(),
 			"fastly_tls_certificate":                         dataSourceFastlyTLSCertificate(),
 			"fastly_tls_certificate_ids":                     dataSourceFastlyTLSCertificateIDs(),
 			"fastly_tls_configuration":                       dataSourceFastlyTLSConfiguration(),
 			"fastly_tls_configuration_ids":                   dataSourceFastlyTLSConfigurationIDs(),
 			"fastly_tls_domain":                              dataSourceFastlyTLSDomain(),
+			"fastly_tls_domains":                             dataSourceFastlyTLSDomains(),
 			"fastly_tls_platform_certificate":                dataSourceFastlyTLSPlatformCertificate(),
 			"fastly_tls_platform_certificate_ids":            dataSourceFastlyTLSPlatformCertificateIDs(),
 			"fastly_tls_private_key":                         dataSourceFastlyTLSPrivateKey(),
@@ -97,6 +210,7 @@ func Provider() *schema.Provider {
 			"fastly_domain_v1":                               resourceFastlyDomainV1(),
 			"fastly_integration":                             resourceFastlyIntegration(),
 			"fastly_kvstore":                                 resourceFastlyKVStore(),
+			"fastly_kvstore_entries":                         resourceFastlyKVStoreEntries(),
 			"fastly_ngwaf_account_list":                      resourceFastlyNGWAFAccountList(),
 			"fastly_ngwaf_account_rule":                      resourceFastlyNGWAFAccountRule(),
 			"fastly_ngwaf_account_signal":                    resourceFastlyNGWAFAccountSignal(),
@@ -117,14 +231,19 @@ func Provider() *schema.Provider {
 			"fastly_ngwaf_workspace_signal":                  resourceFastlyNGWAFWorkspaceSignal(),
 			"fastly_object_storage_access_keys":              resourceObjectStorageAccessKey(),
 			"fastly_secretstore":                             resourceFastlySecretStore(),
+			"fastly_secretstore_secret":                      resourceFastlySecretStoreSecret(),
 			"fastly_service_acl_entries":                     resourceServiceACLEntries(),
 			"fastly_service_authorization":                   resourceServiceAuthorization(),
+			"fastly_service_clone":                           resourceServiceClone(),
 			"fastly_service_compute":                         resourceServiceCompute(),
 			"fastly_service_dictionary_items":                resourceServiceDictionaryItems(),
 			"fastly_service_dynamic_snippet_content":         resourceServiceDynamicSnippetContent(),
+			"fastly_service_rollout":                         resourceFastlyServiceRollout(),
 			"fastly_service_vcl":                             resourceServiceVCL(),
+			"fastly_service_version_activation":              resourceFastlyServiceVersionActivation(),
 			"fastly_tls_activation":                          resourceFastlyTLSActivation(),
 			"fastly_tls_certificate":                         resourceFastlyTLSCertificate(),
+			"fastly_tls_certificate_rotation":                resourceFastlyTLSCertificateRotation(),
 			"fastly_tls_mutual_authentication":               resourceFastlyTLSMutualAuthentication(),
 			"fastly_tls_platform_certificate":                resourceFastlyTLSPlatformCertificate(),
 			"fastly_tls_private_key":                         resourceFastlyTLSPrivateKey(),
@@ -134,16 +253,72 @@ func Provider() *schema.Provider {
 		},
 	}
 
+	addAccountSupport(provider)
+
 	provider.ConfigureContextFunc = func(_ context.Context, d *schema.ResourceData) (any, diag.Diagnostics) {
-		config := Config{
-			APIKey:     d.Get("api_key").(string),
-			BaseURL:    d.Get("base_url").(string),
-			ForceHTTP2: d.Get("force_http2").(bool),
-			NoAuth:     d.Get("no_auth").(bool),
-			UserAgent:  provider.UserAgent(TerraformProviderProductUserAgent, version.ProviderVersion),
-		}
+		config := expandProviderConfig(d, provider.UserAgent(TerraformProviderProductUserAgent, version.ProviderVersion))
 		return config.Client()
 	}
 
 	return provider
 }
+
+// expandProviderConfig converts the provider configuration into a Config.
+func expandProviderConfig(d *schema.ResourceData, userAgent string) Config {
+	return Config{
+		APIKey:                d.Get("api_key").(string),
+		BaseURL:               d.Get("base_url").(string),
+		CloneVersionWait:      expandCloneVersionWait(d.Get("clone_version_wait").([]any)),
+		Credentials:           expandCredentials(d.Get("credentials").(*schema.Set).List()),
+		DefaultServiceComment: d.Get("default_service_comment").(string),
+		DefaultVersionComment: d.Get("default_version_comment").(string),
+		ForceHTTP2:            d.Get("force_http2").(bool),
+		MaxConcurrentRequests: d.Get("max_concurrent_requests").(int),
+		NoAuth:                d.Get("no_auth").(bool),
+		Retry:                 expandRetry(d.Get("retry").([]any)),
+		UserAgent:             userAgent,
+	}
+}
+
+// expandCloneVersionWait converts the `clone_version_wait` provider block into
+// a CloneVersionWaitConfig. Durations are validated by the schema.
+func expandCloneVersionWait(l []any) CloneVersionWaitConfig {
+	var c CloneVersionWaitConfig
+	if len(l) == 0 || l[0] == nil {
+		return c
+	}
+	m := l[0].(map[string]any)
+	c.InitialBackoff, _ = time.ParseDuration(m["initial_backoff"].(string))
+	c.MaxBackoff, _ = time.ParseDuration(m["max_backoff"].(string))
+	c.MaxWait, _ = time.ParseDuration(m["max_wait"].(string))
+	return c
+}
+
+// expandCredentials converts the `credentials` provider blocks into
+// CredentialsConfigs.
+func expandCredentials(l []any) []CredentialsConfig {
+	credentials := make([]CredentialsConfig, 0, len(l))
+	for _, v := range l {
+		m := v.(map[string]any)
+		credentials = append(credentials, CredentialsConfig{
+			Name:    m["name"].(string),
+			APIKey:  m["api_key"].(string),
+			BaseURL: m["base_url"].(string),
+		})
+	}
+	return credentials
+}
+
+// expandRetry converts the `retry` provider block into a RetryConfig.
+// Durations are validated by the schema.
+func expandRetry(l []any) RetryConfig {
+	var c RetryConfig
+	if len(l) == 0 || l[0] == nil {
+		return c
+	}
+	m := l[0].(map[string]any)
+	c.MaxAttempts = m["max_attempts"].(int)
+	c.MaxBackoff, _ = time.ParseDuration(m["max_backoff"].(string))
+	c.MinBackoff, _ = time.ParseDuration(m["min_backoff"].(string))
+	return c
+}
@@ -4,9 +4,18 @@ import (
 	"os"
 	"testing"
 
+	"github.com/dnaeon/go-vcr/recorder"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+
+	gofastly "github.com/fastly/go-fastly/v12/fastly"
 )
 
+// testAccSecondaryAccount is the name of the credentials of the secondary
+// account that the acceptance tests of multi-account support use. Its API
+// key is read from FASTLY_SECONDARY_API_KEY.
+const testAccSecondaryAccount = "secondary"
+
 var (
 	testAccProviders map[string]func() (*schema.Provider, error)
 	testAccProvider  *schema.Provider
@@ -14,6 +23,7 @@ var (
 
 func init() {
 	testAccProvider = Provider()
+	testAccConfigureVCR(testAccProvider)
 	testAccProviders = map[string]func() (*schema.Provider, error){
 		"fastly": func() (*schema.Provider, error) {
 			return testAccProvider, nil
@@ -32,7 +42,49 @@ func TestProvider_impl(_ *testing.T) {
 }
 
 func testAccPreCheck(t *testing.T) {
+	mode := testAccVCRMode()
+	if mode == recorder.ModeReplaying && os.Getenv("FASTLY_API_KEY") == "" {
+		// Replayed tests don't reach the API, but the provider requires a key.
+		if err := os.Setenv("FASTLY_API_KEY", vcrReplayAPIKey); err != nil {
+			t.Fatal(err)
+		}
+	}
 	if v := os.Getenv("FASTLY_API_KEY"); v == "" {
 		t.Fatal("FASTLY_API_KEY must be set for acceptance tests")
 	}
+	if mode != recorder.ModeDisabled {
+		testAccStartVCR(t, mode)
+	}
+}
+
+// testAccPreCheckSecondaryAccount skips tests that need a secondary account
+// when FASTLY_SECONDARY_API_KEY isn't set.
+func testAccPreCheckSecondaryAccount(t *testing.T) {
+	if os.Getenv("FASTLY_SECONDARY_API_KEY") == "" {
+		t.Skip("FASTLY_SECONDARY_API_KEY must be set for multi-account acceptance tests")
+	}
+}
+
+// testAccSecondaryCredentials returns the credentials of the secondary
+// account, if FASTLY_SECONDARY_API_KEY is set.
+func testAccSecondaryCredentials() []CredentialsConfig {
+	apiKey := os.Getenv("FASTLY_SECONDARY_API_KEY")
+	if apiKey == "" {
+		return nil
+	}
+	return []CredentialsConfig{{
+		Name:    testAccSecondaryAccount,
+		APIKey:  apiKey,
+		BaseURL: os.Getenv("FASTLY_SECONDARY_API_URL"),
+	}}
+}
+
+// testAccAccountConn returns the client of the account a resource was
+// created in.
+func testAccAccountConn(rs *terraform.ResourceState) (*gofastly.Client, error) {
+	client, err := testAccProvider.Meta().(*APIClient).forAccount(rs.Primary.Attributes["account"])
+	if err != nil {
+		return nil, err
+	}
+	return client.conn, nil
 }
@@ -0,0 +1,388 @@
+package fastly
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	gofastly "github.com/fastly/go-fastly/v12/fastly"
+)
+
+// kvStoreBatchSize is the maximum number of keys sent in a single request to
+// the KV Store batch endpoint.
+const kvStoreBatchSize = 1000
+
+func resourceFastlyKVStoreEntries() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceFastlyKVStoreEntriesCreate,
+		ReadContext:   resourceFastlyKVStoreEntriesRead,
+		UpdateContext: resourceFastlyKVStoreEntriesUpdate,
+		DeleteContext: resourceFastlyKVStoreEntriesDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceKVStoreEntriesImport,
+		},
+		Schema: map[string]*schema.Schema{
+			"entries": {
+				Type:        schema.TypeMap,
+				Required:    true,
+				Description: "A map representing an entry in the KV Store, (key/value)",
+				Elem:        schema.TypeString,
+				DiffSuppressFunc: func(_, _, _ string, d *schema.ResourceData) bool {
+					// Suppress the diff unless the user wishes Terraform to manage the entries.
+					return !d.HasChange("store_id") && !d.Get("manage_entries").(bool)
+				},
+			},
+			"manage_entries": {
+				Type:        schema.TypeBool,
+				Default:     false,
+				Optional:    true,
+				Description: "Have Terraform manage the entries (default: false). If set to `true` Terraform will remove any entries that were added externally from the config seeded values.",
+			},
+			"metadata": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Description: "A map of metadata to store alongside an entry in the KV Store, (key/metadata). Each key must also be present in `entries`.",
+				Elem:        schema.TypeString,
+				DiffSuppressFunc: func(_, _, _ string, d *schema.ResourceData) bool {
+					// Suppress the diff unless the user wishes Terraform to manage the entries.
+					return !d.HasChange("store_id") && !d.Get("manage_entries").(bool)
+				},
+			},
+			"store_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "An alphanumeric string identifying the KV Store.",
+			},
+		},
+	}
+}
+
+func resourceFastlyKVStoreEntriesCreate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	conn := meta.(*APIClient).conn
+
+	entries := d.Get("entries").(map[string]any)
+	metadata := d.Get("metadata").(map[string]any)
+	storeID := d.Get("store_id").(string)
+
+	if err := validateKVStoreEntriesMetadata(entries, metadata); err != nil {
+		return diag.FromErr(err)
+	}
+
+	var batchEntries []kvStoreBatchItem
+
+	for key, val := range entries {
+		batchEntries = append(batchEntries, newKVStoreBatchItem(key, val.(string), metadata))
+	}
+
+	log.Printf("[DEBUG] CREATE: KV Store Entries")
+
+	err := executeBatchKVStoreOperations(ctx, conn, storeID, batchEntries)
+	if err != nil {
+		return diag.Errorf("error creating KV Store (%s) entries: %s", storeID, err)
+	}
+
+	// NOTE: `id` is exposed as a read-only attribute.
+	d.SetId(fmt.Sprintf("%s/entries", storeID))
+
+	return nil
+}
+
+func resourceFastlyKVStoreEntriesRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	conn := meta.(*APIClient).conn
+
+	log.Printf("[DEBUG] REFRESH: KV Store Entries")
+
+	storeID := d.Get("store_id").(string)
+	entries := d.Get("entries").(map[string]any)
+
+	keys, err := listKVStoreKeys(ctx, conn, storeID)
+	if err != nil {
+		if e, ok := err.(*gofastly.HTTPError); ok && e.IsNotFound() {
+			log.Printf("[WARN] No KV Store found '%s'", storeID)
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+
+	// NOTE: Fetching the value of every key in a store can be expensive.
+	// Unless Terraform is managing the store contents (or the resource is being
+	// imported) we only refresh the keys that are already tracked in state.
+	readAll := d.Get("manage_entries").(bool) || len(entries) == 0
+
+	var items []*kvStoreItem
+
+	for _, key := range keys {
+		if _, ok := entries[key]; !ok && !readAll {
+			continue
+		}
+
+		item, err := conn.GetKVStoreItem(ctx, &gofastly.GetKVStoreItemInput{
+			StoreID: storeID,
+			Key:     key,
+		})
+		if err != nil {
+			if e, ok := err.(*gofastly.HTTPError); ok && e.IsNotFound() {
+				// The key was deleted between listing and fetching it.
+				continue
+			}
+			return diag.Errorf("error reading KV Store (%s) key (%s): %s", storeID, key, err)
+		}
+
+		value, err := item.ValueAsString()
+		if err != nil {
+			return diag.Errorf("error reading KV Store (%s) key (%s): %s", storeID, key, err)
+		}
+
+		items = append(items, &kvStoreItem{
+			Key:      key,
+			Value:    value,
+			Metadata: item.Metadata,
+		})
+	}
+
+	values, metadata := flattenKVStoreEntries(items)
+
+	err = d.Set("entries", values)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("metadata", metadata)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+func resourceFastlyKVStoreEntriesUpdate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	conn := meta.(*APIClient).conn
+
+	storeID := d.Get("store_id").(string)
+
+	log.Printf("[DEBUG] UPDATE: KV Store Entries")
+
+	if d.HasChanges("entries", "metadata") {
+		o, n := d.GetChange("entries")
+		om := o.(map[string]any)
+		nm := n.(map[string]any)
+
+		omd, nmd := d.GetChange("metadata")
+		oldMetadata := omd.(map[string]any)
+		newMetadata := nmd.(map[string]any)
+
+		if err := validateKVStoreEntriesMetadata(nm, newMetadata); err != nil {
+			return diag.FromErr(err)
+		}
+
+		// Deletions
+		for key := range om {
+			if _, ok := nm[key]; !ok {
+				err := conn.DeleteKVStoreKey(ctx, &gofastly.DeleteKVStoreKeyInput{
+					StoreID: storeID,
+					Key:     key,
+					Force:   true,
+				})
+				if err != nil {
+					return diag.Errorf("error deleting KV Store (%s) key (%s): %s", storeID, key, err)
+				}
+			}
+		}
+
+		// Additions and updates
+		var batchEntries []kvStoreBatchItem
+
+		for key, val := range nm {
+			if ov, ok := om[key]; ok && ov == val && oldMetadata[key] == newMetadata[key] {
+				continue
+			}
+			batchEntries = append(batchEntries, newKVStoreBatchItem(key, val.(string), newMetadata))
+		}
+
+		err := executeBatchKVStoreOperations(ctx, conn, storeID, batchEntries)
+		if err != nil {
+			return diag.Errorf("error updating KV Store (%s) entries: %s", storeID, err)
+		}
+	}
+
+	return nil
+}
+
+func resourceFastlyKVStoreEntriesDelete(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	conn := meta.(*APIClient).conn
+
+	entries := d.Get("entries").(map[string]any)
+	storeID := d.Get("store_id").(string)
+
+	log.Printf("[DEBUG] DELETE: KV Store Entries")
+
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		err := conn.DeleteKVStoreKey(ctx, &gofastly.DeleteKVStoreKeyInput{
+			StoreID: storeID,
+			Key:     key,
+			Force:   true,
+		})
+		if err != nil {
+			if e, ok := err.(*gofastly.HTTPError); ok && e.IsNotFound() {
+				continue
+			}
+			return diag.Errorf("error deleting KV Store (%s) entries: %s", storeID, err)
+		}
+	}
+
+	d.SetId("")
+
+	return nil
+}
+
+// kvStoreItem represents a single KV Store key along with its value and
+// metadata.
+type kvStoreItem struct {
+	Key      string
+	Value    string
+	Metadata string
+}
+
+// kvStoreBatchItem is a single line of the newline-delimited JSON body
+// accepted by the KV Store batch endpoint.
+type kvStoreBatchItem struct {
+	Key      string `json:"key"`
+	Value    string `json:"value"`
+	Metadata string `json:"metadata,omitempty"`
+}
+
+// newKVStoreBatchItem builds a batch item for the given key, base64 encoding
+// its value as required by the API.
+func newKVStoreBatchItem(key, value string, metadata map[string]any) kvStoreBatchItem {
+	item := kvStoreBatchItem{
+		Key:   key,
+		Value: base64.StdEncoding.EncodeToString([]byte(value)),
+	}
+	if v, ok := metadata[key]; ok {
+		item.Metadata = v.(string)
+	}
+	return item
+}
+
+// validateKVStoreEntriesMetadata ensures metadata is only declared for keys
+// that are also declared in entries.
+func validateKVStoreEntriesMetadata(entries, metadata map[string]any) error {
+	var missing []string
+	for key := range metadata {
+		if _, ok := entries[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("metadata defined for keys not present in entries: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// flattenKVStoreEntries models data into format suitable for saving to
+// Terraform state.
+func flattenKVStoreEntries(remoteState []*kvStoreItem) (map[string]string, map[string]string) {
+	values := make(map[string]string)
+	metadata := make(map[string]string)
+	for _, currentEntry := range remoteState {
+		values[currentEntry.Key] = currentEntry.Value
+		if currentEntry.Metadata != "" {
+			metadata[currentEntry.Key] = currentEntry.Metadata
+		}
+	}
+	return values, metadata
+}
+
+// buildKVStoreBatchBody encodes batch items as newline-delimited JSON.
+func buildKVStoreBatchBody(batchEntries []kvStoreBatchItem) (*bytes.Buffer, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, item := range batchEntries {
+		if err := enc.Encode(item); err != nil {
+			return nil, err
+		}
+	}
+	return &buf, nil
+}
+
+// executeBatchKVStoreOperations is called from with the Create and Update
+// methods.
+func executeBatchKVStoreOperations(ctx context.Context, conn *gofastly.Client, storeID string, batchEntries []kvStoreBatchItem) error {
+	// Sort the entries so the requests are deterministic.
+	sort.Slice(batchEntries, func(i, j int) bool {
+		return batchEntries[i].Key < batchEntries[j].Key
+	})
+
+	for i := 0; i < len(batchEntries); i += kvStoreBatchSize {
+		j := i + kvStoreBatchSize
+		if j > len(batchEntries) {
+			j = len(batchEntries)
+		}
+
+		body, err := buildKVStoreBatchBody(batchEntries[i:j])
+		if err != nil {
+			return err
+		}
+
+		err = conn.BatchModifyKVStoreKey(ctx, &gofastly.BatchModifyKVStoreKeyInput{
+			StoreID: storeID,
+			Body:    body,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// listKVStoreKeys returns every key in the KV Store, following pagination.
+func listKVStoreKeys(ctx context.Context, conn *gofastly.Client, storeID string) ([]string, error) {
+	var keys []string
+
+	p := conn.NewListKVStoreKeysPaginator(ctx, &gofastly.ListKVStoreKeysInput{
+		StoreID: storeID,
+	})
+	for p.Next() {
+		keys = append(keys, p.Keys()...)
+	}
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func resourceKVStoreEntriesImport(_ context.Context, d *schema.ResourceData, _ any) ([]*schema.ResourceData, error) {
+	split := strings.Split(d.Id(), "/")
+
+	if len(split) != 2 {
+		return nil, fmt.Errorf("invalid id: %s. The ID should be in the format [store_id]/entries", d.Id())
+	}
+
+	storeID := split[0]
+
+	err := d.Set("store_id", storeID)
+	if err != nil {
+		return nil, fmt.Errorf("error setting KV Store ID (%s): %s", storeID, err)
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
@@ -0,0 +1,286 @@
+package fastly
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+
+	gofastly "github.com/fastly/go-fastly/v12/fastly"
+)
+
+func TestResourceFastlyFlattenKVStoreEntries(t *testing.T) {
+	cases := []struct {
+		remote   []*kvStoreItem
+		values   map[string]string
+		metadata map[string]string
+	}{
+		{
+			remote: []*kvStoreItem{
+				{
+					Key:   "key-1",
+					Value: "value-1",
+				},
+				{
+					Key:      "key-2",
+					Value:    "value-2",
+					Metadata: "meta-2",
+				},
+			},
+			values: map[string]string{
+				"key-1": "value-1",
+				"key-2": "value-2",
+			},
+			metadata: map[string]string{
+				"key-2": "meta-2",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		values, metadata := flattenKVStoreEntries(c.remote)
+		if !reflect.DeepEqual(values, c.values) {
+			t.Fatalf("Error matching:\nexpected: %#v\ngot: %#v", c.values, values)
+		}
+		if !reflect.DeepEqual(metadata, c.metadata) {
+			t.Fatalf("Error matching:\nexpected: %#v\ngot: %#v", c.metadata, metadata)
+		}
+	}
+}
+
+func TestResourceFastlyBuildKVStoreBatchBody(t *testing.T) {
+	metadata := map[string]any{
+		"key-2": "meta-2",
+	}
+	items := []kvStoreBatchItem{
+		newKVStoreBatchItem("key-1", "value-1", metadata),
+		newKVStoreBatchItem("key-2", "value-2", metadata),
+	}
+
+	body, err := buildKVStoreBatchBody(items)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	want := `{"key":"key-1","value":"dmFsdWUtMQ=="}
+{"key":"key-2","value":"dmFsdWUtMg==","metadata":"meta-2"}
+`
+	if got := body.String(); got != want {
+		t.Fatalf("Error matching:\nexpected: %q\ngot: %q", want, got)
+	}
+}
+
+func TestResourceFastlyValidateKVStoreEntriesMetadata(t *testing.T) {
+	entries := map[string]any{
+		"key-1": "value-1",
+	}
+
+	if err := validateKVStoreEntriesMetadata(entries, map[string]any{"key-1": "meta"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := validateKVStoreEntriesMetadata(entries, map[string]any{"key-2": "meta"}); err == nil {
+		t.Fatal("expected an error for metadata without a matching entry")
+	}
+}
+
+func TestAccFastlyKVStoreEntries_validate(t *testing.T) {
+	storeName := fmt.Sprintf("store_%s", acctest.RandString(10))
+
+	want1 := map[string]string{
+		"key1": "value1",
+		"key2": "value2",
+	}
+
+	want2 := map[string]string{
+		"key1": "value1_updated",
+		"key3": "value3",
+	}
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      testAccCheckFastlyKVStoreDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccServiceKVStoreEntries(storeName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckFastlyServiceKVStoreEntriesRemoteState(storeName, want1),
+					testAccCheckFastlyServiceKVStoreEntriesRemoteMetadata(storeName, map[string]string{"key1": "meta1"}),
+					resource.TestCheckResourceAttr("fastly_kvstore_entries.example", "entries.%", "2"),
+					resource.TestCheckResourceAttr("fastly_kvstore_entries.example", "metadata.key1", "meta1"),
+				),
+			},
+			{
+				Config: testAccServiceKVStoreEntriesUpdate(storeName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckFastlyServiceKVStoreEntriesRemoteState(storeName, want2),
+					testAccCheckFastlyServiceKVStoreEntriesRemoteMetadata(storeName, map[string]string{}),
+					resource.TestCheckResourceAttr("fastly_kvstore_entries.example", "entries.%", "2"),
+					resource.TestCheckResourceAttr("fastly_kvstore_entries.example", "metadata.%", "0"),
+				),
+			},
+			{
+				ResourceName:            "fastly_kvstore_entries.example",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"manage_entries"},
+			},
+		},
+	})
+}
+
+func testAccServiceKVStoreEntries(storeName string) string {
+	return fmt.Sprintf(`
+resource "fastly_kvstore" "example" {
+  name          = "%s"
+  force_destroy = true
+}
+
+resource "fastly_kvstore_entries" "example" {
+  store_id = fastly_kvstore.example.id
+  entries = {
+    key1: "value1"
+    key2: "value2"
+  }
+  metadata = {
+    key1: "meta1"
+  }
+  manage_entries = true
+}
+`, storeName)
+}
+
+func testAccServiceKVStoreEntriesUpdate(storeName string) string {
+	return fmt.Sprintf(`
+resource "fastly_kvstore" "example" {
+  name          = "%s"
+  force_destroy = true
+}
+
+resource "fastly_kvstore_entries" "example" {
+  store_id = fastly_kvstore.example.id
+  entries = {
+    key1: "value1_updated"
+    key3: "value3"
+  }
+  manage_entries = true
+}
+`, storeName)
+}
+
+func testAccCheckFastlyServiceKVStoreEntriesRemoteState(storeName string, want map[string]string) resource.TestCheckFunc {
+	return func(_ *terraform.State) error {
+		conn := testAccProvider.Meta().(*APIClient).conn
+
+		storeID, err := testAccFindKVStoreID(conn, storeName)
+		if err != nil {
+			return err
+		}
+
+		keys, err := listKVStoreKeys(context.TODO(), conn, storeID)
+		if err != nil {
+			return fmt.Errorf("failed to get KV Store keys: %w", err)
+		}
+
+		got := make(map[string]string)
+		for _, key := range keys {
+			value, err := conn.GetKVStoreKey(context.TODO(), &gofastly.GetKVStoreKeyInput{
+				StoreID: storeID,
+				Key:     key,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to get KV Store key (%s): %w", key, err)
+			}
+			got[key] = value
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			return fmt.Errorf("error matching:\nexpected: %#v\ngot: %#v", want, got)
+		}
+
+		return nil
+	}
+}
+
+// testAccCheckFastlyServiceKVStoreEntriesRemoteMetadata checks the metadata
+// of every key in the KV Store, so that metadata removed from the
+// configuration is verified to be cleared remotely.
+func testAccCheckFastlyServiceKVStoreEntriesRemoteMetadata(storeName string, want map[string]string) resource.TestCheckFunc {
+	return func(_ *terraform.State) error {
+		conn := testAccProvider.Meta().(*APIClient).conn
+
+		storeID, err := testAccFindKVStoreID(conn, storeName)
+		if err != nil {
+			return err
+		}
+
+		keys, err := listKVStoreKeys(context.TODO(), conn, storeID)
+		if err != nil {
+			return fmt.Errorf("failed to get KV Store keys: %w", err)
+		}
+
+		got := make(map[string]string)
+		for _, key := range keys {
+			item, err := conn.GetKVStoreItem(context.TODO(), &gofastly.GetKVStoreItemInput{
+				StoreID: storeID,
+				Key:     key,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to get KV Store key (%s): %w", key, err)
+			}
+			if item.Metadata != "" {
+				got[key] = item.Metadata
+			}
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			return fmt.Errorf("error matching metadata:\nexpected: %#v\ngot: %#v", want, got)
+		}
+
+		return nil
+	}
+}
+
+func testAccFindKVStoreID(conn *gofastly.Client, storeName string) (string, error) {
+	p := conn.NewListKVStoresPaginator(context.TODO(), &gofastly.ListKVStoresInput{})
+	for p.Next() {
+		for _, store := range p.Stores() {
+			if store.Name == storeName {
+				return store.StoreID, nil
+			}
+		}
+	}
+	if err := p.Err(); err != nil {
+		return "", fmt.Errorf("failed to get list of KV Stores: %w", err)
+	}
+
+	return "", fmt.Errorf("failed to find KV Store")
+}
+
+func testAccCheckFastlyKVStoreDestroy(s *terraform.State) error {
+	conn := testAccProvider.Meta().(*APIClient).conn
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "fastly_kvstore" {
+			continue
+		}
+
+		_, err := conn.GetKVStore(context.TODO(), &gofastly.GetKVStoreInput{
+			StoreID: rs.Primary.ID,
+		})
+		if err == nil {
+			return fmt.Errorf("tried deleting KV Store (%s), but it still exists", rs.Primary.ID)
+		}
+		if e, ok := err.(*gofastly.HTTPError); !ok || !e.IsNotFound() {
+			return fmt.Errorf("error checking KV Store (%s) was deleted: %w", rs.Primary.ID, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,340 @@
+package fastly
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	gofastly "github.com/fastly/go-fastly/v12/fastly"
+)
+
+const (
+	secretStoreSecretMethodCreate              = "create"
+	secretStoreSecretMethodRecreate            = "recreate"
+	secretStoreSecretMethodCreateOrRecreate    = "create_or_recreate"
+	secretStoreSecretWriteOnlyAttribute        = "secret_wo"
+	secretStoreSecretWriteOnlyVersionAttribute = "secret_wo_version"
+)
+
+// secretStoreSecretMethods maps the supported `method` values to the HTTP
+// method expected by the Secret Store API.
+var secretStoreSecretMethods = map[string]string{
+	secretStoreSecretMethodCreate:           http.MethodPost,
+	secretStoreSecretMethodRecreate:         http.MethodPatch,
+	secretStoreSecretMethodCreateOrRecreate: http.MethodPut,
+}
+
+func resourceFastlySecretStoreSecret() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceFastlySecretStoreSecretCreate,
+		ReadContext:   resourceFastlySecretStoreSecretRead,
+		UpdateContext: resourceFastlySecretStoreSecretUpdate,
+		DeleteContext: resourceFastlySecretStoreSecretDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceFastlySecretStoreSecretImport,
+		},
+		Schema: map[string]*schema.Schema{
+			"created_at": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Timestamp (GMT) when the secret was created.",
+			},
+			"digest": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "A hex-encoded, opaque hash of the secret as reported by the Fastly API. Used to detect changes made outside of Terraform.",
+			},
+			"method": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     secretStoreSecretMethodCreate,
+				ForceNew:    true,
+				Description: "How an existing secret with the same name is handled when the resource is created. `create` fails if the secret already exists, `recreate` fails if the secret does not already exist, and `create_or_recreate` creates or replaces the secret. Defaults to `create`. As it only applies when the resource is created, changing it replaces the resource.",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice(
+					[]string{secretStoreSecretMethodCreate, secretStoreSecretMethodRecreate, secretStoreSecretMethodCreateOrRecreate},
+					false,
+				)),
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The name of the secret. Secret names must be unique within a store.",
+			},
+			"recreated": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the last write replaced an existing secret.",
+			},
+			"secret": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Sensitive:    true,
+				ExactlyOneOf: []string{"secret", secretStoreSecretWriteOnlyAttribute},
+				Description:  "The plaintext secret. It is encrypted locally before being sent to the Fastly API and is never read back. The value is stored in Terraform state; use `secret_wo` to avoid this.",
+			},
+			secretStoreSecretWriteOnlyAttribute: {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Sensitive:    true,
+				WriteOnly:    true,
+				RequiredWith: []string{secretStoreSecretWriteOnlyVersionAttribute},
+				Description:  "The plaintext secret as a write-only argument (requires Terraform 1.11 or later). The value is never stored in Terraform state. Change `secret_wo_version` to upload a new value.",
+			},
+			secretStoreSecretWriteOnlyVersionAttribute: {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				RequiredWith: []string{secretStoreSecretWriteOnlyAttribute},
+				Description:  "An arbitrary number that must be changed to upload a new value for `secret_wo`.",
+			},
+			"store_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "An alphanumeric string identifying the Secret Store.",
+			},
+		},
+	}
+}
+
+func resourceFastlySecretStoreSecretCreate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	method := secretStoreSecretMethods[d.Get("method").(string)]
+
+	diags := putSecretStoreSecret(ctx, d, meta, method)
+	if diags.HasError() {
+		return diags
+	}
+
+	d.SetId(fmt.Sprintf("%s/%s", d.Get("store_id").(string), d.Get("name").(string)))
+
+	return diags
+}
+
+func resourceFastlySecretStoreSecretRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	conn := meta.(*APIClient).conn
+
+	storeID, name, err := parseSecretStoreSecretID(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	input := &gofastly.GetSecretInput{
+		StoreID: storeID,
+		Name:    name,
+	}
+
+	log.Printf("[DEBUG] REFRESH: Secret Store Secret input: %#v", input)
+
+	secret, err := conn.GetSecret(ctx, input)
+	if err != nil {
+		if e, ok := err.(*gofastly.HTTPError); ok && e.IsNotFound() {
+			log.Printf("[WARN] No Secret Store Secret found '%s'", d.Id())
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+
+	var diags diag.Diagnostics
+
+	digest := hex.EncodeToString(secret.Digest)
+	if prior := d.Get("digest").(string); prior != "" && prior != digest {
+		log.Printf("[WARN] Secret Store Secret '%s' was modified outside of Terraform", d.Id())
+		if _, ok := d.GetOk("secret"); ok {
+			// NOTE: The plaintext is never read back from the API, so the only way
+			// to surface the drift is to clear the value and let Terraform plan a
+			// new upload of the configured secret.
+			if err := d.Set("secret", ""); err != nil {
+				return diag.FromErr(err)
+			}
+		} else {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  "Secret modified outside of Terraform",
+				Detail:   fmt.Sprintf("The digest of secret %q in Secret Store %q no longer matches the value uploaded by Terraform. Change `secret_wo_version` to upload the configured value again.", name, storeID),
+			})
+		}
+	}
+
+	if err := d.Set("store_id", storeID); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("name", secret.Name); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("digest", digest); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("created_at", secret.CreatedAt.Format(time.RFC3339)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
+
+func resourceFastlySecretStoreSecretUpdate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	if d.HasChanges("secret", secretStoreSecretWriteOnlyVersionAttribute) {
+		return putSecretStoreSecret(ctx, d, meta, http.MethodPatch)
+	}
+	return nil
+}
+
+func resourceFastlySecretStoreSecretDelete(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	conn := meta.(*APIClient).conn
+
+	input := &gofastly.DeleteSecretInput{
+		StoreID: d.Get("store_id").(string),
+		Name:    d.Get("name").(string),
+	}
+
+	log.Printf("[DEBUG] DELETE: Secret Store Secret input: %#v", input)
+
+	err := conn.DeleteSecret(ctx, input)
+	if err != nil {
+		if e, ok := err.(*gofastly.HTTPError); ok && e.IsNotFound() {
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+// putSecretStoreSecret encrypts the configured secret with a freshly issued
+// client key and uploads it using the given HTTP method.
+func putSecretStoreSecret(ctx context.Context, d *schema.ResourceData, meta any, method string) diag.Diagnostics {
+	conn := meta.(*APIClient).conn
+
+	plaintext, diags := getSecretStoreSecretPlaintext(d)
+	if diags.HasError() {
+		return diags
+	}
+
+	signingKey, err := conn.GetSigningKey(ctx)
+	if err != nil {
+		return diag.Errorf("error fetching Secret Store signing key: %s", err)
+	}
+
+	clientKey, err := conn.CreateClientKey(ctx)
+	if err != nil {
+		return diag.Errorf("error creating Secret Store client key: %s", err)
+	}
+
+	ciphertext, err := encryptSecretStoreSecret(clientKey, signingKey, plaintext)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	input := &gofastly.CreateSecretInput{
+		ClientKey: clientKey.PublicKey,
+		Method:    method,
+		Name:      d.Get("name").(string),
+		Secret:    ciphertext,
+		StoreID:   d.Get("store_id").(string),
+	}
+
+	// NOTE: The input is not logged as it contains the (encrypted) secret.
+	log.Printf("[DEBUG] WRITE: Secret Store Secret %s/%s (method: %s)", input.StoreID, input.Name, method)
+
+	secret, err := conn.CreateSecret(ctx, input)
+	if err != nil {
+		return diag.Errorf("error writing secret (%s) to Secret Store (%s): %s", input.Name, input.StoreID, err)
+	}
+
+	if err := d.Set("digest", hex.EncodeToString(secret.Digest)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("recreated", secret.Recreated); err != nil {
+		return diag.FromErr(err)
+	}
+	if !secret.CreatedAt.IsZero() {
+		if err := d.Set("created_at", secret.CreatedAt.Format(time.RFC3339)); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	return diags
+}
+
+// getSecretStoreSecretPlaintext returns the plaintext secret from either the
+// `secret` attribute or the write-only `secret_wo` attribute.
+func getSecretStoreSecretPlaintext(d *schema.ResourceData) ([]byte, diag.Diagnostics) {
+	if v, ok := d.GetOk("secret"); ok {
+		return []byte(v.(string)), nil
+	}
+
+	v, diags := d.GetRawConfigAt(cty.GetAttrPath(secretStoreSecretWriteOnlyAttribute))
+	if diags.HasError() {
+		return nil, diags
+	}
+	if v.IsNull() || !v.IsKnown() || !v.Type().Equals(cty.String) || v.AsString() == "" {
+		return nil, diag.Errorf("one of `secret` or `%s` must be set to a non-empty value", secretStoreSecretWriteOnlyAttribute)
+	}
+
+	return []byte(v.AsString()), diags
+}
+
+// encryptSecretStoreSecret verifies the client key was signed by the Secret
+// Store signing key and then uses it to seal the plaintext.
+func encryptSecretStoreSecret(clientKey *gofastly.ClientKey, signingKey ed25519.PublicKey, plaintext []byte) ([]byte, error) {
+	if len(signingKey) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid Secret Store signing key length %d", len(signingKey))
+	}
+	if !clientKey.VerifySignature(signingKey) {
+		return nil, errors.New("unable to verify the Secret Store client key signature")
+	}
+	if !clientKey.ExpiresAt.IsZero() && time.Now().After(clientKey.ExpiresAt) {
+		return nil, fmt.Errorf("the Secret Store client key expired at %s", clientKey.ExpiresAt.Format(time.RFC3339))
+	}
+
+	ciphertext, err := clientKey.Encrypt(plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("error encrypting secret: %w", err)
+	}
+
+	return ciphertext, nil
+}
+
+// parseSecretStoreSecretID splits an ID in the format [store_id]/[name].
+func parseSecretStoreSecretID(id string) (storeID, name string, err error) {
+	split := strings.SplitN(id, "/", 2)
+
+	if len(split) != 2 || split[0] == "" || split[1] == "" {
+		return "", "", fmt.Errorf("invalid id: %s. The ID should be in the format [store_id]/[name]", id)
+	}
+
+	return split[0], split[1], nil
+}
+
+func resourceFastlySecretStoreSecretImport(_ context.Context, d *schema.ResourceData, _ any) ([]*schema.ResourceData, error) {
+	storeID, name, err := parseSecretStoreSecretID(d.Id())
+	if err != nil {
+		return nil, err
+	}
+
+	err = d.Set("store_id", storeID)
+	if err != nil {
+		return nil, fmt.Errorf("error setting Secret Store ID (%s): %s", storeID, err)
+	}
+	err = d.Set("name", name)
+	if err != nil {
+		return nil, fmt.Errorf("error setting Secret Store Secret name (%s): %s", name, err)
+	}
+	err = d.Set("method", secretStoreSecretMethodCreate)
+	if err != nil {
+		return nil, fmt.Errorf("error setting Secret Store Secret method: %s", err)
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
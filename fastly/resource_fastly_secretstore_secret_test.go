@@ -0,0 +1,170 @@
+package fastly
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"golang.org/x/crypto/nacl/box"
+
+	gofastly "github.com/fastly/go-fastly/v12/fastly"
+)
+
+func TestResourceFastlyEncryptSecretStoreSecret(t *testing.T) {
+	signingPublic, signingPrivate, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	boxPublic, boxPrivate, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clientKey := &gofastly.ClientKey{
+		PublicKey: boxPublic[:],
+		Signature: ed25519.Sign(signingPrivate, boxPublic[:]),
+		ExpiresAt: time.Now().Add(time.Minute),
+	}
+
+	ciphertext, err := encryptSecretStoreSecret(clientKey, signingPublic, []byte("my secret"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	plaintext, ok := box.OpenAnonymous(nil, ciphertext, boxPublic, boxPrivate)
+	if !ok {
+		t.Fatal("unable to decrypt secret")
+	}
+	if string(plaintext) != "my secret" {
+		t.Fatalf("Error matching:\nexpected: %q\ngot: %q", "my secret", plaintext)
+	}
+
+	otherPublic, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := encryptSecretStoreSecret(clientKey, otherPublic, []byte("my secret")); err == nil {
+		t.Fatal("expected an error for a client key signed by a different signing key")
+	}
+	if _, err := encryptSecretStoreSecret(clientKey, signingPublic[:8], []byte("my secret")); err == nil {
+		t.Fatal("expected an error for an invalid signing key")
+	}
+
+	clientKey.ExpiresAt = time.Now().Add(-time.Minute)
+	if _, err := encryptSecretStoreSecret(clientKey, signingPublic, []byte("my secret")); err == nil {
+		t.Fatal("expected an error for an expired client key")
+	}
+}
+
+func TestResourceFastlyParseSecretStoreSecretID(t *testing.T) {
+	storeID, name, err := parseSecretStoreSecretID("store/my/secret")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if storeID != "store" || name != "my/secret" {
+		t.Fatalf("unexpected result: %q, %q", storeID, name)
+	}
+
+	for _, id := range []string{"", "store", "store/", "/secret"} {
+		if _, _, err := parseSecretStoreSecretID(id); err == nil {
+			t.Fatalf("expected an error for id %q", id)
+		}
+	}
+}
+
+func TestAccFastlySecretStoreSecret_validate(t *testing.T) {
+	storeName := fmt.Sprintf("tf-test-secret-store-%s", acctest.RandString(10))
+	secretName := fmt.Sprintf("tf-test-secret-%s", acctest.RandString(10))
+
+	var digest string
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      testAccCheckServiceVCLDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSecretStoreSecretConfig(storeName, secretName, "value1"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckFastlySecretStoreSecretDigest("fastly_secretstore_secret.example", &digest),
+					resource.TestCheckResourceAttrSet("fastly_secretstore_secret.example", "digest"),
+					resource.TestCheckResourceAttrSet("fastly_secretstore_secret.example", "created_at"),
+				),
+			},
+			{
+				Config: testAccSecretStoreSecretConfig(storeName, secretName, "value2"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckFastlySecretStoreSecretDigestChanged("fastly_secretstore_secret.example", &digest),
+					resource.TestCheckResourceAttr("fastly_secretstore_secret.example", "recreated", "true"),
+				),
+			},
+			{
+				ResourceName:      "fastly_secretstore_secret.example",
+				ImportState:       true,
+				ImportStateVerify: true,
+				// The plaintext is never read back from the Fastly API.
+				ImportStateVerifyIgnore: []string{"secret", "recreated"},
+			},
+		},
+	})
+}
+
+func testAccCheckFastlySecretStoreSecretDigest(name string, digest *string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("not found: %s", name)
+		}
+
+		conn := testAccProvider.Meta().(*APIClient).conn
+		secret, err := conn.GetSecret(context.TODO(), &gofastly.GetSecretInput{
+			StoreID: rs.Primary.Attributes["store_id"],
+			Name:    rs.Primary.Attributes["name"],
+		})
+		if err != nil {
+			return fmt.Errorf("error looking up secret: %s", err)
+		}
+
+		*digest = hex.EncodeToString(secret.Digest)
+		if got := rs.Primary.Attributes["digest"]; got != *digest {
+			return fmt.Errorf("bad digest, expected (%s), got (%s)", *digest, got)
+		}
+
+		return nil
+	}
+}
+
+func testAccCheckFastlySecretStoreSecretDigestChanged(name string, digest *string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		previous := *digest
+		if err := testAccCheckFastlySecretStoreSecretDigest(name, digest)(s); err != nil {
+			return err
+		}
+		if previous == *digest {
+			return fmt.Errorf("expected digest to change after updating the secret")
+		}
+		return nil
+	}
+}
+
+func testAccSecretStoreSecretConfig(storeName, secretName, value string) string {
+	return fmt.Sprintf(`
+resource "fastly_secretstore" "example" {
+  name = "%s"
+}
+
+resource "fastly_secretstore_secret" "example" {
+  store_id = fastly_secretstore.example.id
+  name     = "%s"
+  secret   = "%s"
+}
+`, storeName, secretName, value)
+}
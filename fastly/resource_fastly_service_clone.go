@@ -0,0 +1,501 @@
+package fastly
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	gofastly "github.com/fastly/go-fastly/v12/fastly"
+)
+
+func resourceServiceClone() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceServiceCloneCreate,
+		ReadContext:   resourceServiceCloneRead,
+		UpdateContext: resourceServiceCloneUpdate,
+		DeleteContext: resourceServiceCloneDelete,
+		Schema: map[string]*schema.Schema{
+			"activate": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				ForceNew:    true,
+				Description: "Conditionally prevents the new service from being activated. Default `true`",
+			},
+			"active_version": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The currently active version of the new service",
+			},
+			"backend_addresses": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "A map of backend names to the address the backend of the new service should use instead of the address of the source service's backend",
+			},
+			"cloned_version": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The version of the new service that the configuration was copied to",
+			},
+			"comment": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     ManagedByTerraform,
+				Description: "Description field for the new service. Default `Managed by Terraform`",
+			},
+			"dictionary_items": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "Items to add to (or replace in) a dictionary of the new service, on top of the items copied from the source service",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"items": {
+							Type:             schema.TypeMap,
+							Required:         true,
+							Elem:             &schema.Schema{Type: schema.TypeString},
+							Description:      "A map of dictionary item keys to values",
+							ValidateDiagFunc: validateDictionaryItems(),
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The name of the dictionary",
+						},
+					},
+				},
+			},
+			"domains": {
+				Type:        schema.TypeSet,
+				Required:    true,
+				ForceNew:    true,
+				MinItems:    1,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The domain names of the new service, which replace the domains of the source service (a domain can only belong to one service)",
+			},
+			"force_destroy": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Services that are active cannot be destroyed. In order to destroy the service, set `force_destroy` to `true`. Default `false`",
+			},
+			"logging_endpoint_names": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "A map of logging endpoint names to the name the endpoint should have in the new service. Applies to every `logging_*` block of the source service. Note that custom VCL referencing an endpoint by name is not updated",
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The unique name for the new service",
+			},
+			"package_filename": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "The path to the Wasm deployment package to upload to a new Compute service, as packages can't be copied from the source service. Required to activate a clone of a Compute service",
+			},
+			"service_type": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The type of the source and new service (`vcl` or `wasm`)",
+			},
+			"source_service_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Alphanumeric string identifying the service to copy",
+			},
+			"source_version": {
+				Type:             schema.TypeInt,
+				Optional:         true,
+				Computed:         true,
+				ForceNew:         true,
+				Description:      "The version of the source service to copy. Defaults to the active version",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(1)),
+			},
+		},
+	}
+}
+
+// serviceCloneOverrides are the changes made to the configuration of the
+// source service when it is copied.
+type serviceCloneOverrides struct {
+	Domains              []string
+	BackendAddresses     map[string]string
+	LoggingEndpointNames map[string]string
+	DictionaryItems      map[string]map[string]string
+}
+
+// expandServiceCloneOverrides reads the overrides from the resource data.
+func expandServiceCloneOverrides(d *schema.ResourceData) serviceCloneOverrides {
+	o := serviceCloneOverrides{
+		BackendAddresses:     map[string]string{},
+		LoggingEndpointNames: map[string]string{},
+		DictionaryItems:      map[string]map[string]string{},
+	}
+	for _, v := range d.Get("domains").(*schema.Set).List() {
+		o.Domains = append(o.Domains, v.(string))
+	}
+	sort.Strings(o.Domains)
+	for k, v := range d.Get("backend_addresses").(map[string]any) {
+		o.BackendAddresses[k] = v.(string)
+	}
+	for k, v := range d.Get("logging_endpoint_names").(map[string]any) {
+		o.LoggingEndpointNames[k] = v.(string)
+	}
+	for _, v := range d.Get("dictionary_items").(*schema.Set).List() {
+		m := v.(map[string]any)
+		items := map[string]string{}
+		for k, v := range m["items"].(map[string]any) {
+			items[k] = v.(string)
+		}
+		o.DictionaryItems[m["name"].(string)] = items
+	}
+	return o
+}
+
+// serviceConfigurationBlocks returns the nested blocks of every attribute
+// handler of the service, keyed by block type.
+func serviceConfigurationBlocks(d *schema.ResourceData, serviceDef ServiceDefinition) map[string][]map[string]any {
+	blocks := map[string][]map[string]any{}
+	for _, a := range serviceDef.GetAttributeHandler() {
+		key, ok := serviceAttributeKey(a)
+		if !ok {
+			continue
+		}
+		for _, v := range d.Get(key).(*schema.Set).List() {
+			blocks[key] = append(blocks[key], v.(map[string]any))
+		}
+	}
+	return blocks
+}
+
+// applyServiceCloneOverrides applies the overrides to the nested blocks of the
+// source service. Overrides of blocks that don't exist in the source service
+// are an error, as they are most likely a typo.
+func applyServiceCloneOverrides(blocks map[string][]map[string]any, o serviceCloneOverrides) error {
+	var domains []map[string]any
+	for _, name := range o.Domains {
+		domains = append(domains, map[string]any{"name": name, "comment": ""})
+	}
+	blocks["domain"] = domains
+
+	found := map[string]bool{}
+	for _, backend := range blocks["backend"] {
+		name := backend["name"].(string)
+		if address, ok := o.BackendAddresses[name]; ok {
+			backend["address"] = address
+			found[name] = true
+		}
+	}
+	for name := range o.BackendAddresses {
+		if !found[name] {
+			return fmt.Errorf("backend_addresses: the source service has no backend named %q", name)
+		}
+	}
+
+	found = map[string]bool{}
+	for key, endpoints := range blocks {
+		if !strings.HasPrefix(key, "logging_") {
+			continue
+		}
+		for _, endpoint := range endpoints {
+			name := endpoint["name"].(string)
+			if newName, ok := o.LoggingEndpointNames[name]; ok {
+				endpoint["name"] = newName
+				found[name] = true
+			}
+		}
+	}
+	for name := range o.LoggingEndpointNames {
+		if !found[name] {
+			return fmt.Errorf("logging_endpoint_names: the source service has no logging endpoint named %q", name)
+		}
+	}
+
+	found = map[string]bool{}
+	for _, dictionary := range blocks["dictionary"] {
+		found[dictionary["name"].(string)] = true
+	}
+	for name := range o.DictionaryItems {
+		if !found[name] {
+			return fmt.Errorf("dictionary_items: the source service has no dictionary named %q", name)
+		}
+	}
+
+	return nil
+}
+
+func resourceServiceCloneCreate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	conn := meta.(*APIClient).conn
+
+	sourceID := d.Get("source_service_id").(string)
+	sourceVersion := d.Get("source_version").(int)
+
+	src, serviceDef, diags := readServiceConfiguration(ctx, meta, sourceID, sourceVersion)
+	if diags.HasError() {
+		return diags
+	}
+	if sourceVersion == 0 {
+		sourceVersion = src.Get("active_version").(int)
+		if sourceVersion == 0 {
+			return diag.Errorf("Fastly Service (%s) has no active version, set source_version to the version to copy", sourceID)
+		}
+	}
+
+	overrides := expandServiceCloneOverrides(d)
+	blocks := serviceConfigurationBlocks(src, serviceDef)
+	if err := applyServiceCloneOverrides(blocks, overrides); err != nil {
+		return diag.FromErr(err)
+	}
+
+	// The package of a Compute service can't be downloaded, so it is uploaded
+	// from package_filename, if given.
+	if serviceDef.GetType() == ServiceTypeCompute {
+		var pkg []any
+		if filename := d.Get("package_filename").(string); filename != "" {
+			pkg = []any{map[string]any{"filename": filename, "content": ""}}
+		} else if d.Get("activate").(bool) {
+			return diag.Errorf("package_filename is required to activate a clone of Compute service (%s)", sourceID)
+		}
+		if err := src.Set("package", pkg); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	// Dictionaries are copied with their items, unless they are write-only.
+	sourceDictionaries := map[string]string{}
+	for _, dictionary := range blocks["dictionary"] {
+		if id, _ := dictionary["dictionary_id"].(string); id != "" && !dictionary["write_only"].(bool) {
+			sourceDictionaries[dictionary["name"].(string)] = id
+		}
+	}
+
+	service, err := conn.CreateService(ctx, &gofastly.CreateServiceInput{
+		Name:    gofastly.ToPointer(d.Get("name").(string)),
+		Comment: gofastly.ToPointer(d.Get("comment").(string)),
+		Type:    gofastly.ToPointer(serviceDef.GetType()),
+	})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if service.ServiceID == nil {
+		return diag.Errorf("error: service.ServiceID is nil")
+	}
+	d.SetId(*service.ServiceID)
+
+	// If the service was just created, there is an empty Version 1 available
+	// that is unlocked and can be updated.
+	const serviceVersion = 1
+	if err := d.Set("cloned_version", serviceVersion); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("source_version", sourceVersion); err != nil {
+		return diag.FromErr(err)
+	}
+
+	_, err = conn.UpdateVersion(gofastly.NewContextForResourceID(ctx, d.Id()), &gofastly.UpdateVersionInput{
+		ServiceID:      d.Id(),
+		ServiceVersion: serviceVersion,
+		Comment:        gofastly.ToPointer(fmt.Sprintf("Cloned from version %d of Fastly Service (%s)", sourceVersion, sourceID)),
+	})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// The attribute handlers write to the new service.
+	src.SetId(d.Id())
+	if err := createServiceBlocks(ctx, src, serviceDef.GetAttributeHandler(), blocks, serviceVersion, meta.(*APIClient).maxConcurrentRequests, conn); err != nil {
+		return diag.Errorf("error copying the configuration of Fastly Service (%s): %s", sourceID, err)
+	}
+
+	if err := copyServiceDictionaryItems(ctx, conn, sourceID, sourceDictionaries, d.Id(), serviceVersion, overrides.DictionaryItems); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if serviceDef.GetType() == ServiceTypeVCL || d.Get("activate").(bool) {
+		log.Printf("[DEBUG] Validating Fastly Service (%s), Version (%v)", d.Id(), serviceVersion)
+		valid, msg, err := conn.ValidateVersion(gofastly.NewContextForResourceID(ctx, d.Id()), &gofastly.ValidateVersionInput{
+			ServiceID:      d.Id(),
+			ServiceVersion: serviceVersion,
+		})
+		if err != nil {
+			return diag.Errorf("error checking validation: %s", err)
+		}
+		if !valid {
+			return diag.Errorf("invalid configuration for Fastly Service (%s): %s", d.Id(), msg)
+		}
+	}
+
+	if d.Get("activate").(bool) {
+		log.Printf("[DEBUG] Activating Fastly Service (%s), Version (%v)", d.Id(), serviceVersion)
+		_, err := conn.ActivateVersion(gofastly.NewContextForResourceID(ctx, d.Id()), &gofastly.ActivateVersionInput{
+			ServiceID:      d.Id(),
+			ServiceVersion: serviceVersion,
+		})
+		if err != nil {
+			return diag.Errorf("error activating version (%d): %s", serviceVersion, err)
+		}
+	}
+
+	return append(diags, resourceServiceCloneRead(ctx, d, meta)...)
+}
+
+// copyServiceDictionaryItems copies the items of the source dictionaries,
+// keyed by name, to the dictionaries of the same name in the new service,
+// along with the overridden items.
+func copyServiceDictionaryItems(ctx context.Context, conn *gofastly.Client, sourceID string, sourceDictionaries map[string]string, serviceID string, serviceVersion int, overrides map[string]map[string]string) error {
+	if len(sourceDictionaries) == 0 && len(overrides) == 0 {
+		return nil
+	}
+
+	dictionaries, err := conn.ListDictionaries(gofastly.NewContextForResourceID(ctx, serviceID), &gofastly.ListDictionariesInput{
+		ServiceID:      serviceID,
+		ServiceVersion: serviceVersion,
+	})
+	if err != nil {
+		return fmt.Errorf("error listing dictionaries of Fastly Service (%s): %s", serviceID, err)
+	}
+
+	for _, dictionary := range dictionaries {
+		name := gofastly.ToValue(dictionary.Name)
+		items := map[string]string{}
+		if sourceDictionaryID, ok := sourceDictionaries[name]; ok {
+			remoteState, err := conn.ListDictionaryItems(gofastly.NewContextForResourceID(ctx, sourceID), &gofastly.ListDictionaryItemsInput{
+				ServiceID:    sourceID,
+				DictionaryID: sourceDictionaryID,
+			})
+			if err != nil {
+				return fmt.Errorf("error listing items of dictionary (%s) of Fastly Service (%s): %s", sourceDictionaryID, sourceID, err)
+			}
+			items = flattenDictionaryItems(remoteState)
+		}
+		for k, v := range overrides[name] {
+			items[k] = v
+		}
+		if len(items) == 0 {
+			continue
+		}
+
+		var batchDictionaryItems []*gofastly.BatchDictionaryItem
+		for key, val := range items {
+			batchDictionaryItems = append(batchDictionaryItems, &gofastly.BatchDictionaryItem{
+				Operation: gofastly.ToPointer(gofastly.CreateBatchOperation),
+				ItemKey:   gofastly.ToPointer(key),
+				ItemValue: gofastly.ToPointer(val),
+			})
+		}
+
+		dictionaryID := gofastly.ToValue(dictionary.DictionaryID)
+		log.Printf("[DEBUG] Copying %d items to dictionary (%s) of Fastly Service (%s)", len(batchDictionaryItems), dictionaryID, serviceID)
+		if err := executeBatchDictionaryOperations(ctx, conn, serviceID, dictionaryID, batchDictionaryItems); err != nil {
+			return fmt.Errorf("error creating dictionary items: service %s, dictionary %s, %s", serviceID, dictionaryID, err)
+		}
+	}
+
+	return nil
+}
+
+func resourceServiceCloneRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	log.Printf("[DEBUG] Refreshing Service Clone for (%s)", d.Id())
+
+	conn := meta.(*APIClient).conn
+
+	s, err := conn.GetServiceDetails(gofastly.NewContextForResourceID(ctx, d.Id()), &gofastly.GetServiceInput{
+		ServiceID: d.Id(),
+	})
+	if err != nil {
+		if e, ok := err.(*gofastly.HTTPError); ok && e.IsNotFound() {
+			log.Printf("[WARN] %s for ID (%s)", errFastlyNoServiceFound, d.Id())
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+
+	if s.DeletedAt != nil {
+		log.Printf("[WARN] Service ID (%s) has been deleted", d.Id())
+		d.SetId("")
+		return nil
+	}
+
+	if err := d.Set("name", s.Name); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("comment", s.Comment); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("service_type", s.Type); err != nil {
+		return diag.FromErr(err)
+	}
+	var activeVersion int
+	if s.ActiveVersion != nil {
+		activeVersion = gofastly.ToValue(s.ActiveVersion.Number)
+	}
+	if err := d.Set("active_version", activeVersion); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+func resourceServiceCloneUpdate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	conn := meta.(*APIClient).conn
+
+	// Name and comment don't require a new version.
+	if d.HasChanges("name", "comment") {
+		_, err := conn.UpdateService(gofastly.NewContextForResourceID(ctx, d.Id()), &gofastly.UpdateServiceInput{
+			ServiceID: d.Id(),
+			Name:      gofastly.ToPointer(d.Get("name").(string)),
+			Comment:   gofastly.ToPointer(d.Get("comment").(string)),
+		})
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	return resourceServiceCloneRead(ctx, d, meta)
+}
+
+func resourceServiceCloneDelete(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	conn := meta.(*APIClient).conn
+
+	// Fastly will fail to delete any service with an Active Version.
+	if d.Get("force_destroy").(bool) {
+		s, err := conn.GetServiceDetails(gofastly.NewContextForResourceID(ctx, d.Id()), &gofastly.GetServiceInput{
+			ServiceID: d.Id(),
+		})
+		if err != nil {
+			return diag.FromErr(err)
+		}
+
+		if s.ActiveVersion != nil && s.ActiveVersion.Number != nil && *s.ActiveVersion.Number != 0 {
+			_, err := conn.DeactivateVersion(gofastly.NewContextForResourceID(ctx, d.Id()), &gofastly.DeactivateVersionInput{
+				ServiceID:      d.Id(),
+				ServiceVersion: *s.ActiveVersion.Number,
+			})
+			if err != nil {
+				return diag.FromErr(err)
+			}
+		}
+	}
+
+	err := conn.DeleteService(ctx, &gofastly.DeleteServiceInput{
+		ServiceID: d.Id(),
+	})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
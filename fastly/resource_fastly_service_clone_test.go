@@ -0,0 +1,175 @@
+package fastly
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+
+	gofastly "github.com/fastly/go-fastly/v12/fastly"
+)
+
+func TestApplyServiceCloneOverrides(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceService(vclService).Schema, map[string]any{
+		"name": "source",
+		"domain": []any{
+			map[string]any{"name": "www.example.com"},
+		},
+		"backend": []any{
+			map[string]any{"name": "origin", "address": "origin.example.com"},
+			map[string]any{"name": "static", "address": "static.example.com"},
+		},
+		"dictionary": []any{
+			map[string]any{"name": "redirects"},
+		},
+		"logging_syslog": []any{
+			map[string]any{"name": "syslog", "address": "syslog.example.com"},
+		},
+		"logging_https": []any{
+			map[string]any{"name": "https", "url": "https://logs.example.com"},
+		},
+	})
+
+	blocks := serviceConfigurationBlocks(d, vclService)
+	err := applyServiceCloneOverrides(blocks, serviceCloneOverrides{
+		Domains:              []string{"staging.example.com"},
+		BackendAddresses:     map[string]string{"origin": "staging-origin.example.com"},
+		LoggingEndpointNames: map[string]string{"syslog": "staging syslog"},
+		DictionaryItems:      map[string]map[string]string{"redirects": {"/a": "/b"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if want := []map[string]any{{"name": "staging.example.com", "comment": ""}}; !reflect.DeepEqual(blocks["domain"], want) {
+		t.Errorf("expected domains %v, got %v", want, blocks["domain"])
+	}
+	addresses := map[string]any{}
+	for _, backend := range blocks["backend"] {
+		addresses[backend["name"].(string)] = backend["address"]
+	}
+	if want := map[string]any{"origin": "staging-origin.example.com", "static": "static.example.com"}; !reflect.DeepEqual(addresses, want) {
+		t.Errorf("expected backend addresses %v, got %v", want, addresses)
+	}
+	if got := blocks["logging_syslog"][0]["name"]; got != "staging syslog" {
+		t.Errorf("expected the syslog endpoint to be renamed, got %q", got)
+	}
+	if got := blocks["logging_https"][0]["name"]; got != "https" {
+		t.Errorf("expected the https endpoint to keep its name, got %q", got)
+	}
+}
+
+func TestApplyServiceCloneOverrides_unknown(t *testing.T) {
+	for name, o := range map[string]serviceCloneOverrides{
+		`no backend named "missing"`:          {BackendAddresses: map[string]string{"missing": "example.com"}},
+		`no logging endpoint named "missing"`: {LoggingEndpointNames: map[string]string{"missing": "renamed"}},
+		`no dictionary named "missing"`:       {DictionaryItems: map[string]map[string]string{"missing": {"k": "v"}}},
+	} {
+		blocks := map[string][]map[string]any{
+			"backend":        {{"name": "origin", "address": "origin.example.com"}},
+			"logging_syslog": {{"name": "syslog"}},
+		}
+		err := applyServiceCloneOverrides(blocks, o)
+		if err == nil || !strings.Contains(err.Error(), name) {
+			t.Errorf("expected error %q, got %v", name, err)
+		}
+	}
+}
+
+func TestAccFastlyServiceClone_basic(t *testing.T) {
+	var service gofastly.ServiceDetail
+	name := fmt.Sprintf("tf-test-%s", acctest.RandString(10))
+	domain := fmt.Sprintf("fastly-test.%s.com", name)
+	cloneDomain := fmt.Sprintf("fastly-test-staging.%s.com", name)
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      testAccCheckServiceVCLDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+resource "fastly_service_vcl" "source" {
+  name = "%s"
+
+  domain {
+    name = "%s"
+  }
+
+  backend {
+    address = "aws.amazon.com"
+    name    = "origin"
+  }
+
+  dictionary {
+    name = "redirects"
+  }
+
+  force_destroy = true
+}
+
+resource "fastly_service_dictionary_items" "source" {
+  service_id    = fastly_service_vcl.source.id
+  dictionary_id = one(fastly_service_vcl.source.dictionary).dictionary_id
+  items = {
+    "/a" = "/b"
+  }
+}
+
+resource "fastly_service_clone" "staging" {
+  source_service_id = fastly_service_vcl.source.id
+  name              = "%s-staging"
+  domains           = ["%s"]
+
+  backend_addresses = {
+    origin = "httpbin.org"
+  }
+
+  dictionary_items {
+    name = "redirects"
+    items = {
+      "/c" = "/d"
+    }
+  }
+
+  force_destroy = true
+  depends_on    = [fastly_service_dictionary_items.source]
+}
+`, name, domain, name, cloneDomain),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckServiceExists("fastly_service_clone.staging", &service),
+					resource.TestCheckResourceAttr("fastly_service_clone.staging", "service_type", ServiceTypeVCL),
+					resource.TestCheckResourceAttr("fastly_service_clone.staging", "active_version", "1"),
+					resource.TestCheckResourceAttr("fastly_service_clone.staging", "source_version", "1"),
+					testAccCheckServiceCloneBackend(&service, "origin", "httpbin.org"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckServiceCloneBackend(service *gofastly.ServiceDetail, name, address string) resource.TestCheckFunc {
+	return func(_ *terraform.State) error {
+		conn := testAccProvider.Meta().(*APIClient).conn
+		backend, err := conn.GetBackend(context.TODO(), &gofastly.GetBackendInput{
+			ServiceID:      gofastly.ToValue(service.ServiceID),
+			ServiceVersion: gofastly.ToValue(service.ActiveVersion.Number),
+			Name:           name,
+		})
+		if err != nil {
+			return fmt.Errorf("error looking up backend %s of Fastly Service (%s): %s", name, gofastly.ToValue(service.ServiceID), err)
+		}
+		if got := gofastly.ToValue(backend.Address); got != address {
+			return fmt.Errorf("expected backend %s to have address %q, got %q", name, address, got)
+		}
+		return nil
+	}
+}
@@ -11,6 +11,11 @@ var computeAttributes = ServiceMetadata{
 // Ordering is important - stored is processing order
 // Some objects may need to be updated first, as they can be referenced by other
 // configuration objects (Backends, Request Headers, etc).
+//
+// NOTE: The condition, header, gzip, cache_setting and response_object
+// handlers are deliberately not registered. They only affect the VCL generated
+// for Delivery services and are never executed by the Compute platform (see
+// the 2.0.0 upgrade guide for blocks previously removed for the same reason).
 var computeService = &BaseServiceDefinition{
 	Type: computeAttributes.serviceType,
 	Attributes: []ServiceAttributeDefinition{
@@ -0,0 +1,460 @@
+package fastly
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	gofastly "github.com/fastly/go-fastly/v12/fastly"
+)
+
+const (
+	rolloutStatusAborted   = "aborted"
+	rolloutStatusFailed    = "failed"
+	rolloutStatusSucceeded = "succeeded"
+
+	rolloutStepFailed  = "failed"
+	rolloutStepPassed  = "passed"
+	rolloutStepSkipped = "skipped"
+
+	// rolloutAbortTimeout bounds the requests made to abort a rollout, which
+	// still run once the rollout itself has timed out.
+	rolloutAbortTimeout = 2 * time.Minute
+)
+
+func resourceFastlyServiceRollout() *schema.Resource {
+	stagingCheck := activationCheckSchema()
+	stagingCheck.Description = "HTTP probes to run against the staging environment once the version is staged, before it is activated. The probes connect to `staging_ip` instead of resolving the host of their URL. If any probe fails, the version is not activated."
+	stagingCheck.RequiredWith = []string{"staging_ip"}
+	productionCheck := activationCheckSchema()
+	productionCheck.Description = "HTTP probes to run against production once the version is activated. If any probe fails, the rollout fails (and is aborted, if `abort_on_failure` is `true`)."
+
+	return &schema.Resource{
+		CreateContext: resourceFastlyServiceRolloutCreate,
+		ReadContext:   resourceFastlyServiceRolloutRead,
+		UpdateContext: resourceFastlyServiceRolloutUpdate,
+		DeleteContext: resourceFastlyServiceRolloutDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceFastlyServiceRolloutImport,
+		},
+		Schema: map[string]*schema.Schema{
+			"abort_on_failure": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "When a step fails, restore the versions that were active in production and staging before the rollout. Default `true`. Otherwise the environments are left as they were when the step failed.",
+			},
+			"activation_check": productionCheck,
+			"previous_version": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The version that was active in production before the rollout. `0` if no version was active.",
+			},
+			"service_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Alphanumeric string identifying the service.",
+			},
+			"staging_check": stagingCheck,
+			"staging_ip": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Description:      "The IP address of the Fastly staging environment, which `staging_check` probes connect to.",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IsIPAddress),
+			},
+			"status": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The outcome of the last rollout. One of `succeeded`, `failed` (a step failed and the environments were left as they were) or `aborted` (a step failed and the previous versions were restored).",
+			},
+			"steps": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The steps of the last rollout, in order.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"finished_at": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "When the step finished (RFC 3339). Empty if the step was skipped.",
+						},
+						"message": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The error of a failed step.",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The name of the step. One of `stage`, `staging_check`, `activate`, `activation_check` or `abort`.",
+						},
+						"started_at": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "When the step started (RFC 3339). Empty if the step was skipped.",
+						},
+						"status": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The outcome of the step. One of `passed`, `failed` or `skipped`.",
+						},
+					},
+				},
+			},
+			"version": {
+				Type:             schema.TypeInt,
+				Required:         true,
+				Description:      "The candidate version to roll out (e.g. the `cloned_version` of a service with `activate = false`). Changing it starts a new rollout.",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(1)),
+			},
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(30 * time.Minute),
+			Update: schema.DefaultTimeout(30 * time.Minute),
+		},
+	}
+}
+
+func resourceFastlyServiceRolloutCreate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	d.SetId(d.Get("service_id").(string))
+
+	diags := rolloutServiceVersion(ctx, d, meta)
+	if diags.HasError() {
+		return diags
+	}
+	return append(diags, resourceFastlyServiceRolloutRead(ctx, d, meta)...)
+}
+
+func resourceFastlyServiceRolloutRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	conn := meta.(*APIClient).conn
+
+	serviceID := d.Get("service_id").(string)
+
+	log.Printf("[DEBUG] REFRESH: Service Rollout for service (%s)", serviceID)
+
+	s, err := conn.GetServiceDetails(gofastly.NewContextForResourceID(ctx, serviceID), &gofastly.GetServiceInput{
+		ServiceID: serviceID,
+	})
+	if err != nil {
+		if e, ok := err.(*gofastly.HTTPError); ok && e.IsNotFound() {
+			log.Printf("[WARN] %s for ID (%s)", errFastlyNoServiceFound, serviceID)
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+	if s.DeletedAt != nil {
+		log.Printf("[WARN] Service ID (%s) has been deleted", serviceID)
+		d.SetId("")
+		return nil
+	}
+
+	// NOTE: If the rollout was aborted, or another version has since been
+	// activated, the version is updated so that Terraform plans to roll out
+	// the configured version again.
+	if err := d.Set("version", activeServiceVersion(s, serviceEnvironmentProduction)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+func resourceFastlyServiceRolloutUpdate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	if d.HasChange("version") {
+		diags := rolloutServiceVersion(ctx, d, meta)
+		if diags.HasError() {
+			return diags
+		}
+		return append(diags, resourceFastlyServiceRolloutRead(ctx, d, meta)...)
+	}
+	return resourceFastlyServiceRolloutRead(ctx, d, meta)
+}
+
+func resourceFastlyServiceRolloutDelete(_ context.Context, d *schema.ResourceData, _ any) diag.Diagnostics {
+	log.Printf("[INFO] Leaving Fastly Service (%s), Version (%d) active", d.Get("service_id").(string), d.Get("version").(int))
+	return nil
+}
+
+func resourceFastlyServiceRolloutImport(_ context.Context, d *schema.ResourceData, _ any) ([]*schema.ResourceData, error) {
+	if err := d.Set("service_id", d.Id()); err != nil {
+		return nil, err
+	}
+	if err := d.Set("abort_on_failure", true); err != nil {
+		return nil, err
+	}
+	return []*schema.ResourceData{d}, nil
+}
+
+// rolloutStep is a step of a rollout.
+type rolloutStep struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// rolloutStepResult records the outcome of a rollout step.
+type rolloutStepResult struct {
+	Name       string
+	Status     string
+	Message    string
+	StartedAt  time.Time
+	FinishedAt time.Time
+}
+
+// runRolloutSteps runs the steps in order until one fails. The remaining
+// steps are recorded as skipped, and the index of the failed step is
+// returned along with its error, or -1 if all steps passed.
+func runRolloutSteps(ctx context.Context, steps []rolloutStep) ([]rolloutStepResult, int, error) {
+	results := make([]rolloutStepResult, 0, len(steps))
+	failed := -1
+	var err error
+	for i, step := range steps {
+		if err != nil {
+			results = append(results, rolloutStepResult{Name: step.Name, Status: rolloutStepSkipped})
+			continue
+		}
+
+		r := rolloutStepResult{Name: step.Name, Status: rolloutStepPassed, StartedAt: time.Now()}
+		log.Printf("[DEBUG] Running rollout step %s", step.Name)
+		if err = step.Run(ctx); err != nil {
+			r.Status = rolloutStepFailed
+			r.Message = err.Error()
+			failed = i
+		}
+		r.FinishedAt = time.Now()
+		results = append(results, r)
+	}
+	return results, failed, err
+}
+
+// flattenRolloutSteps models the step results into a format suitable for
+// saving to Terraform state.
+func flattenRolloutSteps(results []rolloutStepResult) []map[string]any {
+	formatTime := func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.UTC().Format(time.RFC3339)
+	}
+
+	steps := make([]map[string]any, 0, len(results))
+	for _, r := range results {
+		steps = append(steps, map[string]any{
+			"finished_at": formatTime(r.FinishedAt),
+			"message":     r.Message,
+			"name":        r.Name,
+			"started_at":  formatTime(r.StartedAt),
+			"status":      r.Status,
+		})
+	}
+	return steps
+}
+
+// rolloutServiceVersion stages the configured version, runs the staging
+// checks, activates the version and runs the activation checks. When a step
+// fails and `abort_on_failure` is set, the previously active versions are
+// restored. The steps and outcome are recorded in state either way.
+func rolloutServiceVersion(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	conn := meta.(*APIClient).conn
+
+	serviceID := d.Get("service_id").(string)
+	version := d.Get("version").(int)
+
+	stagingCheck, err := expandActivationCheck(d.Get("staging_check").([]any))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	postActivationCheck, err := expandActivationCheck(d.Get("activation_check").([]any))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	s, err := conn.GetServiceDetails(gofastly.NewContextForResourceID(ctx, serviceID), &gofastly.GetServiceInput{
+		ServiceID: serviceID,
+	})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	previous := activeServiceVersion(s, serviceEnvironmentProduction)
+	previousStaged := activeServiceVersion(s, serviceEnvironmentStaging)
+
+	if err := d.Set("previous_version", previous); err != nil {
+		return diag.FromErr(err)
+	}
+
+	activate := func(environment string, v int) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			log.Printf("[DEBUG] Activating Fastly Service (%s), Version (%d) in %s", serviceID, v, environment)
+			_, err := conn.ActivateVersion(gofastly.NewContextForResourceID(ctx, serviceID), &gofastly.ActivateVersionInput{
+				Environment:    activationEnvironment(environment),
+				ServiceID:      serviceID,
+				ServiceVersion: v,
+			})
+			if err != nil {
+				return fmt.Errorf("error activating version (%d) in %s: %w", v, environment, err)
+			}
+			return nil
+		}
+	}
+	check := func(c *activationCheck, client *http.Client) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			if c == nil {
+				return nil
+			}
+			return c.run(ctx, client)
+		}
+	}
+
+	steps := []rolloutStep{
+		{Name: "stage", Run: activate(serviceEnvironmentStaging, version)},
+		{Name: "staging_check", Run: check(stagingCheck, stagingHTTPClient(d.Get("staging_ip").(string)))},
+		{Name: "activate", Run: activate(serviceEnvironmentProduction, version)},
+		{Name: "activation_check", Run: check(postActivationCheck, newActivationCheckHTTPClient(nil))},
+	}
+	if previous == version {
+		log.Printf("[INFO] Fastly Service (%s), Version (%d) is already active", serviceID, version)
+		steps = nil
+	}
+
+	results, failed, rolloutErr := runRolloutSteps(ctx, steps)
+	status := rolloutStatusSucceeded
+	if rolloutErr != nil {
+		status = rolloutStatusFailed
+	}
+
+	var abortErr error
+	if rolloutErr != nil && d.Get("abort_on_failure").(bool) {
+		// The rollout may have failed because it timed out, so the previous
+		// versions are restored with a context of their own.
+		abortCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), rolloutAbortTimeout)
+		defer cancel()
+
+		// The version is only active in production once the activate step
+		// has passed.
+		activated := results[failed].Name == "activation_check"
+		abort := []rolloutStep{{
+			Name: "abort",
+			Run: func(ctx context.Context) error {
+				return restoreServiceVersions(ctx, conn, serviceID, version, previous, previousStaged, activated)
+			},
+		}}
+		var abortResults []rolloutStepResult
+		abortResults, _, abortErr = runRolloutSteps(abortCtx, abort)
+		results = append(results, abortResults...)
+		if abortErr == nil {
+			status = rolloutStatusAborted
+		}
+	}
+
+	if err := d.Set("status", status); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("steps", flattenRolloutSteps(results)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if rolloutErr == nil {
+		return nil
+	}
+
+	// Record the version that is now active, so that the next plan rolls out
+	// the configured version again.
+	if status == rolloutStatusAborted {
+		if err := d.Set("version", previous); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	step := results[failed].Name
+	switch {
+	case abortErr != nil:
+		return diag.Diagnostics{{
+			Severity: diag.Error,
+			Summary:  "Rollout failed and abort failed",
+			Detail:   fmt.Sprintf("Step %s of the rollout of version %d of Fastly Service (%s) failed: %s\n\nError restoring the previous versions: %s", step, version, serviceID, rolloutErr, abortErr),
+		}}
+	case status == rolloutStatusAborted:
+		return diag.Diagnostics{{
+			Severity: diag.Error,
+			Summary:  "Rollout aborted",
+			Detail:   fmt.Sprintf("Step %s of the rollout of version %d of Fastly Service (%s) failed and the previous versions have been restored: %s", step, version, serviceID, rolloutErr),
+		}}
+	default:
+		return diag.Diagnostics{{
+			Severity: diag.Error,
+			Summary:  "Rollout failed",
+			Detail:   fmt.Sprintf("Step %s of the rollout of version %d of Fastly Service (%s) failed: %s", step, version, serviceID, rolloutErr),
+		}}
+	}
+}
+
+// restoreServiceVersions restores the versions that were active in
+// production and staging before a rollout of version. Production is only
+// restored if the version was activated.
+func restoreServiceVersions(ctx context.Context, conn *gofastly.Client, serviceID string, version, previous, previousStaged int, activated bool) error {
+	restore := func(environment string, v int) error {
+		if v != 0 {
+			log.Printf("[DEBUG] Restoring Fastly Service (%s), Version (%d) in %s", serviceID, v, environment)
+			_, err := conn.ActivateVersion(gofastly.NewContextForResourceID(ctx, serviceID), &gofastly.ActivateVersionInput{
+				Environment:    activationEnvironment(environment),
+				ServiceID:      serviceID,
+				ServiceVersion: v,
+			})
+			if err != nil {
+				return fmt.Errorf("error activating version (%d) in %s: %w", v, environment, err)
+			}
+			return nil
+		}
+
+		log.Printf("[DEBUG] Deactivating Fastly Service (%s), Version (%d) in %s", serviceID, version, environment)
+		_, err := conn.DeactivateVersion(gofastly.NewContextForResourceID(ctx, serviceID), &gofastly.DeactivateVersionInput{
+			Environment:    activationEnvironment(environment),
+			ServiceID:      serviceID,
+			ServiceVersion: version,
+		})
+		if err != nil {
+			return fmt.Errorf("error deactivating version (%d) in %s: %w", version, environment, err)
+		}
+		return nil
+	}
+
+	if activated && previous != version {
+		if err := restore(serviceEnvironmentProduction, previous); err != nil {
+			return err
+		}
+	}
+	if previousStaged != version {
+		return restore(serviceEnvironmentStaging, previousStaged)
+	}
+	return nil
+}
+
+// stagingHTTPClient returns a HTTP client that connects to the given staging
+// IP address, on the port of the request URL, instead of resolving the host of
+// the URL. The host is still used for the Host header and TLS server name.
+// Like every probe client, it doesn't follow redirects.
+func stagingHTTPClient(stagingIP string) *http.Client {
+	if stagingIP == "" {
+		return newActivationCheckHTTPClient(nil)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		_, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		return dialer.DialContext(ctx, network, net.JoinHostPort(stagingIP, port))
+	}
+	transport.Proxy = nil
+
+	return newActivationCheckHTTPClient(transport)
+}
@@ -0,0 +1,132 @@
+package fastly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestRunRolloutSteps(t *testing.T) {
+	var ran []string
+	step := func(name string, err error) rolloutStep {
+		return rolloutStep{Name: name, Run: func(context.Context) error {
+			ran = append(ran, name)
+			return err
+		}}
+	}
+
+	results, failed, err := runRolloutSteps(context.Background(), []rolloutStep{
+		step("stage", nil),
+		step("staging_check", errors.New("probe failed")),
+		step("activate", nil),
+	})
+	if err == nil || err.Error() != "probe failed" {
+		t.Errorf("expected the error of the failed step, got %v", err)
+	}
+	if failed != 1 {
+		t.Errorf("expected step 1 to fail, got %d", failed)
+	}
+	if want := []string{"stage", "staging_check"}; !reflect.DeepEqual(ran, want) {
+		t.Errorf("expected steps %v to run, got %v", want, ran)
+	}
+
+	var statuses []string
+	for _, r := range flattenRolloutSteps(results) {
+		statuses = append(statuses, fmt.Sprintf("%s:%s:%s", r["name"], r["status"], r["message"]))
+		if (r["status"] == rolloutStepSkipped) != (r["started_at"] == "") {
+			t.Errorf("expected only skipped steps to have no start time, got %v", r)
+		}
+	}
+	if want := []string{"stage:passed:", "staging_check:failed:probe failed", "activate:skipped:"}; !reflect.DeepEqual(statuses, want) {
+		t.Errorf("expected steps %v, got %v", want, statuses)
+	}
+
+	results, failed, err = runRolloutSteps(context.Background(), []rolloutStep{step("stage", nil)})
+	if err != nil || failed != -1 || len(results) != 1 {
+		t.Errorf("expected all steps to pass, got %v, %d, %v", results, failed, err)
+	}
+}
+
+func TestStagingHTTPClient(t *testing.T) {
+	server := newTestEdgeServer(t, 0)
+	u, _ := url.Parse(server.URL)
+
+	// The host doesn't resolve, so the request only succeeds if it is sent to
+	// the staging IP.
+	u.Host = "staging.example.invalid:" + u.Port()
+	resp, err := stagingHTTPClient("127.0.0.1").Get(u.String())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status 200, got %d", resp.StatusCode)
+	}
+	if got, want := resp.Header.Get("X-Served-By"), "cache-"+u.Host; got != want {
+		t.Errorf("expected the request host to be preserved (%q), got %q", want, got)
+	}
+
+	if stagingHTTPClient("").Transport != nil {
+		t.Errorf("expected the default transport without a staging IP")
+	}
+
+	// Probes can expect a redirect status, so redirects aren't followed.
+	for _, ip := range []string{"", "127.0.0.1"} {
+		if err := stagingHTTPClient(ip).CheckRedirect(nil, nil); err != http.ErrUseLastResponse {
+			t.Errorf("expected the client for staging IP %q not to follow redirects, got %v", ip, err)
+		}
+	}
+}
+
+func TestAccFastlyServiceRollout_basic(t *testing.T) {
+	name := fmt.Sprintf("tf-test-%s", acctest.RandString(10))
+	domain := fmt.Sprintf("fastly-test.%s.com", name)
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      testAccCheckServiceVCLDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccServiceRolloutConfig(name, domain, "amazon docs"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("fastly_service_rollout.example", "version", "1"),
+					resource.TestCheckResourceAttr("fastly_service_rollout.example", "previous_version", "0"),
+					resource.TestCheckResourceAttr("fastly_service_rollout.example", "status", rolloutStatusSucceeded),
+					resource.TestCheckResourceAttr("fastly_service_rollout.example", "steps.#", "4"),
+					resource.TestCheckResourceAttr("fastly_service_rollout.example", "steps.2.name", "activate"),
+					resource.TestCheckResourceAttr("fastly_service_rollout.example", "steps.2.status", rolloutStepPassed),
+					testAccCheckServiceVersionActive("fastly_service_vcl.example", 1),
+				),
+			},
+			{
+				Config: testAccServiceRolloutConfig(name, domain, "updated docs"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("fastly_service_rollout.example", "version", "2"),
+					resource.TestCheckResourceAttr("fastly_service_rollout.example", "previous_version", "1"),
+					resource.TestCheckResourceAttr("fastly_service_rollout.example", "status", rolloutStatusSucceeded),
+					testAccCheckServiceVersionActive("fastly_service_vcl.example", 2),
+				),
+			},
+		},
+	})
+}
+
+func testAccServiceRolloutConfig(name, domain, backendName string) string {
+	return testAccServiceVersionActivationServiceConfig(name, domain, backendName) + `
+resource "fastly_service_rollout" "example" {
+  service_id = fastly_service_vcl.example.id
+  version    = fastly_service_vcl.example.cloned_version
+}
+`
+}
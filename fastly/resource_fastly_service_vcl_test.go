@@ -519,7 +519,10 @@ func testAccCheckServiceExists(n string, service *gofastly.ServiceDetail) resour
 			return fmt.Errorf("no Service ID is set")
 		}
 
-		conn := testAccProvider.Meta().(*APIClient).conn
+		conn, err := testAccAccountConn(rs)
+		if err != nil {
+			return err
+		}
 		latest, err := conn.GetServiceDetails(context.TODO(), &gofastly.GetServiceInput{
 			ServiceID: rs.Primary.ID,
 		})
@@ -699,6 +702,237 @@ func TestAccFastlyServiceVCL_brokenSnippet(t *testing.T) {
 	})
 }
 
+// TestAccFastlyServiceVCL_invalidSnippet checks that unbalanced snippets are
+// rejected at plan time, before a new version is cloned.
+func TestAccFastlyServiceVCL_invalidSnippet(t *testing.T) {
+	name := fmt.Sprintf("tf-test-%s", acctest.RandString(10))
+	domain := fmt.Sprintf("fastly-test.tf-%s.test", acctest.RandString(10))
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      testAccCheckServiceVCLDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccServiceVCLConfigBrokenSnippet(name, domain, "backend1", `if (req.url !~ "^/anything") {
+                       set req.url = "/anything" req.url;`),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`snippet "url rewrite": line 1: unclosed '\{'`),
+			},
+		},
+	})
+}
+
+func TestAccFastlyServiceVCL_sourceSnapshot(t *testing.T) {
+	var service gofastly.ServiceDetail
+	name := fmt.Sprintf("tf-test-%s", acctest.RandString(10))
+	domain := fmt.Sprintf("fastly-test.tf-%s.test", acctest.RandString(10))
+
+	config := func(backend string) string {
+		return fmt.Sprintf(`
+resource "fastly_service_vcl" "foo" {
+  name = "%s"
+
+  domain {
+    name = "%s"
+  }
+%s
+  source_snapshot = jsonencode({
+    backend = [{
+      address = "httpbin.org"
+      name    = "restored origin"
+      port    = 443
+    }]
+    domain = [{
+      name = "ignored.example.com"
+    }]
+  })
+
+  force_destroy = true
+}`, name, domain, backend)
+	}
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      testAccCheckServiceVCLDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: config(""),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckServiceExists("fastly_service_vcl.foo", &service),
+					testAccCheckFastlyServiceVCLAttributes(&service, name, []string{domain}),
+					// Restored blocks are read into state, but aren't managed
+					// until they are declared.
+					resource.TestCheckResourceAttr("fastly_service_vcl.foo", "backend.#", "1"),
+					resource.TestCheckTypeSetElemNestedAttrs("fastly_service_vcl.foo", "backend.*", map[string]string{
+						"name": "restored origin",
+					}),
+					resource.TestCheckTypeSetElemAttr("fastly_service_vcl.foo", "source_snapshot_blocks.*", "backend"),
+					func(_ *terraform.State) error {
+						conn := testAccProvider.Meta().(*APIClient).conn
+						backends, err := conn.ListBackends(context.TODO(), &gofastly.ListBackendsInput{
+							ServiceID:      gofastly.ToValue(service.ServiceID),
+							ServiceVersion: gofastly.ToValue(service.ActiveVersion.Number),
+						})
+						if err != nil {
+							return err
+						}
+						if len(backends) != 1 || gofastly.ToValue(backends[0].Name) != "restored origin" {
+							return fmt.Errorf("expected the backend to be restored from the snapshot, got %d backends", len(backends))
+						}
+						return nil
+					},
+				),
+			},
+			{
+				// Declaring the restored backend takes it over without creating
+				// or deleting anything, so no new version is activated.
+				Config: config(`
+  backend {
+    address = "httpbin.org"
+    name    = "restored origin"
+    port    = 443
+  }
+`),
+				ExpectNonEmptyPlan: false,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("fastly_service_vcl.foo", "active_version", "1"),
+					resource.TestCheckResourceAttr("fastly_service_vcl.foo", "backend.#", "1"),
+					resource.TestCheckResourceAttr("fastly_service_vcl.foo", "source_snapshot_blocks.#", "0"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccFastlyServiceVCL_driftPolicy(t *testing.T) {
+	var service gofastly.ServiceDetail
+	name := fmt.Sprintf("tf-test-%s", acctest.RandString(10))
+	domain := fmt.Sprintf("fastly-test.tf-%s.test", acctest.RandString(10))
+
+	config := func(policy string) string {
+		return fmt.Sprintf(`
+resource "fastly_service_vcl" "foo" {
+  name         = "%s"
+  drift_policy = "%s"
+
+  domain {
+    name = "%s"
+  }
+
+  backend {
+    address = "httpbin.org"
+    name    = "origin"
+  }
+
+  force_destroy = true
+}`, name, policy, domain)
+	}
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      testAccCheckServiceVCLDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: config("error"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckServiceExists("fastly_service_vcl.foo", &service),
+					resource.TestCheckResourceAttr("fastly_service_vcl.foo", "active_version", "1"),
+				),
+			},
+			{
+				// Activate a clone of the active version outside of Terraform.
+				PreConfig: func() {
+					conn := testAccProvider.Meta().(*APIClient).conn
+					version, err := conn.CloneVersion(context.TODO(), &gofastly.CloneVersionInput{
+						ServiceID:      gofastly.ToValue(service.ServiceID),
+						ServiceVersion: gofastly.ToValue(service.ActiveVersion.Number),
+					})
+					if err != nil {
+						t.Fatal(err)
+					}
+					if _, err := conn.ActivateVersion(context.TODO(), &gofastly.ActivateVersionInput{
+						ServiceID:      gofastly.ToValue(service.ServiceID),
+						ServiceVersion: gofastly.ToValue(version.Number),
+					}); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config:      config("error"),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`Version 2 of Fastly Service \(\w+\) was activated outside of Terraform`),
+			},
+			{
+				Config: config("warn"),
+				Check:  resource.TestCheckResourceAttr("fastly_service_vcl.foo", "active_version", "2"),
+			},
+		},
+	})
+}
+
+func TestAccFastlyServiceVCL_defaultComments(t *testing.T) {
+	var service gofastly.ServiceDetail
+	name := fmt.Sprintf("tf-test-%s", acctest.RandString(10))
+	domain := fmt.Sprintf("fastly-test.tf-%s.test", acctest.RandString(10))
+	t.Setenv("TF_TEST_PIPELINE_ID", "1234")
+
+	config := func(backendName string) string {
+		return fmt.Sprintf(`
+provider "fastly" {
+  default_service_comment = "Created by pipeline {{env.TF_TEST_PIPELINE_ID}}"
+  default_version_comment = "Deployed by pipeline {{env.TF_TEST_PIPELINE_ID}}"
+}
+
+resource "fastly_service_vcl" "foo" {
+  name = "%s"
+
+  domain {
+    name = "%s"
+  }
+
+  backend {
+    address = "httpbin.org"
+    name    = "%s"
+  }
+
+  force_destroy = true
+}`, name, domain, backendName)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      testAccCheckServiceVCLDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: config("origin"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckServiceExists("fastly_service_vcl.foo", &service),
+					resource.TestCheckResourceAttr("fastly_service_vcl.foo", "comment", "Created by pipeline 1234"),
+					resource.TestCheckResourceAttr("fastly_service_vcl.foo", "version_comment", "Deployed by pipeline 1234"),
+				),
+			},
+			{
+				Config: config("updated origin"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("fastly_service_vcl.foo", "active_version", "2"),
+					resource.TestCheckResourceAttr("fastly_service_vcl.foo", "version_comment", "Deployed by pipeline 1234"),
+				),
+			},
+		},
+	})
+}
+
 func TestAccFastlyServiceVCL_createZeroDefaultTTL(t *testing.T) {
 	var service gofastly.ServiceDetail
 	name := fmt.Sprintf("tf-test-%s", acctest.RandString(10))
@@ -735,7 +969,10 @@ func testAccCheckServiceVCLDestroy(s *terraform.State) error {
 			continue
 		}
 
-		conn := testAccProvider.Meta().(*APIClient).conn
+		conn, err := testAccAccountConn(rs)
+		if err != nil {
+			return err
+		}
 		l, err := conn.ListServices(context.TODO(), &gofastly.ListServicesInput{})
 		if err != nil {
 			return fmt.Errorf("error listing services when deleting Fastly Service (%s): %s", rs.Primary.ID, err)
@@ -1065,11 +1302,10 @@ resource "fastly_service_vcl" "foo" {
 }
 
 func testSweepServices(region string) error {
-	client, diagnostics := sharedClientForRegion(region)
-	if diagnostics.HasError() {
-		return diagToErr(diagnostics)
-	}
+	return sweepAccounts(region, sweepServices)
+}
 
+func sweepServices(client *gofastly.Client) error {
 	services, err := client.ListServices(context.TODO(), &gofastly.ListServicesInput{})
 	if err != nil {
 		return err
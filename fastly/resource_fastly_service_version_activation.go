@@ -0,0 +1,291 @@
+package fastly
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	gofastly "github.com/fastly/go-fastly/v12/fastly"
+)
+
+const (
+	serviceEnvironmentProduction = "production"
+	serviceEnvironmentStaging    = "staging"
+)
+
+func resourceFastlyServiceVersionActivation() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceFastlyServiceVersionActivationCreate,
+		ReadContext:   resourceFastlyServiceVersionActivationRead,
+		UpdateContext: resourceFastlyServiceVersionActivationUpdate,
+		DeleteContext: resourceFastlyServiceVersionActivationDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceFastlyServiceVersionActivationImport,
+		},
+		Schema: map[string]*schema.Schema{
+			"environment": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ForceNew:         true,
+				Default:          serviceEnvironmentProduction,
+				Description:      "The environment to activate the version in. One of `production` or `staging`. Default `production`.",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice([]string{serviceEnvironmentProduction, serviceEnvironmentStaging}, false)),
+			},
+			"previous_version": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The version that was active in the environment before `version` was activated. `0` if no version was active, or if `version` was already active when the resource was created. Unchanged if `version` changes to a version that is already active.",
+			},
+			"rollback_on_destroy": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Re-activate `previous_version` when the resource is destroyed. Default `false`, which leaves `version` active.",
+			},
+			"service_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Alphanumeric string identifying the service.",
+			},
+			"version": {
+				Type:             schema.TypeInt,
+				Required:         true,
+				Description:      "The service version to activate (e.g. the `cloned_version` of a service with `activate = false`).",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(1)),
+			},
+			"wait_for_propagation": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Description:      "How long to wait after each activation, to allow the configuration to propagate across the Fastly network (e.g. `30s`). Resources that `depends_on` the activation, such as smoke tests, run after the wait.",
+				ValidateDiagFunc: validateDuration(),
+			},
+		},
+	}
+}
+
+func resourceFastlyServiceVersionActivationCreate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	diags := activateServiceVersion(ctx, d, meta)
+	if diags.HasError() {
+		return diags
+	}
+
+	d.SetId(fmt.Sprintf("%s/%s", d.Get("service_id").(string), d.Get("environment").(string)))
+
+	return append(diags, resourceFastlyServiceVersionActivationRead(ctx, d, meta)...)
+}
+
+func resourceFastlyServiceVersionActivationRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	conn := meta.(*APIClient).conn
+
+	serviceID := d.Get("service_id").(string)
+	environment := d.Get("environment").(string)
+
+	log.Printf("[DEBUG] REFRESH: Service Version Activation for service (%s) in %s", serviceID, environment)
+
+	s, err := conn.GetServiceDetails(gofastly.NewContextForResourceID(ctx, serviceID), &gofastly.GetServiceInput{
+		ServiceID: serviceID,
+	})
+	if err != nil {
+		if e, ok := err.(*gofastly.HTTPError); ok && e.IsNotFound() {
+			log.Printf("[WARN] %s for ID (%s)", errFastlyNoServiceFound, serviceID)
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+	if s.DeletedAt != nil {
+		log.Printf("[WARN] Service ID (%s) has been deleted", serviceID)
+		d.SetId("")
+		return nil
+	}
+
+	// NOTE: If another version has since been activated (e.g. via the UI) the
+	// version is updated so that Terraform plans to activate it again.
+	if err := d.Set("version", activeServiceVersion(s, environment)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+func resourceFastlyServiceVersionActivationUpdate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	if d.HasChange("version") {
+		diags := activateServiceVersion(ctx, d, meta)
+		if diags.HasError() {
+			return diags
+		}
+		return append(diags, resourceFastlyServiceVersionActivationRead(ctx, d, meta)...)
+	}
+	return resourceFastlyServiceVersionActivationRead(ctx, d, meta)
+}
+
+func resourceFastlyServiceVersionActivationDelete(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	conn := meta.(*APIClient).conn
+
+	serviceID := d.Get("service_id").(string)
+	environment := d.Get("environment").(string)
+	version := d.Get("version").(int)
+	previous := d.Get("previous_version").(int)
+
+	if !d.Get("rollback_on_destroy").(bool) {
+		log.Printf("[INFO] Leaving Fastly Service (%s), Version (%d) active in %s", serviceID, version, environment)
+		return nil
+	}
+	if previous == 0 {
+		log.Printf("[WARN] No version of Fastly Service (%s) was active in %s before version %d, nothing to roll back to", serviceID, environment, version)
+		return nil
+	}
+
+	s, err := conn.GetServiceDetails(gofastly.NewContextForResourceID(ctx, serviceID), &gofastly.GetServiceInput{
+		ServiceID: serviceID,
+	})
+	if err != nil {
+		if e, ok := err.(*gofastly.HTTPError); ok && e.IsNotFound() {
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+
+	// Only roll back if the version activated by this resource is still
+	// active, so that a newer activation isn't reverted.
+	if active := activeServiceVersion(s, environment); active != version {
+		log.Printf("[INFO] Fastly Service (%s), Version (%d) is active in %s instead of version %d, skipping rollback", serviceID, active, environment, version)
+		return nil
+	}
+
+	log.Printf("[DEBUG] Rolling back Fastly Service (%s) in %s from version %d to version %d", serviceID, environment, version, previous)
+	_, err = conn.ActivateVersion(gofastly.NewContextForResourceID(ctx, serviceID), &gofastly.ActivateVersionInput{
+		Environment:    activationEnvironment(environment),
+		ServiceID:      serviceID,
+		ServiceVersion: previous,
+	})
+	if err != nil {
+		return diag.Errorf("error rolling back to version (%d): %s", previous, err)
+	}
+
+	return nil
+}
+
+func resourceFastlyServiceVersionActivationImport(_ context.Context, d *schema.ResourceData, _ any) ([]*schema.ResourceData, error) {
+	serviceID, environment, err := parseServiceVersionActivationID(d.Id())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := d.Set("service_id", serviceID); err != nil {
+		return nil, err
+	}
+	if err := d.Set("environment", environment); err != nil {
+		return nil, err
+	}
+	if err := d.Set("rollback_on_destroy", false); err != nil {
+		return nil, err
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// activateServiceVersion activates the configured version, recording the
+// previously active version so that it can be restored on destroy.
+//
+// If the version is already active nothing is activated, so there is no new
+// version to roll back to: a new resource records 0, and an existing one
+// keeps the version it recorded when it last activated a version.
+func activateServiceVersion(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	conn := meta.(*APIClient).conn
+
+	serviceID := d.Get("service_id").(string)
+	environment := d.Get("environment").(string)
+	version := d.Get("version").(int)
+
+	s, err := conn.GetServiceDetails(gofastly.NewContextForResourceID(ctx, serviceID), &gofastly.GetServiceInput{
+		ServiceID: serviceID,
+	})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	previous := activeServiceVersion(s, environment)
+	if previous == version {
+		log.Printf("[INFO] Fastly Service (%s), Version (%d) is already active in %s", serviceID, version, environment)
+		if d.Id() == "" {
+			if err := d.Set("previous_version", 0); err != nil {
+				return diag.FromErr(err)
+			}
+		}
+		return nil
+	}
+
+	log.Printf("[DEBUG] Activating Fastly Service (%s), Version (%d) in %s", serviceID, version, environment)
+	_, err = conn.ActivateVersion(gofastly.NewContextForResourceID(ctx, serviceID), &gofastly.ActivateVersionInput{
+		Environment:    activationEnvironment(environment),
+		ServiceID:      serviceID,
+		ServiceVersion: version,
+	})
+	if err != nil {
+		return diag.Errorf("error activating version (%d): %s", version, err)
+	}
+
+	if err := d.Set("previous_version", previous); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if v := d.Get("wait_for_propagation").(string); v != "" {
+		wait, _ := time.ParseDuration(v)
+		log.Printf("[DEBUG] Waiting %s for Fastly Service (%s), Version (%d) to propagate", wait, serviceID, version)
+		if err := sleepContext(ctx, wait); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	return nil
+}
+
+// activeServiceVersion returns the version of the service that is active in
+// the given environment, or 0 if there is none.
+func activeServiceVersion(s *gofastly.ServiceDetail, environment string) int {
+	if environment == serviceEnvironmentStaging {
+		for _, e := range s.Environments {
+			if e.Name != nil && *e.Name == serviceEnvironmentStaging && e.ServiceVersion != nil {
+				return int(*e.ServiceVersion)
+			}
+		}
+		return 0
+	}
+
+	if s.ActiveVersion != nil && s.ActiveVersion.Number != nil {
+		return *s.ActiveVersion.Number
+	}
+	return 0
+}
+
+// activationEnvironment returns the Environment expected by the activation
+// API, which is empty for production.
+func activationEnvironment(environment string) string {
+	if environment == serviceEnvironmentProduction {
+		return ""
+	}
+	return environment
+}
+
+// parseServiceVersionActivationID splits an ID in the format
+// [service_id]/[environment].
+func parseServiceVersionActivationID(id string) (serviceID, environment string, err error) {
+	split := strings.SplitN(id, "/", 2)
+
+	if len(split) != 2 || split[0] == "" {
+		return "", "", fmt.Errorf("invalid id: %s. The ID should be in the format [service_id]/[environment]", id)
+	}
+	if split[1] != serviceEnvironmentProduction && split[1] != serviceEnvironmentStaging {
+		return "", "", fmt.Errorf("invalid environment: %s. The environment should be one of %s or %s", split[1], serviceEnvironmentProduction, serviceEnvironmentStaging)
+	}
+
+	return split[0], split[1], nil
+}
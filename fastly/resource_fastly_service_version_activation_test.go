@@ -0,0 +1,197 @@
+package fastly
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+
+	gofastly "github.com/fastly/go-fastly/v12/fastly"
+)
+
+func TestActiveServiceVersion(t *testing.T) {
+	s := &gofastly.ServiceDetail{
+		ActiveVersion: &gofastly.Version{
+			Number: gofastly.ToPointer(3),
+		},
+		Environments: []*gofastly.Environment{
+			{
+				Name:           gofastly.ToPointer("staging"),
+				ServiceVersion: gofastly.ToPointer(int64(4)),
+			},
+		},
+	}
+
+	if got := activeServiceVersion(s, serviceEnvironmentProduction); got != 3 {
+		t.Errorf("expected production version 3, got %d", got)
+	}
+	if got := activeServiceVersion(s, serviceEnvironmentStaging); got != 4 {
+		t.Errorf("expected staging version 4, got %d", got)
+	}
+	if got := activeServiceVersion(&gofastly.ServiceDetail{}, serviceEnvironmentProduction); got != 0 {
+		t.Errorf("expected no active version, got %d", got)
+	}
+	if got := activeServiceVersion(&gofastly.ServiceDetail{}, serviceEnvironmentStaging); got != 0 {
+		t.Errorf("expected no staged version, got %d", got)
+	}
+}
+
+func TestActivateServiceVersion_alreadyActive(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/service/123/details" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id":"123","active_version":{"number":2}}`)
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := gofastly.NewClientForEndpoint("someapikey", server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	meta := &APIClient{conn: client}
+	r := resourceFastlyServiceVersionActivation()
+
+	// A new resource didn't activate anything, so it has nothing to roll back.
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]any{
+		"service_id": "123",
+		"version":    2,
+	})
+	if diags := activateServiceVersion(context.Background(), d, meta); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if got := d.Get("previous_version").(int); got != 0 {
+		t.Errorf("expected previous_version 0 for a new resource, got %d", got)
+	}
+
+	// An existing resource keeps the version it recorded.
+	d.SetId("123/production")
+	if err := d.Set("previous_version", 1); err != nil {
+		t.Fatal(err)
+	}
+	if diags := activateServiceVersion(context.Background(), d, meta); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if got := d.Get("previous_version").(int); got != 1 {
+		t.Errorf("expected previous_version 1 to be kept, got %d", got)
+	}
+}
+
+func TestParseServiceVersionActivationID(t *testing.T) {
+	serviceID, environment, err := parseServiceVersionActivationID("abc123/staging")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if serviceID != "abc123" || environment != "staging" {
+		t.Fatalf("unexpected result: %s, %s", serviceID, environment)
+	}
+
+	for _, id := range []string{"abc123", "/production", "abc123/testing"} {
+		if _, _, err := parseServiceVersionActivationID(id); err == nil {
+			t.Errorf("expected an error for ID %q", id)
+		}
+	}
+}
+
+func TestAccFastlyServiceVersionActivation_basic(t *testing.T) {
+	name := fmt.Sprintf("tf-test-%s", acctest.RandString(10))
+	domain := fmt.Sprintf("fastly-test.%s.com", name)
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      testAccCheckServiceVCLDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccServiceVersionActivationConfig(name, domain, "amazon docs"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("fastly_service_version_activation.example", "version", "1"),
+					resource.TestCheckResourceAttr("fastly_service_version_activation.example", "previous_version", "0"),
+					testAccCheckServiceVersionActive("fastly_service_vcl.example", 1),
+				),
+			},
+			{
+				Config: testAccServiceVersionActivationConfig(name, domain, "updated docs"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("fastly_service_version_activation.example", "version", "2"),
+					resource.TestCheckResourceAttr("fastly_service_version_activation.example", "previous_version", "1"),
+					testAccCheckServiceVersionActive("fastly_service_vcl.example", 2),
+				),
+			},
+			{
+				ResourceName:            "fastly_service_version_activation.example",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"previous_version", "rollback_on_destroy", "wait_for_propagation"},
+			},
+			{
+				// Removing the activation rolls back to the previous version.
+				Config: testAccServiceVersionActivationServiceConfig(name, domain, "updated docs"),
+				Check:  testAccCheckServiceVersionActive("fastly_service_vcl.example", 1),
+			},
+		},
+	})
+}
+
+func testAccCheckServiceVersionActive(n string, want int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("not found: %s", n)
+		}
+
+		conn := testAccProvider.Meta().(*APIClient).conn
+		service, err := conn.GetServiceDetails(context.TODO(), &gofastly.GetServiceInput{
+			ServiceID: rs.Primary.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		if got := activeServiceVersion(service, serviceEnvironmentProduction); got != want {
+			return fmt.Errorf("expected active version %d, got %d", want, got)
+		}
+		return nil
+	}
+}
+
+func testAccServiceVersionActivationServiceConfig(name, domain, backendName string) string {
+	return fmt.Sprintf(`
+resource "fastly_service_vcl" "example" {
+  name     = "%s"
+  activate = false
+
+  domain {
+    name = "%s"
+  }
+
+  backend {
+    address = "aws.amazon.com"
+    name    = "%s"
+  }
+
+  force_destroy = true
+}
+`, name, domain, backendName)
+}
+
+func testAccServiceVersionActivationConfig(name, domain, backendName string) string {
+	return testAccServiceVersionActivationServiceConfig(name, domain, backendName) + `
+resource "fastly_service_version_activation" "example" {
+  service_id          = fastly_service_vcl.example.id
+  version             = fastly_service_vcl.example.cloned_version
+  rollback_on_destroy = true
+}
+`
+}
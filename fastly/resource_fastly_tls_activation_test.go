@@ -235,11 +235,10 @@ func testAccFastlyTLSActivationCheckDestroy(state *terraform.State) error {
 }
 
 func testSweepTLSActivation(region string) error {
-	client, diagnostics := sharedClientForRegion(region)
-	if diagnostics.HasError() {
-		return diagToErr(diagnostics)
-	}
+	return sweepAccounts(region, sweepTLSActivation)
+}
 
+func sweepTLSActivation(client *fastly.Client) error {
 	activations, err := client.ListTLSActivations(context.TODO(), &fastly.ListTLSActivationsInput{PageSize: 1000})
 	if err != nil {
 		return err
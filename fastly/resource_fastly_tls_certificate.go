@@ -9,6 +9,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 
 	"github.com/fastly/go-fastly/v12/fastly"
 )
@@ -20,7 +21,12 @@ func resourceFastlyTLSCertificate() *schema.Resource {
 		UpdateContext: resourceFastlyTLSCertificateUpdate,
 		DeleteContext: resourceFastlyTLSCertificateDelete,
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: resourceFastlyTLSCertificateImport,
+		},
+		CustomizeDiff: customizeDiffTLSCertificateDetails,
+		ValidateRawResourceConfigFuncs: []schema.ValidateRawResourceConfigFunc{
+			validateTLSCertificateConsistency,
+			validateTLSCertificateExpiry,
 		},
 		Schema: map[string]*schema.Schema{
 			"certificate_body": {
@@ -29,6 +35,11 @@ func resourceFastlyTLSCertificate() *schema.Resource {
 				Required:         true,
 				ValidateDiagFunc: validatePEMBlocks("CERTIFICATE"),
 			},
+			"chain_fingerprint": {
+				Type:        schema.TypeString,
+				Description: "The SHA-256 fingerprint of the certificate chain in `certificate_body`: the hex-encoded SHA-256 digest of its DER-encoded certificates, in order. It changes whenever the leaf or an intermediate certificate does.",
+				Computed:    true,
+			},
 			"created_at": {
 				Type:        schema.TypeString,
 				Description: "Timestamp (GMT) when the certificate was created.",
@@ -36,10 +47,30 @@ func resourceFastlyTLSCertificate() *schema.Resource {
 			},
 			"domains": {
 				Type:        schema.TypeSet,
-				Description: "All the domains (including wildcard domains) that are listed in the certificate's Subject Alternative Names (SAN) list.",
+				Description: "All the domains (including wildcard domains) that are listed in the certificate's Subject Alternative Names (SAN) list. When set, the plan fails unless they match the SAN list of `certificate_body`.",
+				Optional:    true,
 				Computed:    true,
 				Elem:        &schema.Schema{Type: schema.TypeString},
 			},
+			"expected_public_key_sha256": {
+				Type:        schema.TypeString,
+				Description: "The `public_key_sha256` of the `fastly_tls_private_key` the certificate was issued for. When set, the plan fails unless it matches the public key of `certificate_body`.",
+				Optional:    true,
+			},
+			"expiry_warning_days": {
+				Type:             schema.TypeInt,
+				Description:      "Report a diagnostic at plan time when the certificate in `certificate_body` has expired or expires within this number of days. `0` disables the check. Default `0`.",
+				Optional:         true,
+				Default:          0,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(0)),
+			},
+			"expiry_warning_severity": {
+				Type:             schema.TypeString,
+				Description:      "The severity of the `expiry_warning_days` diagnostic: `warning`, or `error` to fail the plan until a renewed certificate is uploaded. Default `warning`.",
+				Optional:         true,
+				Default:          tlsCertificateExpirySeverityWarning,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice([]string{tlsCertificateExpirySeverityWarning, tlsCertificateExpirySeverityError}, false)),
+			},
 			"issued_to": {
 				Type:        schema.TypeString,
 				Description: "The hostname for which a certificate was issued.",
@@ -50,12 +81,32 @@ func resourceFastlyTLSCertificate() *schema.Resource {
 				Description: "The certificate authority that issued the certificate.",
 				Computed:    true,
 			},
+			"key_algorithm": {
+				Type:        schema.TypeString,
+				Description: "The algorithm and size of the certificate's public key, e.g. `RSA-2048` or `ECDSA-P256`.",
+				Computed:    true,
+			},
 			"name": {
 				Type:        schema.TypeString,
 				Description: "Human-readable name used to identify the certificate. Defaults to the certificate's Common Name or first Subject Alternative Name entry.",
 				Optional:    true,
 				Computed:    true,
 			},
+			"not_after": {
+				Type:        schema.TypeString,
+				Description: "Timestamp (GMT) when the certificate will expire.",
+				Computed:    true,
+			},
+			"not_before": {
+				Type:        schema.TypeString,
+				Description: "Timestamp (GMT) when the certificate will become valid.",
+				Computed:    true,
+			},
+			"public_key_sha256": {
+				Type:        schema.TypeString,
+				Description: "The hex-encoded SHA-256 digest of the DER-encoded public key of the certificate in `certificate_body`.",
+				Computed:    true,
+			},
 			"replace": {
 				Type:        schema.TypeBool,
 				Description: "A recommendation from Fastly indicating the key associated with this certificate is in need of rotation.",
@@ -71,6 +122,12 @@ func resourceFastlyTLSCertificate() *schema.Resource {
 				Description: "The algorithm used to sign the certificate.",
 				Computed:    true,
 			},
+			"subject_alternative_names": {
+				Type:        schema.TypeList,
+				Description: "The DNS names and IP addresses in the Subject Alternative Names (SAN) list of the certificate in `certificate_body`, in order.",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
 			"updated_at": {
 				Type:        schema.TypeString,
 				Description: "Timestamp (GMT) when the certificate was last updated.",
@@ -155,10 +212,50 @@ func resourceFastlyTLSCertificateRead(ctx context.Context, d *schema.ResourceDat
 		return diag.FromErr(err)
 	}
 
+	// The certificate body isn't returned by the API, so its details are
+	// parsed from the configured body. Imported certificates only have the
+	// validity dates until the body is configured.
+	if body := d.Get("certificate_body").(string); body != "" {
+		details, err := parseTLSCertificateDetails(body)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if err := setTLSCertificateDetails(details, d.Set); err != nil {
+			return diag.FromErr(err)
+		}
+	} else {
+		if cert.NotAfter != nil {
+			if err := d.Set("not_after", cert.NotAfter.Format(time.RFC3339)); err != nil {
+				return diag.FromErr(err)
+			}
+		}
+		if cert.NotBefore != nil {
+			if err := d.Set("not_before", cert.NotBefore.Format(time.RFC3339)); err != nil {
+				return diag.FromErr(err)
+			}
+		}
+	}
+
 	return diags
 }
 
+func resourceFastlyTLSCertificateImport(_ context.Context, d *schema.ResourceData, _ any) ([]*schema.ResourceData, error) {
+	if err := d.Set("expiry_warning_days", 0); err != nil {
+		return nil, err
+	}
+	if err := d.Set("expiry_warning_severity", tlsCertificateExpirySeverityWarning); err != nil {
+		return nil, err
+	}
+	return []*schema.ResourceData{d}, nil
+}
+
 func resourceFastlyTLSCertificateUpdate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	// NOTE: The other arguments only affect the plan, and updating the
+	// certificate uploads it again.
+	if !d.HasChanges("certificate_body", "name") {
+		return resourceFastlyTLSCertificateRead(ctx, d, meta)
+	}
+
 	conn := meta.(*APIClient).conn
 
 	input := &fastly.UpdateCustomTLSCertificateInput{
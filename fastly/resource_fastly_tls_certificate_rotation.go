@@ -0,0 +1,575 @@
+package fastly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	gofastly "github.com/fastly/go-fastly/v12/fastly"
+)
+
+const (
+	tlsRotationStatusFailed     = "failed"
+	tlsRotationStatusRolledBack = "rolled_back"
+	tlsRotationStatusSucceeded  = "succeeded"
+
+	// tlsRotationRollbackTimeout bounds the requests made to roll back a
+	// rotation, which still run once the rotation itself has timed out.
+	tlsRotationRollbackTimeout = 2 * time.Minute
+)
+
+func resourceFastlyTLSCertificateRotation() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceFastlyTLSCertificateRotationCreate,
+		ReadContext:   resourceFastlyTLSCertificateRotationRead,
+		UpdateContext: resourceFastlyTLSCertificateRotationUpdate,
+		DeleteContext: resourceFastlyTLSCertificateRotationDelete,
+		ValidateRawResourceConfigFuncs: []schema.ValidateRawResourceConfigFunc{
+			validateTLSCertificateRotationConsistency,
+		},
+		Schema: map[string]*schema.Schema{
+			"activation_ids": {
+				Type:        schema.TypeSet,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The IDs of the TLS activations that use the current certificate.",
+			},
+			"certificate_body": {
+				Type:             schema.TypeString,
+				Required:         true,
+				Description:      "PEM-formatted certificate, optionally including any intermediary certificates. Changing it starts a new rotation.",
+				ValidateDiagFunc: validateStringTrimmed,
+			},
+			"certificate_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The ID of the current certificate. Reference it in the `certificate_id` of the `fastly_tls_activation` resources of the covered domains.",
+			},
+			"domains": {
+				Type:        schema.TypeSet,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The domains covered by the current certificate. The activations of these domains are switched to the certificate by each rotation.",
+			},
+			"key_pem": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Private key of the certificate, in PEM format. It is uploaded by a rotation when it changes. Changing it starts a new rotation.",
+				Sensitive:   !DisplaySensitiveFields,
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The name of the certificates and private keys uploaded by the rotations. Changing it only affects the next rotation.",
+			},
+			"private_key_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The ID of the private key of the current certificate.",
+			},
+			"status": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The outcome of the last rotation. One of `succeeded`, `failed` (a step failed and the rollback failed too) or `rolled_back` (a step failed and the activations were switched back to the previous certificate).",
+			},
+			"steps": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The steps of the last rotation, in order.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"finished_at": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "When the step finished (RFC 3339). Empty if the step was skipped.",
+						},
+						"message": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The error of a failed step.",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The name of the step. One of `check_domains`, `upload_key`, `upload_certificate`, `switch_activations`, `verify_activations`, `delete_previous` or `rollback`.",
+						},
+						"started_at": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "When the step started (RFC 3339). Empty if the step was skipped.",
+						},
+						"status": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The outcome of the step. One of `passed`, `failed` or `skipped`.",
+						},
+					},
+				},
+			},
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
+		},
+	}
+}
+
+func resourceFastlyTLSCertificateRotationCreate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	diags := rotateTLSCertificate(ctx, d, meta)
+	if diags.HasError() {
+		return diags
+	}
+	return append(diags, resourceFastlyTLSCertificateRotationRead(ctx, d, meta)...)
+}
+
+func resourceFastlyTLSCertificateRotationRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	log.Printf("[DEBUG] Refreshing TLS Certificate Rotation for (%s)", d.Id())
+
+	conn := meta.(*APIClient).conn
+
+	certificateID := d.Get("certificate_id").(string)
+	certificate, err := conn.GetCustomTLSCertificate(ctx, &gofastly.GetCustomTLSCertificateInput{
+		ID: certificateID,
+	})
+	if err, ok := err.(*gofastly.HTTPError); ok && err.IsNotFound() {
+		id := d.Id()
+		d.SetId("")
+		return diag.Diagnostics{
+			diag.Diagnostic{
+				Severity:      diag.Warning,
+				Summary:       fmt.Sprintf("TLS certificate (%s) of the rotation not found - removing from state", certificateID),
+				AttributePath: cty.Path{cty.GetAttrStep{Name: id}},
+			},
+		}
+	} else if err != nil {
+		return diag.FromErr(err)
+	}
+
+	var domains []string
+	for _, domain := range certificate.Domains {
+		domains = append(domains, domain.ID)
+	}
+	err = d.Set("domains", domains)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	activations, err := listTLSActivations(ctx, conn, tlsActivationUsesCertificate(certificateID))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	var activationIDs []string
+	for _, activation := range activations {
+		activationIDs = append(activationIDs, activation.ID)
+	}
+	err = d.Set("activation_ids", activationIDs)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+func resourceFastlyTLSCertificateRotationUpdate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	if d.HasChanges("certificate_body", "key_pem") {
+		diags := rotateTLSCertificate(ctx, d, meta)
+		if diags.HasError() {
+			return diags
+		}
+		return append(diags, resourceFastlyTLSCertificateRotationRead(ctx, d, meta)...)
+	}
+	return resourceFastlyTLSCertificateRotationRead(ctx, d, meta)
+}
+
+func resourceFastlyTLSCertificateRotationDelete(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	conn := meta.(*APIClient).conn
+
+	certificateID := d.Get("certificate_id").(string)
+	activations, err := listTLSActivations(ctx, conn, tlsActivationUsesCertificate(certificateID))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if len(activations) > 0 {
+		return diag.Errorf("TLS certificate (%s) is still used by TLS activations %s: delete them or switch them to another certificate first", certificateID, strings.Join(tlsActivationIDs(activations), ", "))
+	}
+
+	var httpErr *gofastly.HTTPError
+	err = conn.DeleteCustomTLSCertificate(ctx, &gofastly.DeleteCustomTLSCertificateInput{
+		ID: certificateID,
+	})
+	if err != nil && (!errors.As(err, &httpErr) || !httpErr.IsNotFound()) {
+		return diag.FromErr(err)
+	}
+
+	err = conn.DeletePrivateKey(ctx, &gofastly.DeletePrivateKeyInput{
+		ID: d.Get("private_key_id").(string),
+	})
+	if err != nil && (!errors.As(err, &httpErr) || !httpErr.IsNotFound()) {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+// tlsActivationUsesCertificate returns a TLSActivationPredicate selecting the
+// activations that use a certificate.
+func tlsActivationUsesCertificate(certificateID string) TLSActivationPredicate {
+	return func(activation *gofastly.TLSActivation) bool {
+		return activation.Certificate != nil && activation.Certificate.ID == certificateID
+	}
+}
+
+// tlsActivationIDs returns the sorted IDs of activations.
+func tlsActivationIDs(activations []*gofastly.TLSActivation) []string {
+	ids := make([]string, 0, len(activations))
+	for _, activation := range activations {
+		ids = append(ids, activation.ID)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// tlsActivationSwitch records an activation switched to a new certificate by
+// a rotation, so that it can be switched back.
+type tlsActivationSwitch struct {
+	Activation            *gofastly.TLSActivation
+	PreviousCertificateID string
+}
+
+// switchTLSActivation switches an activation to a certificate, keeping its
+// mutual authentication.
+func switchTLSActivation(ctx context.Context, conn *gofastly.Client, activation *gofastly.TLSActivation, certificateID string) error {
+	input := &gofastly.UpdateTLSActivationInput{
+		ID:          activation.ID,
+		Certificate: &gofastly.CustomTLSCertificate{ID: certificateID},
+	}
+	if activation.MutualAuthentication != nil && activation.MutualAuthentication.ID != "" {
+		input.MutualAuthentication = &gofastly.TLSMutualAuthentication{ID: activation.MutualAuthentication.ID}
+	}
+
+	log.Printf("[DEBUG] Switching TLS Activation (%s) to TLS Certificate (%s)", activation.ID, certificateID)
+	_, err := conn.UpdateTLSActivation(ctx, input)
+	if err != nil {
+		return fmt.Errorf("error switching TLS activation (%s) to TLS certificate (%s): %w", activation.ID, certificateID, err)
+	}
+	return nil
+}
+
+// rotateTLSCertificate checks that the configured certificate covers the
+// domains of the previous one, uploads the configured key, if it changed, and
+// certificate, switches the activations of the domains covered by the
+// certificate to it and verifies them. Only then are the previous
+// certificate and key deleted. When a step fails, the activations are
+// switched back and the uploaded certificate and key are deleted. The steps
+// and outcome are recorded in state either way.
+func rotateTLSCertificate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	conn := meta.(*APIClient).conn
+
+	name := d.Get("name").(string)
+	previousCertificateID := d.Get("certificate_id").(string)
+	previousKeyID := d.Get("private_key_id").(string)
+	uploadKey := previousKeyID == "" || d.HasChange("key_pem")
+
+	keyID := previousKeyID
+	var (
+		certificate *gofastly.CustomTLSCertificate
+		switched    []tlsActivationSwitch
+	)
+
+	var steps []rolloutStep
+	if previousCertificateID != "" {
+		steps = append(steps, rolloutStep{
+			Name: "check_domains",
+			Run: func(_ context.Context) error {
+				return checkTLSCertificateDomains(d.Get("certificate_body").(string), buildStringSlice(d.Get("domains").(*schema.Set)))
+			},
+		})
+	}
+	if uploadKey {
+		steps = append(steps, rolloutStep{
+			Name: "upload_key",
+			Run: func(ctx context.Context) error {
+				key, err := conn.CreatePrivateKey(ctx, &gofastly.CreatePrivateKeyInput{
+					Key:  d.Get("key_pem").(string),
+					Name: name,
+				})
+				if err != nil {
+					return fmt.Errorf("error uploading private key: %w", err)
+				}
+				keyID = key.ID
+				return nil
+			},
+		})
+	}
+	steps = append(steps,
+		rolloutStep{
+			Name: "upload_certificate",
+			Run: func(ctx context.Context) error {
+				var err error
+				certificate, err = conn.CreateCustomTLSCertificate(ctx, &gofastly.CreateCustomTLSCertificateInput{
+					CertBlob: d.Get("certificate_body").(string),
+					Name:     name,
+				})
+				if err != nil {
+					return fmt.Errorf("error uploading certificate: %w", err)
+				}
+				// The ID is the ID of the first certificate, and doesn't
+				// change with the rotations. It is set as soon as the
+				// certificate is uploaded, so that it is tracked if the
+				// creation fails and can't be rolled back.
+				if d.Id() == "" {
+					d.SetId(certificate.ID)
+				}
+				return nil
+			},
+		},
+		rolloutStep{
+			Name: "switch_activations",
+			Run: func(ctx context.Context) error {
+				covered := make(map[string]bool, len(certificate.Domains))
+				for _, domain := range certificate.Domains {
+					covered[domain.ID] = true
+				}
+				activations, err := listTLSActivations(ctx, conn, func(activation *gofastly.TLSActivation) bool {
+					return activation.Domain != nil && covered[activation.Domain.ID] && !tlsActivationUsesCertificate(certificate.ID)(activation)
+				})
+				if err != nil {
+					return fmt.Errorf("error listing TLS activations: %w", err)
+				}
+				for _, activation := range activations {
+					if err := switchTLSActivation(ctx, conn, activation, certificate.ID); err != nil {
+						return err
+					}
+					s := tlsActivationSwitch{Activation: activation}
+					if activation.Certificate != nil {
+						s.PreviousCertificateID = activation.Certificate.ID
+					}
+					switched = append(switched, s)
+				}
+				return nil
+			},
+		},
+		rolloutStep{
+			Name: "verify_activations",
+			Run: func(ctx context.Context) error {
+				for _, s := range switched {
+					activation, err := conn.GetTLSActivation(ctx, &gofastly.GetTLSActivationInput{
+						ID: s.Activation.ID,
+					})
+					if err != nil {
+						return fmt.Errorf("error verifying TLS activation (%s): %w", s.Activation.ID, err)
+					}
+					if !tlsActivationUsesCertificate(certificate.ID)(activation) {
+						return fmt.Errorf("TLS activation (%s) doesn't use TLS certificate (%s)", s.Activation.ID, certificate.ID)
+					}
+				}
+				return nil
+			},
+		},
+	)
+
+	results, failed, rotationErr := runRolloutSteps(ctx, steps)
+
+	if rotationErr == nil {
+		deleteResults, _, deleteErr := runRolloutSteps(ctx, []rolloutStep{{
+			Name: "delete_previous",
+			Run: func(ctx context.Context) error {
+				return deletePreviousTLSCertificate(ctx, conn, previousCertificateID, previousKeyID, uploadKey)
+			},
+		}})
+		results = append(results, deleteResults...)
+
+		for key, value := range map[string]any{
+			"certificate_id": certificate.ID,
+			"private_key_id": keyID,
+			"status":         tlsRotationStatusSucceeded,
+			"steps":          flattenRolloutSteps(results),
+		} {
+			if err := d.Set(key, value); err != nil {
+				return diag.FromErr(err)
+			}
+		}
+
+		if deleteErr != nil {
+			return diag.Diagnostics{{
+				Severity: diag.Warning,
+				Summary:  "Previous certificate not deleted",
+				Detail:   fmt.Sprintf("The activations were switched to TLS certificate (%s), but the previous certificate or private key could not be deleted: %s", certificate.ID, deleteErr),
+			}}
+		}
+		return nil
+	}
+
+	// The rotation may have failed because it timed out, so it is rolled back
+	// with a context of its own.
+	rollbackCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), tlsRotationRollbackTimeout)
+	defer cancel()
+
+	rollbackResults, _, rollbackErr := runRolloutSteps(rollbackCtx, []rolloutStep{{
+		Name: "rollback",
+		Run: func(ctx context.Context) error {
+			var uploadedKeyID string
+			if keyID != previousKeyID {
+				uploadedKeyID = keyID
+			}
+			return rollbackTLSCertificateRotation(ctx, conn, switched, certificate, uploadedKeyID)
+		},
+	}})
+	results = append(results, rollbackResults...)
+
+	status := tlsRotationStatusRolledBack
+	if rollbackErr != nil {
+		status = tlsRotationStatusFailed
+	}
+	if err := d.Set("status", status); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("steps", flattenRolloutSteps(results)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if previousCertificateID == "" {
+		// NOTE: When the creation is rolled back, nothing is left to track.
+		// Otherwise the uploaded certificate and key are tracked by the
+		// tainted resource, so that they are deleted with it.
+		if rollbackErr == nil {
+			d.SetId("")
+		} else if certificate != nil {
+			if err := d.Set("certificate_id", certificate.ID); err != nil {
+				return diag.FromErr(err)
+			}
+			if err := d.Set("private_key_id", keyID); err != nil {
+				return diag.FromErr(err)
+			}
+		}
+	}
+
+	// Record the certificate and key that are still in use, so that the next
+	// plan rotates them again.
+	for _, key := range []string{"certificate_body", "key_pem"} {
+		previous, _ := d.GetChange(key)
+		if err := d.Set(key, previous); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	step := results[failed].Name
+	if rollbackErr != nil {
+		return diag.Diagnostics{{
+			Severity: diag.Error,
+			Summary:  "Certificate rotation failed and rollback failed",
+			Detail:   fmt.Sprintf("Step %s of the rotation failed: %s\n\nError rolling back: %s", step, rotationErr, rollbackErr),
+		}}
+	}
+	return diag.Diagnostics{{
+		Severity: diag.Error,
+		Summary:  "Certificate rotation rolled back",
+		Detail:   fmt.Sprintf("Step %s of the rotation failed and the activations have been switched back to their previous certificates: %s", step, rotationErr),
+	}}
+}
+
+// rollbackTLSCertificateRotation switches the activations back to their
+// previous certificates and deletes the certificate and key uploaded by a
+// rotation, if any.
+func rollbackTLSCertificateRotation(ctx context.Context, conn *gofastly.Client, switched []tlsActivationSwitch, certificate *gofastly.CustomTLSCertificate, uploadedKeyID string) error {
+	var errs []error
+	for i := len(switched) - 1; i >= 0; i-- {
+		s := switched[i]
+		if s.PreviousCertificateID == "" {
+			continue
+		}
+		if err := switchTLSActivation(ctx, conn, s.Activation, s.PreviousCertificateID); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	// The uploaded certificate can't be deleted while activations use it.
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	if certificate != nil {
+		log.Printf("[DEBUG] Deleting TLS Certificate (%s) uploaded by the rotation", certificate.ID)
+		err := conn.DeleteCustomTLSCertificate(ctx, &gofastly.DeleteCustomTLSCertificateInput{
+			ID: certificate.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("error deleting TLS certificate (%s): %w", certificate.ID, err)
+		}
+	}
+	if uploadedKeyID != "" {
+		log.Printf("[DEBUG] Deleting TLS Private Key (%s) uploaded by the rotation", uploadedKeyID)
+		err := conn.DeletePrivateKey(ctx, &gofastly.DeletePrivateKeyInput{
+			ID: uploadedKeyID,
+		})
+		if err != nil {
+			return fmt.Errorf("error deleting private key (%s): %w", uploadedKeyID, err)
+		}
+	}
+	return nil
+}
+
+// checkTLSCertificateDomains returns an error if a certificate doesn't cover
+// each of the domains of the certificate it replaces, as their activations
+// would be left on the previous certificate.
+func checkTLSCertificateDomains(body string, previousDomains []string) error {
+	certs, err := parsePEMCertificates(body)
+	if err != nil {
+		return fmt.Errorf("error parsing certificate: %w", err)
+	}
+
+	var missing []string
+	for _, domain := range previousDomains {
+		if !tlsDomainsCover(certs[0].DNSNames, domain) {
+			missing = append(missing, domain)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("the certificate doesn't cover domains %s of the previous certificate", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// deletePreviousTLSCertificate deletes the certificate replaced by a
+// rotation, unless activations of domains it covers and the new certificate
+// doesn't still use it, and its private key, if it was replaced too.
+func deletePreviousTLSCertificate(ctx context.Context, conn *gofastly.Client, certificateID, keyID string, keyReplaced bool) error {
+	if certificateID == "" {
+		return nil
+	}
+
+	activations, err := listTLSActivations(ctx, conn, tlsActivationUsesCertificate(certificateID))
+	if err != nil {
+		return fmt.Errorf("error listing TLS activations: %w", err)
+	}
+	if len(activations) > 0 {
+		return fmt.Errorf("TLS certificate (%s) is still used by TLS activations %s of domains the new certificate doesn't cover", certificateID, strings.Join(tlsActivationIDs(activations), ", "))
+	}
+
+	log.Printf("[DEBUG] Deleting previous TLS Certificate (%s)", certificateID)
+	err = conn.DeleteCustomTLSCertificate(ctx, &gofastly.DeleteCustomTLSCertificateInput{
+		ID: certificateID,
+	})
+	if err != nil {
+		return fmt.Errorf("error deleting TLS certificate (%s): %w", certificateID, err)
+	}
+
+	if keyReplaced && keyID != "" {
+		log.Printf("[DEBUG] Deleting previous TLS Private Key (%s)", keyID)
+		err = conn.DeletePrivateKey(ctx, &gofastly.DeletePrivateKeyInput{
+			ID: keyID,
+		})
+		if err != nil {
+			return fmt.Errorf("error deleting private key (%s): %w", keyID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,453 @@
+package fastly
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/require"
+
+	gofastly "github.com/fastly/go-fastly/v12/fastly"
+)
+
+// testTLSServer is a fake Fastly TLS API, with activations mapping activation
+// IDs to the domain and certificate ID they use.
+type testTLSServer struct {
+	mu          sync.Mutex
+	activations map[string][2]string
+	// certificateDomains are the domains of the uploaded certificates.
+	certificateDomains []string
+	// failSwitch makes switching this activation to a new certificate fail.
+	failSwitch string
+	// failDelete makes deleting this certificate or key fail.
+	failDelete string
+	// deleted records the deleted certificates and keys, in order.
+	deleted []string
+}
+
+func (s *testTLSServer) client(t *testing.T) *gofastly.Client {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	t.Cleanup(server.Close)
+
+	client, err := gofastly.NewClientForEndpoint("someapikey", server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func (s *testTLSServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	activation := func(id string) string {
+		a := s.activations[id]
+		return fmt.Sprintf(`{"id":%q,"type":"tls_activation","relationships":{"tls_certificate":{"data":{"id":%q,"type":"tls_certificate"}},"tls_domain":{"data":{"id":%q,"type":"tls_domain"}}}}`, id, a[1], a[0])
+	}
+
+	switch path := r.URL.Path; {
+	case r.Method == http.MethodPost && path == "/tls/private_keys":
+		fmt.Fprint(w, `{"data":{"id":"key-new","type":"tls_private_key"}}`)
+	case r.Method == http.MethodPost && path == "/tls/certificates":
+		var domains []string
+		for _, domain := range s.certificateDomains {
+			domains = append(domains, fmt.Sprintf(`{"id":%q,"type":"tls_domain"}`, domain))
+		}
+		fmt.Fprintf(w, `{"data":{"id":"cert-new","type":"tls_certificate","relationships":{"tls_domains":{"data":[%s]}}}}`, strings.Join(domains, ","))
+	case r.Method == http.MethodGet && path == "/tls/activations":
+		var ids []string
+		if r.URL.Query().Get("page[number]") == "1" {
+			for id := range s.activations {
+				ids = append(ids, id)
+			}
+			sort.Strings(ids)
+		}
+		var data []string
+		for _, id := range ids {
+			data = append(data, activation(id))
+		}
+		fmt.Fprintf(w, `{"data":[%s]}`, strings.Join(data, ","))
+	case strings.HasPrefix(path, "/tls/activations/"):
+		id := strings.TrimPrefix(path, "/tls/activations/")
+		if r.Method == http.MethodPatch {
+			var body struct {
+				Data struct {
+					Relationships struct {
+						Certificate struct {
+							Data struct {
+								ID string `json:"id"`
+							} `json:"data"`
+						} `json:"tls_certificate"`
+					} `json:"relationships"`
+				} `json:"data"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			certificateID := body.Data.Relationships.Certificate.Data.ID
+			if id == s.failSwitch && certificateID == "cert-new" {
+				w.WriteHeader(http.StatusInternalServerError)
+				fmt.Fprint(w, `{"errors":[{"title":"activation failed"}]}`)
+				return
+			}
+			s.activations[id] = [2]string{s.activations[id][0], certificateID}
+		}
+		fmt.Fprintf(w, `{"data":%s}`, activation(id))
+	case r.Method == http.MethodDelete && path == s.failDelete:
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, `{"errors":[{"title":"delete failed"}]}`)
+	case r.Method == http.MethodDelete:
+		s.deleted = append(s.deleted, path)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// testTLSCertificateRotationData returns the data of a rotation of the
+// previous certificate and key, covering domains, to a certificate covering
+// sans.
+func testTLSCertificateRotationData(t *testing.T, certificateID, keyID string, domains []string, sans ...string) *schema.ResourceData {
+	t.Helper()
+
+	key, cert, err := generateKeyAndCert(sans...)
+	require.NoError(t, err)
+	d := schema.TestResourceDataRaw(t, resourceFastlyTLSCertificateRotation().Schema, map[string]any{
+		"certificate_body": cert,
+		"key_pem":          key,
+		"name":             "example",
+	})
+	if certificateID != "" {
+		d.SetId(certificateID)
+	}
+	require.NoError(t, d.Set("certificate_id", certificateID))
+	require.NoError(t, d.Set("private_key_id", keyID))
+	require.NoError(t, d.Set("domains", domains))
+	return d
+}
+
+// testTLSCertificateRotationSteps returns the name and status of the steps of
+// the last rotation.
+func testTLSCertificateRotationSteps(d *schema.ResourceData) []string {
+	var steps []string
+	for _, step := range d.Get("steps").([]any) {
+		step := step.(map[string]any)
+		steps = append(steps, fmt.Sprintf("%s:%s", step["name"], step["status"]))
+	}
+	return steps
+}
+
+func TestRotateTLSCertificate(t *testing.T) {
+	s := &testTLSServer{
+		activations: map[string][2]string{
+			"act-a":     {"a.example.com", "cert-old"},
+			"act-b":     {"b.example.com", "cert-old"},
+			"act-other": {"other.example.com", "cert-other"},
+		},
+		certificateDomains: []string{"a.example.com", "b.example.com"},
+	}
+	d := testTLSCertificateRotationData(t, "cert-old", "key-old", []string{"a.example.com", "b.example.com"}, "*.example.com")
+
+	diags := rotateTLSCertificate(context.Background(), d, &APIClient{conn: s.client(t)})
+	require.False(t, diags.HasError(), "unexpected error: %v", diags)
+
+	want := map[string][2]string{
+		"act-a":     {"a.example.com", "cert-new"},
+		"act-b":     {"b.example.com", "cert-new"},
+		"act-other": {"other.example.com", "cert-other"},
+	}
+	if !reflect.DeepEqual(s.activations, want) {
+		t.Errorf("expected activations %v, got %v", want, s.activations)
+	}
+	if want := []string{"/tls/certificates/cert-old", "/tls/private_keys/key-old"}; !reflect.DeepEqual(s.deleted, want) {
+		t.Errorf("expected the previous certificate and key to be deleted, got %v", s.deleted)
+	}
+	if got := [3]string{d.Get("certificate_id").(string), d.Get("private_key_id").(string), d.Get("status").(string)}; got != [3]string{"cert-new", "key-new", tlsRotationStatusSucceeded} {
+		t.Errorf("unexpected state %v", got)
+	}
+}
+
+func TestRotateTLSCertificate_domainsNotCovered(t *testing.T) {
+	// The new certificate doesn't cover b.example.com, so nothing is uploaded
+	// or switched.
+	s := &testTLSServer{
+		activations: map[string][2]string{
+			"act-a": {"a.example.com", "cert-old"},
+			"act-b": {"b.example.com", "cert-old"},
+		},
+		certificateDomains: []string{"a.example.com"},
+	}
+	d := testTLSCertificateRotationData(t, "cert-old", "key-old", []string{"a.example.com", "b.example.com"}, "a.example.com")
+
+	diags := rotateTLSCertificate(context.Background(), d, &APIClient{conn: s.client(t)})
+	if !diags.HasError() || !strings.Contains(diags[0].Detail, "doesn't cover domains b.example.com") {
+		t.Fatalf("expected an error about b.example.com, got %v", diags)
+	}
+
+	want := map[string][2]string{
+		"act-a": {"a.example.com", "cert-old"},
+		"act-b": {"b.example.com", "cert-old"},
+	}
+	if !reflect.DeepEqual(s.activations, want) {
+		t.Errorf("expected activations %v, got %v", want, s.activations)
+	}
+	if len(s.deleted) != 0 {
+		t.Errorf("expected nothing to be deleted, got %v", s.deleted)
+	}
+	if want := []string{"check_domains:failed", "upload_key:skipped", "upload_certificate:skipped", "switch_activations:skipped", "verify_activations:skipped", "rollback:passed"}; !reflect.DeepEqual(testTLSCertificateRotationSteps(d), want) {
+		t.Errorf("expected steps %v, got %v", want, testTLSCertificateRotationSteps(d))
+	}
+}
+
+func TestRotateTLSCertificate_rollback(t *testing.T) {
+	s := &testTLSServer{
+		activations: map[string][2]string{
+			"act-a": {"a.example.com", "cert-old"},
+			"act-b": {"b.example.com", "cert-old"},
+		},
+		certificateDomains: []string{"a.example.com", "b.example.com"},
+		failSwitch:         "act-b",
+	}
+	d := testTLSCertificateRotationData(t, "cert-old", "key-old", []string{"a.example.com", "b.example.com"}, "a.example.com", "b.example.com")
+
+	diags := rotateTLSCertificate(context.Background(), d, &APIClient{conn: s.client(t)})
+	if !diags.HasError() || diags[0].Summary != "Certificate rotation rolled back" {
+		t.Fatalf("expected the rotation to be rolled back, got %v", diags)
+	}
+
+	want := map[string][2]string{
+		"act-a": {"a.example.com", "cert-old"},
+		"act-b": {"b.example.com", "cert-old"},
+	}
+	if !reflect.DeepEqual(s.activations, want) {
+		t.Errorf("expected activations %v, got %v", want, s.activations)
+	}
+	if want := []string{"/tls/certificates/cert-new", "/tls/private_keys/key-new"}; !reflect.DeepEqual(s.deleted, want) {
+		t.Errorf("expected the uploaded certificate and key to be deleted, got %v", s.deleted)
+	}
+	if got := [3]string{d.Get("certificate_id").(string), d.Get("private_key_id").(string), d.Get("status").(string)}; got != [3]string{"cert-old", "key-old", tlsRotationStatusRolledBack} {
+		t.Errorf("unexpected state %v", got)
+	}
+
+	if want := []string{"check_domains:passed", "upload_key:passed", "upload_certificate:passed", "switch_activations:failed", "verify_activations:skipped", "rollback:passed"}; !reflect.DeepEqual(testTLSCertificateRotationSteps(d), want) {
+		t.Errorf("expected steps %v, got %v", want, testTLSCertificateRotationSteps(d))
+	}
+}
+
+func TestResourceFastlyTLSCertificateRotationCreate_rollback(t *testing.T) {
+	for name, tc := range map[string]struct {
+		failDelete string
+		wantID     string
+		wantState  [3]string
+	}{
+		// The uploaded certificate and key are deleted, so nothing is tracked.
+		"rolled back": {
+			wantState: [3]string{"", "", tlsRotationStatusRolledBack},
+		},
+		// The uploaded certificate couldn't be deleted, so the resource
+		// tracks it.
+		"rollback failed": {
+			failDelete: "/tls/certificates/cert-new",
+			wantID:     "cert-new",
+			wantState:  [3]string{"cert-new", "key-new", tlsRotationStatusFailed},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			s := &testTLSServer{
+				activations: map[string][2]string{
+					"act-a": {"a.example.com", "cert-old"},
+					"act-b": {"b.example.com", "cert-old"},
+				},
+				certificateDomains: []string{"a.example.com", "b.example.com"},
+				failSwitch:         "act-b",
+				failDelete:         tc.failDelete,
+			}
+			d := testTLSCertificateRotationData(t, "", "", nil, "a.example.com", "b.example.com")
+
+			diags := resourceFastlyTLSCertificateRotationCreate(context.Background(), d, &APIClient{conn: s.client(t)})
+			if !diags.HasError() {
+				t.Fatal("expected an error")
+			}
+			if d.Id() != tc.wantID {
+				t.Errorf("expected ID %q, got %q", tc.wantID, d.Id())
+			}
+			if got := [3]string{d.Get("certificate_id").(string), d.Get("private_key_id").(string), d.Get("status").(string)}; got != tc.wantState {
+				t.Errorf("expected state %v, got %v", tc.wantState, got)
+			}
+		})
+	}
+}
+
+func TestValidateTLSCertificateRotationConsistency(t *testing.T) {
+	key, cert, err := generateKeyAndCert("www.example.com")
+	require.NoError(t, err)
+	otherKey, _, err := generateKeyAndCert("www.example.com")
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		key  cty.Value
+		want []string
+	}{
+		"matching":    {cty.StringVal(key), nil},
+		"unknown key": {cty.UnknownVal(cty.String), nil},
+		"other key":   {cty.StringVal(otherKey), []string{"certificate_body"}},
+		"invalid key": {cty.StringVal("invalid"), []string{"key_pem"}},
+	} {
+		var resp schema.ValidateResourceConfigFuncResponse
+		validateTLSCertificateRotationConsistency(context.Background(), schema.ValidateResourceConfigFuncRequest{
+			RawConfig: cty.ObjectVal(map[string]cty.Value{
+				"certificate_body": cty.StringVal(cert),
+				"key_pem":          tc.key,
+			}),
+		}, &resp)
+
+		var paths []string
+		for _, d := range resp.Diagnostics {
+			paths = append(paths, d.AttributePath[0].(cty.GetAttrStep).Name)
+		}
+		if !reflect.DeepEqual(paths, tc.want) {
+			t.Errorf("%s: expected diagnostics for %v, got %v", name, tc.want, resp.Diagnostics)
+		}
+	}
+}
+
+func TestAccFastlyTLSCertificateRotation_basic(t *testing.T) {
+	domain := fmt.Sprintf("%s.com", acctest.RandomWithPrefix(testResourcePrefix))
+	key, cert, err := generateKeyAndCert(domain)
+	require.NoError(t, err)
+	key2, cert2, err := generateKeyAndCert(domain)
+	require.NoError(t, err)
+	key = strings.ReplaceAll(key, "\n", `\n`)
+	cert = strings.ReplaceAll(cert, "\n", `\n`)
+	key2 = strings.ReplaceAll(key2, "\n", `\n`)
+	cert2 = strings.ReplaceAll(cert2, "\n", `\n`)
+
+	name := acctest.RandomWithPrefix(testResourcePrefix)
+	var firstCertificateID string
+
+	resourceName := "fastly_tls_certificate_rotation.test"
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      testAccCheckFastlyTLSCertificateRotationDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccFastlyTLSCertificateRotationConfig(name, key, cert, domain),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "status", tlsRotationStatusSucceeded),
+					resource.TestCheckResourceAttr(resourceName, "domains.#", "1"),
+					resource.TestCheckResourceAttr(resourceName, "steps.#", "5"),
+					resource.TestCheckResourceAttrPair(resourceName, "certificate_id", "fastly_tls_activation.test", "certificate_id"),
+					testAccCopyResourceAttr(resourceName, "certificate_id", &firstCertificateID),
+				),
+			},
+			{
+				Config: testAccFastlyTLSCertificateRotationConfig(name, key2, cert2, domain),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "status", tlsRotationStatusSucceeded),
+					resource.TestCheckResourceAttr(resourceName, "activation_ids.#", "1"),
+					resource.TestCheckResourceAttr(resourceName, "steps.#", "6"),
+					resource.TestCheckResourceAttr(resourceName, "steps.0.name", "check_domains"),
+					resource.TestCheckResourceAttr(resourceName, "steps.0.status", "passed"),
+					resource.TestCheckResourceAttrPair(resourceName, "certificate_id", "fastly_tls_activation.test", "certificate_id"),
+					testAccCheckFastlyTLSCertificateDeleted(&firstCertificateID),
+				),
+			},
+		},
+	})
+}
+
+// testAccCopyResourceAttr copies the value of an attribute of a resource.
+func testAccCopyResourceAttr(n, key string, value *string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("not found: %s", n)
+		}
+		*value = rs.Primary.Attributes[key]
+		return nil
+	}
+}
+
+func testAccCheckFastlyTLSCertificateDeleted(id *string) resource.TestCheckFunc {
+	return func(_ *terraform.State) error {
+		conn := testAccProvider.Meta().(*APIClient).conn
+		_, err := conn.GetCustomTLSCertificate(context.TODO(), &gofastly.GetCustomTLSCertificateInput{
+			ID: *id,
+		})
+		if err == nil {
+			return fmt.Errorf("expected the previous TLS certificate (%s) to be deleted", *id)
+		}
+		return nil
+	}
+}
+
+func testAccCheckFastlyTLSCertificateRotationDestroy(state *terraform.State) error {
+	for _, resourceState := range state.RootModule().Resources {
+		if resourceState.Type != "fastly_tls_certificate_rotation" {
+			continue
+		}
+
+		conn := testAccProvider.Meta().(*APIClient).conn
+		certificates, err := conn.ListCustomTLSCertificates(context.TODO(), &gofastly.ListCustomTLSCertificatesInput{})
+		if err != nil {
+			return fmt.Errorf("error listing TLS certificates when deleting certificate rotation (%s): %w", resourceState.Primary.ID, err)
+		}
+
+		for _, certificate := range certificates {
+			if certificate.ID == resourceState.Primary.Attributes["certificate_id"] {
+				return fmt.Errorf("tried deleting certificate rotation (%s), but certificate (%s) still exists", resourceState.Primary.ID, certificate.ID)
+			}
+		}
+	}
+	return nil
+}
+
+func testAccFastlyTLSCertificateRotationConfig(name, key, cert, domain string) string {
+	return fmt.Sprintf(`
+resource "fastly_service_vcl" "test" {
+  name = "%s"
+
+  domain {
+    name = "%s"
+  }
+
+  backend {
+    address = "127.0.0.1"
+    name    = "localhost"
+  }
+
+  force_destroy = true
+}
+
+resource "fastly_tls_certificate_rotation" "test" {
+  name             = "%s"
+  key_pem          = "%s"
+  certificate_body = "%s"
+}
+
+resource "fastly_tls_activation" "test" {
+  certificate_id = fastly_tls_certificate_rotation.test.certificate_id
+  domain         = "%s"
+  depends_on     = [fastly_service_vcl.test]
+}
+`, name, domain, name, key, cert, domain)
+}
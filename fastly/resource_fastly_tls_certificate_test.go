@@ -3,17 +3,96 @@ package fastly
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
 	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"github.com/stretchr/testify/require"
 
 	"github.com/fastly/go-fastly/v12/fastly"
 )
 
+func TestResourceFastlyTLSCertificateUpdate(t *testing.T) {
+	var uploads int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		switch {
+		case r.URL.Path != "/tls/certificates/cert-id":
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusBadRequest)
+		case r.Method == http.MethodPatch:
+			uploads++
+			fmt.Fprint(w, `{"data":{"id":"cert-id","type":"tls_certificate"}}`)
+		default:
+			fmt.Fprint(w, `{"data":{"id":"cert-id","type":"tls_certificate","attributes":{"name":"example","created_at":"2024-01-01T00:00:00Z","updated_at":"2024-01-01T00:00:00Z"}}}`)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := fastly.NewClientForEndpoint("someapikey", server.URL)
+	require.NoError(t, err)
+	meta := &APIClient{conn: client}
+
+	_, cert, err := generateKeyAndCert("example.com")
+	require.NoError(t, err)
+	_, renewed, err := generateKeyAndCert("example.com")
+	require.NoError(t, err)
+
+	r := resourceFastlyTLSCertificate()
+	prior := map[string]any{
+		"certificate_body": cert,
+		"name":             "example",
+	}
+	update := func(config map[string]any) {
+		t.Helper()
+		d := schema.TestResourceDataRaw(t, r.Schema, prior)
+		d.SetId("cert-id")
+		state := d.State()
+		diff, err := r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(config), meta)
+		require.NoError(t, err)
+		d, err = schema.InternalMap(r.Schema).Data(state, diff)
+		require.NoError(t, err)
+		if diags := resourceFastlyTLSCertificateUpdate(context.Background(), d, meta); diags.HasError() {
+			t.Fatalf("unexpected error: %v", diags)
+		}
+	}
+
+	// Arguments that only affect the plan don't upload the certificate again.
+	update(map[string]any{
+		"certificate_body":        cert,
+		"name":                    "example",
+		"expiry_warning_days":     30,
+		"expiry_warning_severity": "error",
+	})
+	if uploads != 0 {
+		t.Errorf("expected no upload, got %d", uploads)
+	}
+
+	update(map[string]any{
+		"certificate_body":           cert,
+		"name":                       "example",
+		"domains":                    []any{"example.com"},
+		"expected_public_key_sha256": "ab12",
+	})
+	if uploads != 0 {
+		t.Errorf("expected no upload, got %d", uploads)
+	}
+
+	update(map[string]any{
+		"certificate_body": renewed,
+		"name":             "example",
+	})
+	if uploads != 1 {
+		t.Errorf("expected the renewed certificate to be uploaded, got %d uploads", uploads)
+	}
+}
+
 func init() {
 	resource.AddTestSweepers("fastly_tls_certificate", &resource.Sweeper{
 		Name:         "fastly_tls_certificate",
@@ -61,7 +140,7 @@ func TestAccFastlyTLSCertificate_withName(t *testing.T) {
 				ResourceName:            resourceName,
 				ImportState:             true,
 				ImportStateVerify:       true,
-				ImportStateVerifyIgnore: []string{"certificate_body"},
+				ImportStateVerifyIgnore: []string{"certificate_body", "chain_fingerprint", "key_algorithm", "public_key_sha256", "subject_alternative_names.#", "subject_alternative_names.0"},
 			},
 		},
 	})
@@ -94,6 +173,12 @@ func TestAccFastlyTLSCertificate_withoutName(t *testing.T) {
 					resource.TestCheckResourceAttrSet(resourceName, "serial_number"),
 					resource.TestCheckResourceAttrSet(resourceName, "signature_algorithm"),
 					resource.TestCheckResourceAttr(resourceName, "domains.#", "1"),
+					resource.TestCheckResourceAttrSet(resourceName, "not_after"),
+					resource.TestCheckResourceAttrSet(resourceName, "not_before"),
+					resource.TestCheckResourceAttr(resourceName, "key_algorithm", "RSA-2048"),
+					resource.TestCheckResourceAttr(resourceName, "subject_alternative_names.#", "1"),
+					resource.TestCheckResourceAttr(resourceName, "subject_alternative_names.0", domain),
+					resource.TestCheckResourceAttrSet(resourceName, "chain_fingerprint"),
 					testAccTLSCertificateExists(resourceName),
 				),
 			},
@@ -101,6 +186,74 @@ func TestAccFastlyTLSCertificate_withoutName(t *testing.T) {
 	})
 }
 
+func TestAccFastlyTLSCertificate_expiryWarning(t *testing.T) {
+	name := acctest.RandomWithPrefix(testResourcePrefix)
+	domain := fmt.Sprintf("%s.example.com", name)
+
+	// The certificate is valid for 90 days.
+	key, cert, err := generateKeyAndCert(domain)
+	require.NoError(t, err)
+
+	resourceName := "fastly_tls_certificate.test"
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      testAccCheckTLSCertificateDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccTLSCertificateWithExpiryWarning(name, key, cert, 100),
+				ExpectError: regexp.MustCompile("The certificate expires in 89 day"),
+			},
+			{
+				Config: testAccTLSCertificateWithExpiryWarning(name, key, cert, 30),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "expiry_warning_days", "30"),
+					resource.TestCheckResourceAttr(resourceName, "expiry_warning_severity", "error"),
+					resource.TestCheckResourceAttrSet(resourceName, "not_after"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccFastlyTLSCertificate_consistencyChecks(t *testing.T) {
+	name := acctest.RandomWithPrefix(testResourcePrefix)
+	domain := fmt.Sprintf("%s.example.com", name)
+
+	key, cert, err := generateKeyAndCert(domain)
+	require.NoError(t, err)
+	otherKey, _, err := generateKeyAndCert(domain)
+	require.NoError(t, err)
+
+	resourceName := "fastly_tls_certificate.test"
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      testAccCheckTLSCertificateDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccTLSCertificateWithConsistencyChecks(name, otherKey, cert, domain),
+				ExpectError: regexp.MustCompile("doesn't match the private key"),
+			},
+			{
+				Config:      testAccTLSCertificateWithConsistencyChecks(name, key, cert, "other."+domain),
+				ExpectError: regexp.MustCompile("domains don't match the certificate"),
+			},
+			{
+				Config: testAccTLSCertificateWithConsistencyChecks(name, key, cert, domain),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair(resourceName, "public_key_sha256", "fastly_tls_private_key.key", "public_key_sha256"),
+					resource.TestCheckResourceAttr(resourceName, "domains.#", "1"),
+				),
+			},
+		},
+	})
+}
+
 func testAccTLSCertificateExists(resourceName string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		r, ok := s.RootModule().Resources[resourceName]
@@ -157,6 +310,45 @@ EOF
 `, keyName, key, cert)
 }
 
+func testAccTLSCertificateWithExpiryWarning(keyName string, key string, cert string, days int) string {
+	return fmt.Sprintf(`
+resource "fastly_tls_private_key" "key" {
+  name = "%[1]s"
+  key_pem = <<EOF
+%[2]s
+EOF
+}
+
+resource "fastly_tls_certificate" "test" {
+  certificate_body = <<EOF
+%[3]s
+EOF
+  expiry_warning_days     = %[4]d
+  expiry_warning_severity = "error"
+  depends_on = [fastly_tls_private_key.key]
+}
+`, keyName, key, cert, days)
+}
+
+func testAccTLSCertificateWithConsistencyChecks(keyName string, key string, cert string, domain string) string {
+	return fmt.Sprintf(`
+resource "fastly_tls_private_key" "key" {
+  name = "%[1]s"
+  key_pem = <<EOF
+%[2]s
+EOF
+}
+
+resource "fastly_tls_certificate" "test" {
+  certificate_body = <<EOF
+%[3]s
+EOF
+  domains                    = ["%[4]s"]
+  expected_public_key_sha256 = fastly_tls_private_key.key.public_key_sha256
+}
+`, keyName, key, cert, domain)
+}
+
 func testAccCheckTLSCertificateDestroy(s *terraform.State) error {
 	conn := testAccProvider.Meta().(*APIClient).conn
 
@@ -181,11 +373,10 @@ func testAccCheckTLSCertificateDestroy(s *terraform.State) error {
 }
 
 func testSweepTLSCertificates(region string) error {
-	client, diagnostics := sharedClientForRegion(region)
-	if diagnostics.HasError() {
-		return diagToErr(diagnostics)
-	}
+	return sweepAccounts(region, sweepTLSCertificates)
+}
 
+func sweepTLSCertificates(client *fastly.Client) error {
 	certificates, err := client.ListCustomTLSCertificates(context.TODO(), &fastly.ListCustomTLSCertificatesInput{PageSize: 1000})
 	if err != nil {
 		return err
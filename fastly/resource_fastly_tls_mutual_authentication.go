@@ -32,9 +32,10 @@ func resourceFastlyTLSMutualAuthentication() *schema.Resource {
 				},
 			},
 			"cert_bundle": {
-				Type:        schema.TypeString,
-				Description: "One or more certificates. Enter each individual certificate blob on a new line. Must be PEM-formatted.",
-				Required:    true,
+				Type:             schema.TypeString,
+				Description:      "One or more certificates. Enter each individual certificate blob on a new line. Must be PEM-formatted CA certificates.",
+				Required:         true,
+				ValidateDiagFunc: validateCACertificateBundle(),
 			},
 			"created_at": {
 				Type:        schema.TypeString,
@@ -22,6 +22,9 @@ func resourceFastlyTLSPlatformCertificate() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		ValidateRawResourceConfigFuncs: []schema.ValidateRawResourceConfigFunc{
+			validateTLSPlatformCertificateConsistency,
+		},
 		Schema: map[string]*schema.Schema{
 			"allow_untrusted_root": {
 				Type:        schema.TypeBool,
@@ -48,13 +51,14 @@ func resourceFastlyTLSPlatformCertificate() *schema.Resource {
 			},
 			"domains": {
 				Type:        schema.TypeSet,
-				Description: "All the domains (including wildcard domains) that are listed in any certificate's Subject Alternative Names (SAN) list.",
+				Description: "All the domains (including wildcard domains) that are listed in any certificate's Subject Alternative Names (SAN) list. When set, the plan fails unless they match the SAN list of `certificate_body`.",
+				Optional:    true,
 				Computed:    true,
 				Elem:        &schema.Schema{Type: schema.TypeString},
 			},
 			"intermediates_blob": {
 				Type:             schema.TypeString,
-				Description:      "PEM-formatted certificate chain from the `certificate_body` to its root.",
+				Description:      "PEM-formatted certificate chain from the `certificate_body` to its root. The plan fails unless the chain verifies to `certificate_body` and every certificate of the chain is part of the path.",
 				Required:         true,
 				ValidateDiagFunc: validatePEMBlocks("CERTIFICATE"),
 			},
@@ -156,6 +160,12 @@ func resourceFastlyTLSPlatformCertificateRead(ctx context.Context, d *schema.Res
 }
 
 func resourceFastlyTLSPlatformCertificateUpdate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	// NOTE: `domains` only affects the plan, and updating the certificate
+	// uploads it again.
+	if !d.HasChanges("certificate_body", "intermediates_blob", "allow_untrusted_root") {
+		return resourceFastlyTLSPlatformCertificateRead(ctx, d, meta)
+	}
+
 	conn := meta.(*APIClient).conn
 
 	_, err := conn.UpdateBulkCertificate(ctx, &fastly.UpdateBulkCertificateInput{
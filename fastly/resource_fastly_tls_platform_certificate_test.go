@@ -3,17 +3,69 @@ package fastly
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"github.com/stretchr/testify/require"
 
 	"github.com/fastly/go-fastly/v12/fastly"
 )
 
+func TestResourceFastlyTLSPlatformCertificateUpdate(t *testing.T) {
+	var uploads int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		switch {
+		case r.URL.Path != "/tls/bulk/certificates/cert-id":
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusBadRequest)
+		case r.Method == http.MethodPatch:
+			uploads++
+			fmt.Fprint(w, `{"data":{"id":"cert-id","type":"tls_bulk_certificate"}}`)
+		default:
+			fmt.Fprint(w, `{"data":{"id":"cert-id","type":"tls_bulk_certificate","attributes":{"created_at":"2024-01-01T00:00:00Z","updated_at":"2024-01-01T00:00:00Z","not_after":"2025-01-01T00:00:00Z","not_before":"2024-01-01T00:00:00Z"},"relationships":{"tls_configurations":{"data":[{"id":"config-id","type":"tls_configuration"}]}}}}`)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := fastly.NewClientForEndpoint("someapikey", server.URL)
+	require.NoError(t, err)
+	meta := &APIClient{conn: client}
+
+	_, cert, caPEM, err := generateKeyAndCertWithCA("example.com")
+	require.NoError(t, err)
+
+	r := resourceFastlyTLSPlatformCertificate()
+	config := map[string]any{
+		"certificate_body":   cert,
+		"configuration_id":   "config-id",
+		"intermediates_blob": caPEM,
+	}
+	d := schema.TestResourceDataRaw(t, r.Schema, config)
+	d.SetId("cert-id")
+	state := d.State()
+
+	// `domains` only affects the plan, so it doesn't upload the certificate
+	// again.
+	config["domains"] = []any{"example.com"}
+	diff, err := r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(config), meta)
+	require.NoError(t, err)
+	d, err = schema.InternalMap(r.Schema).Data(state, diff)
+	require.NoError(t, err)
+	if diags := resourceFastlyTLSPlatformCertificateUpdate(context.Background(), d, meta); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if uploads != 0 {
+		t.Errorf("expected no upload, got %d", uploads)
+	}
+}
+
 func init() {
 	resource.AddTestSweepers("fastly_tls_platform_certificate", &resource.Sweeper{
 		Name: "fastly_tls_platform_certificate",
@@ -128,11 +180,10 @@ EOF
 }
 
 func testSweepTLSPlatformCertificates(region string) error {
-	client, diagnostics := sharedClientForRegion(region)
-	if diagnostics.HasError() {
-		return diagToErr(diagnostics)
-	}
+	return sweepAccounts(region, sweepTLSPlatformCertificates)
+}
 
+func sweepTLSPlatformCertificates(client *fastly.Client) error {
 	certificates, err := client.ListBulkCertificates(context.TODO(), &fastly.ListBulkCertificatesInput{PageSize: 1000})
 	if err != nil {
 		return err
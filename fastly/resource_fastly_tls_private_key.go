@@ -20,6 +20,7 @@ func resourceFastlyTLSPrivateKey() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		CustomizeDiff: resourceFastlyTLSPrivateKeyCustomizeDiff,
 		Schema: map[string]*schema.Schema{
 			"created_at": {
 				Type:        schema.TypeString,
@@ -54,6 +55,11 @@ func resourceFastlyTLSPrivateKey() *schema.Resource {
 				Computed:    true,
 				Description: "Useful for safely identifying the key.",
 			},
+			"public_key_sha256": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The hex-encoded SHA-256 digest of the DER-encoded public key, computed from `key_pem`. Reference it in the `expected_public_key_sha256` argument of `fastly_tls_certificate` to check that the certificate was issued for this key.",
+			},
 			"replace": {
 				Type:        schema.TypeBool,
 				Computed:    true,
@@ -79,6 +85,20 @@ func resourceFastlyTLSPrivateKeyCreate(ctx context.Context, d *schema.ResourceDa
 	return resourceFastlyTLSPrivateKeyRead(ctx, d, meta)
 }
 
+// resourceFastlyTLSPrivateKeyCustomizeDiff computes `public_key_sha256` at plan
+// time, so that certificates can be checked against the key before either is
+// uploaded.
+func resourceFastlyTLSPrivateKeyCustomizeDiff(_ context.Context, rd *schema.ResourceDiff, _ any) error {
+	if !rd.HasChange("key_pem") || !rd.NewValueKnown("key_pem") {
+		return nil
+	}
+	sha, err := privateKeyPublicKeySHA256(rd.Get("key_pem").(string))
+	if err != nil {
+		return fmt.Errorf("key_pem: %w", err)
+	}
+	return rd.SetNew("public_key_sha256", sha)
+}
+
 func resourceFastlyTLSPrivateKeyRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
 	log.Printf("[DEBUG] Refreshing TLS Private Key Configuration for (%s)", d.Id())
 
@@ -125,6 +145,19 @@ func resourceFastlyTLSPrivateKeyRead(ctx context.Context, d *schema.ResourceData
 		return diag.FromErr(err)
 	}
 
+	// The key isn't returned by the API, so imported keys don't have a
+	// public_key_sha256.
+	if keyPEM := d.Get("key_pem").(string); keyPEM != "" {
+		sha, err := privateKeyPublicKeySHA256(keyPEM)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		err = d.Set("public_key_sha256", sha)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
 	return diags
 }
 
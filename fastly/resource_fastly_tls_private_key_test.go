@@ -42,13 +42,14 @@ func TestAccFastlyResourceTLSPrivateKey_basic(t *testing.T) {
 				Check: resource.ComposeTestCheckFunc(
 					testAccCheckPrivateKeyExists("fastly_tls_private_key.foo"),
 					resource.TestCheckResourceAttr("fastly_tls_private_key.foo", "name", name),
+					resource.TestCheckResourceAttrSet("fastly_tls_private_key.foo", "public_key_sha256"),
 				),
 			},
 			{
 				ResourceName:            "fastly_tls_private_key.foo",
 				ImportState:             true,
 				ImportStateVerify:       true,
-				ImportStateVerifyIgnore: []string{"key_pem"},
+				ImportStateVerifyIgnore: []string{"key_pem", "public_key_sha256"},
 			},
 		},
 	})
@@ -115,11 +116,10 @@ func testAccCheckPrivateKeyExists(resourceName string) resource.TestCheckFunc {
 }
 
 func testSweepTLSPrivateKeys(region string) error {
-	client, diagnostics := sharedClientForRegion(region)
-	if diagnostics.HasError() {
-		return diagToErr(diagnostics)
-	}
+	return sweepAccounts(region, sweepTLSPrivateKeys)
+}
 
+func sweepTLSPrivateKeys(client *fastly.Client) error {
 	keys, err := client.ListPrivateKeys(context.TODO(), &fastly.ListPrivateKeysInput{PageSize: 1000})
 	if err != nil {
 		return err
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"sort"
 	"strings"
 	"time"
 
@@ -33,6 +34,7 @@ func resourceFastlyTLSSubscription() *schema.Resource {
 			customdiff.ValidateValue("domains", resourceFastlyTLSSubscriptionValidateDomains),
 			customdiff.ValidateValue("common_name", resourceFastlyTLSSubscriptionValidateCommonName),
 			resourceFastlyTLSSubscriptionSetNewComputed,
+			resourceFastlyTLSSubscriptionPlanDNSRecords,
 		),
 		Schema: map[string]*schema.Schema{
 			"certificate_authority": {
@@ -64,6 +66,40 @@ func resourceFastlyTLSSubscription() *schema.Resource {
 				Description: "Timestamp (GMT) when the subscription was created.",
 				Computed:    true,
 			},
+			"dns_records": {
+				Type:        schema.TypeList,
+				Description: "The DNS records that respond to the ACME DNS challenges, one per domain, sorted by domain. The domains and record names are known at plan time, including for domains added to an existing subscription, so the records can be used with `for_each`, e.g. `{ for r in fastly_tls_subscription.example.dns_records : r.domain => r }`. An apex domain and its wildcard share the same record.",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"domain": {
+							Type:        schema.TypeString,
+							Description: "The domain validated by the record, e.g. `*.example.com`.",
+							Computed:    true,
+						},
+						"record_name": {
+							Type:        schema.TypeString,
+							Description: "The name of the DNS record to add, e.g. `_acme-challenge.example.com`.",
+							Computed:    true,
+						},
+						"record_type": {
+							Type:        schema.TypeString,
+							Description: "The type of DNS record to add, e.g. `CNAME`.",
+							Computed:    true,
+						},
+						"record_value": {
+							Type:        schema.TypeString,
+							Description: "The value to which the DNS record should point, e.g. `xxxxx.fastly-validations.com`.",
+							Computed:    true,
+						},
+						"ttl": {
+							Type:        schema.TypeInt,
+							Description: "The suggested TTL of the DNS record, in seconds.",
+							Computed:    true,
+						},
+					},
+				},
+			},
 			"domains": {
 				Type:        schema.TypeSet,
 				Description: "List of domains on which to enable TLS.",
@@ -230,6 +266,7 @@ func resourceFastlyTLSSubscriptionRead(ctx context.Context, d *schema.ResourceDa
 
 	var managedHTTPChallenges []map[string]any
 	var managedDNSChallenges []map[string]any
+	dnsChallenges := make(map[string]gofastly.TLSChallenge)
 	for _, domain := range subscription.Authorizations {
 		for _, challenge := range domain.Challenges {
 			if challenge.Type == "managed-dns" {
@@ -242,6 +279,7 @@ func resourceFastlyTLSSubscriptionRead(ctx context.Context, d *schema.ResourceDa
 					"record_name":  challenge.RecordName,
 					"record_value": challenge.Values[0],
 				})
+				dnsChallenges[challenge.RecordName] = challenge
 			} else {
 				managedHTTPChallenges = append(managedHTTPChallenges, map[string]any{
 					"record_type":   challenge.RecordType,
@@ -346,6 +384,10 @@ func resourceFastlyTLSSubscriptionRead(ctx context.Context, d *schema.ResourceDa
 	if err != nil {
 		return diag.FromErr(err)
 	}
+	err = d.Set("dns_records", flattenTLSSubscriptionDNSRecords(domains, dnsChallenges, ""))
+	if err != nil {
+		return diag.FromErr(err)
+	}
 
 	return nil
 }
@@ -430,6 +472,85 @@ func resourceFastlyTLSSubscriptionSetNewComputed(_ context.Context, d *schema.Re
 	return nil
 }
 
+// resourceFastlyTLSSubscriptionPlanDNSRecords plans `dns_records` when the
+// domains change. The records of new domains have unknown types and values
+// until the subscription is applied, but their domains and names are known,
+// so that `dns_records` can be used with `for_each` in the same plan.
+func resourceFastlyTLSSubscriptionPlanDNSRecords(_ context.Context, d *schema.ResourceDiff, _ any) error {
+	if !d.HasChange("domains") {
+		return nil
+	}
+	if !d.NewValueKnown("domains") {
+		return d.SetNewComputed("dns_records")
+	}
+
+	// The challenges of the domains the subscription already has don't
+	// change. When the subscription is replaced, the diff is planned again
+	// without an ID.
+	known := make(map[string]gofastly.TLSChallenge)
+	if d.Id() != "" {
+		old, _ := d.GetChange("dns_records")
+		for _, r := range old.([]any) {
+			record := r.(map[string]any)
+			if value := record["record_value"].(string); value != "" {
+				known[record["record_name"].(string)] = gofastly.TLSChallenge{
+					RecordName: record["record_name"].(string),
+					RecordType: record["record_type"].(string),
+					Values:     []string{value},
+				}
+			}
+		}
+	}
+
+	var domains []string
+	for _, domain := range d.Get("domains").(*schema.Set).List() {
+		domains = append(domains, domain.(string))
+	}
+	return d.SetNew("dns_records", flattenTLSSubscriptionDNSRecords(domains, known, unknownVariableValue))
+}
+
+// tlsSubscriptionDNSRecordTTL is the suggested TTL of `dns_records`, short
+// so that a record that is fixed is seen by the next validation attempt.
+const tlsSubscriptionDNSRecordTTL = 60
+
+// unknownVariableValue is the value the SDK plans as unknown when it is set
+// with ResourceDiff.SetNew, which allows planning the elements of a list with
+// some unknown attributes.
+const unknownVariableValue = "74D93920-ED26-11E3-AC10-0800200C9A66"
+
+// tlsSubscriptionDNSRecordName returns the name of the record of the managed
+// DNS challenge of a domain. Wildcard domains share the record of their
+// apex domain.
+func tlsSubscriptionDNSRecordName(domain string) string {
+	return "_acme-challenge." + strings.TrimPrefix(domain, "*.")
+}
+
+// flattenTLSSubscriptionDNSRecords returns the `dns_records` of the domains,
+// sorted by domain, from the managed DNS challenges keyed by record name.
+// The type and value of the records of domains without a challenge are set
+// to missing.
+func flattenTLSSubscriptionDNSRecords(domains []string, challenges map[string]gofastly.TLSChallenge, missing string) []map[string]any {
+	sorted := append([]string{}, domains...)
+	sort.Strings(sorted)
+
+	records := make([]map[string]any, 0, len(sorted))
+	for _, domain := range sorted {
+		name := tlsSubscriptionDNSRecordName(domain)
+		recordType, recordValue := missing, missing
+		if challenge, ok := challenges[name]; ok && len(challenge.Values) > 0 {
+			recordType, recordValue = challenge.RecordType, challenge.Values[0]
+		}
+		records = append(records, map[string]any{
+			"domain":       domain,
+			"record_name":  name,
+			"record_type":  recordType,
+			"record_value": recordValue,
+			"ttl":          tlsSubscriptionDNSRecordTTL,
+		})
+	}
+	return records
+}
+
 // NOTE: Although the RFC spec says it’s case-insensitive, the implementation is varied depending on the software.
 // For example, Let's Encrypt doesn't allow uppercase letters. For this reason, Fastly TLS also doesn't support
 // uppercase letters in domains. But, Fastly API accepts such inputs and silently converts them to lowercase.
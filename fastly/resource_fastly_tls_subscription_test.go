@@ -3,12 +3,17 @@ package fastly
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"regexp"
 	"strings"
 	"testing"
 
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/go-cty/cty/msgpack"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 
 	"github.com/fastly/go-fastly/v12/fastly"
@@ -21,6 +26,129 @@ func init() {
 	})
 }
 
+func TestFlattenTLSSubscriptionDNSRecords(t *testing.T) {
+	challenges := map[string]fastly.TLSChallenge{
+		"_acme-challenge.example.com": {RecordName: "_acme-challenge.example.com", RecordType: "CNAME", Values: []string{"abc.fastly-validations.com"}},
+	}
+
+	records := flattenTLSSubscriptionDNSRecords([]string{"www.example.org", "*.example.com", "example.com"}, challenges, "")
+	want := []map[string]any{
+		{"domain": "*.example.com", "record_name": "_acme-challenge.example.com", "record_type": "CNAME", "record_value": "abc.fastly-validations.com", "ttl": tlsSubscriptionDNSRecordTTL},
+		{"domain": "example.com", "record_name": "_acme-challenge.example.com", "record_type": "CNAME", "record_value": "abc.fastly-validations.com", "ttl": tlsSubscriptionDNSRecordTTL},
+		{"domain": "www.example.org", "record_name": "_acme-challenge.www.example.org", "record_type": "", "record_value": "", "ttl": tlsSubscriptionDNSRecordTTL},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("expected records %v, got %v", want, records)
+	}
+}
+
+func TestResourceFastlyTLSSubscriptionPlanDNSRecords(t *testing.T) {
+	r := resourceFastlyTLSSubscription()
+	addAccountSupport(&schema.Provider{ResourcesMap: map[string]*schema.Resource{"fastly_tls_subscription": r}})
+	ty := r.CoreConfigSchema().ImpliedType()
+
+	value := func(attrs map[string]cty.Value) cty.Value {
+		all := make(map[string]cty.Value)
+		for name, t := range ty.AttributeTypes() {
+			all[name] = cty.NullVal(t)
+		}
+		for name, v := range attrs {
+			all[name] = v
+		}
+		return cty.ObjectVal(all)
+	}
+	config := func(domains ...string) map[string]cty.Value {
+		var vals []cty.Value
+		for _, domain := range domains {
+			vals = append(vals, cty.StringVal(domain))
+		}
+		return map[string]cty.Value{
+			"certificate_authority": cty.StringVal("lets-encrypt"),
+			"domains":               cty.SetVal(vals),
+		}
+	}
+	// planned returns the domains and whether the values of the planned
+	// records are known.
+	planned := func(v cty.Value) (domains []string, known []bool) {
+		for _, record := range v.GetAttr("dns_records").AsValueSlice() {
+			domains = append(domains, record.GetAttr("domain").AsString())
+			known = append(known, record.GetAttr("record_value").IsKnown())
+		}
+		return domains, known
+	}
+
+	// Create.
+	create := value(config("b.example.com", "a.example.com"))
+	domains, known := planned(testPlanResourceChange(t, r, cty.NullVal(ty), create, create))
+	if want := []string{"a.example.com", "b.example.com"}; !reflect.DeepEqual(domains, want) || !reflect.DeepEqual(known, []bool{false, false}) {
+		t.Errorf("expected known domains %v with unknown values, got %v %v", want, domains, known)
+	}
+
+	// Update, adding a domain.
+	prior := config("a.example.com")
+	prior["id"] = cty.StringVal("SU1Z0isxPaozGVKXdv0eY")
+	prior["state"] = cty.StringVal("issued")
+	prior["force_destroy"] = cty.False
+	prior["force_update"] = cty.False
+	prior["dns_records"] = cty.ListVal([]cty.Value{cty.ObjectVal(map[string]cty.Value{
+		"domain":       cty.StringVal("a.example.com"),
+		"record_name":  cty.StringVal("_acme-challenge.a.example.com"),
+		"record_type":  cty.StringVal("CNAME"),
+		"record_value": cty.StringVal("abc.fastly-validations.com"),
+		"ttl":          cty.NumberIntVal(tlsSubscriptionDNSRecordTTL),
+	})})
+	proposed := make(map[string]cty.Value)
+	for k, v := range prior {
+		proposed[k] = v
+	}
+	proposed["domains"] = create.GetAttr("domains")
+
+	update := testPlanResourceChange(t, r, value(prior), create, value(proposed))
+	domains, known = planned(update)
+	if want := []string{"a.example.com", "b.example.com"}; !reflect.DeepEqual(domains, want) || !reflect.DeepEqual(known, []bool{true, false}) {
+		t.Errorf("expected known domains %v with an unknown value for the new domain, got %v %v", want, domains, known)
+	}
+	if v := update.GetAttr("dns_records").Index(cty.NumberIntVal(0)).GetAttr("record_value"); !v.RawEquals(cty.StringVal("abc.fastly-validations.com")) {
+		t.Errorf("expected the record of the existing domain to be unchanged, got %#v", v)
+	}
+}
+
+// testPlanResourceChange plans a change of a resource through the gRPC
+// server of the provider, the way Terraform does, and returns the planned
+// state.
+func testPlanResourceChange(t *testing.T, r *schema.Resource, prior, config, proposed cty.Value) cty.Value {
+	t.Helper()
+
+	ty := r.CoreConfigSchema().ImpliedType()
+	encode := func(v cty.Value) *tfprotov5.DynamicValue {
+		b, err := msgpack.Marshal(v, ty)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &tfprotov5.DynamicValue{MsgPack: b}
+	}
+
+	provider := &schema.Provider{ResourcesMap: map[string]*schema.Resource{"fastly_test": r}}
+	resp, err := schema.NewGRPCProviderServer(provider).PlanResourceChange(context.Background(), &tfprotov5.PlanResourceChangeRequest{
+		TypeName:         "fastly_test",
+		PriorState:       encode(prior),
+		Config:           encode(config),
+		ProposedNewState: encode(proposed),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range resp.Diagnostics {
+		t.Fatalf("unexpected diagnostic: %s: %s", d.Summary, d.Detail)
+	}
+
+	planned, err := msgpack.Unmarshal(resp.PlannedState.MsgPack, ty)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return planned
+}
+
 func TestAccResourceFastlyTLSSubscription_Config(t *testing.T) {
 	name := acctest.RandomWithPrefix(testResourcePrefix)
 	domain1 := fmt.Sprintf("%s.test", name)
@@ -47,6 +175,10 @@ func TestAccResourceFastlyTLSSubscription_Config(t *testing.T) {
 					resource.TestCheckResourceAttrSet(resourceName, "state"),
 					resource.TestCheckResourceAttr(resourceName, "managed_dns_challenge.%", "3"),
 					resource.TestCheckResourceAttrSet(resourceName, "managed_http_challenges.#"),
+					resource.TestCheckResourceAttr(resourceName, "dns_records.#", "2"),
+					resource.TestCheckResourceAttr(resourceName, "dns_records.0.domain", domain1),
+					resource.TestCheckResourceAttr(resourceName, "dns_records.0.record_name", "_acme-challenge."+domain1),
+					resource.TestCheckResourceAttrSet(resourceName, "dns_records.0.record_value"),
 					resource.TestCheckResourceAttr(resourceName, "common_name", domain1),
 					testAccResourceFastlyTLSSubscriptionExists(resourceName, &subscriptionID),
 				),
@@ -135,11 +267,10 @@ resource "fastly_tls_subscription" "subject" {
 }
 
 func testSweepTLSSubscription(region string) error {
-	client, diagnostics := sharedClientForRegion(region)
-	if diagnostics.HasError() {
-		return diagToErr(diagnostics)
-	}
+	return sweepAccounts(region, sweepTLSSubscription)
+}
 
+func sweepTLSSubscription(client *fastly.Client) error {
 	subscriptions, err := client.ListTLSSubscriptions(context.TODO(), &fastly.ListTLSSubscriptionsInput{PageSize: 1000})
 	if err != nil {
 		return err
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/hashicorp/go-cty/cty"
@@ -16,9 +18,12 @@ import (
 
 func resourceFastlyTLSSubscriptionValidation() *schema.Resource {
 	return &schema.Resource{
-		CreateContext: resourceFastlyTLSSubscriptionValidationCreate,
-		ReadContext:   resourceFastlyTLSSubscriptionValidationRead,
-		DeleteContext: resourceFastlyTLSSubscriptionValidationDelete,
+		// NOTE: The SDK would cancel CreateContext once the create timeout
+		// elapses, which `wait_timeout` overrides, so the deadline is applied
+		// by resourceFastlyTLSSubscriptionValidationCreate instead.
+		CreateWithoutTimeout: resourceFastlyTLSSubscriptionValidationCreate,
+		ReadContext:          resourceFastlyTLSSubscriptionValidationRead,
+		DeleteContext:        resourceFastlyTLSSubscriptionValidationDelete,
 		Schema: map[string]*schema.Schema{
 			"subscription_id": {
 				Type:        schema.TypeString,
@@ -26,6 +31,13 @@ func resourceFastlyTLSSubscriptionValidation() *schema.Resource {
 				Required:    true,
 				ForceNew:    true,
 			},
+			"wait_timeout": {
+				Type:             schema.TypeString,
+				Description:      "How long to wait for the subscription to be validated (e.g. `2h`). Overrides the `create` timeout.",
+				Optional:         true,
+				ForceNew:         true,
+				ValidateDiagFunc: validateDuration(),
+			},
 		},
 		Timeouts: &schema.ResourceTimeout{
 			Create: schema.DefaultTimeout(45 * time.Minute),
@@ -35,21 +47,36 @@ func resourceFastlyTLSSubscriptionValidation() *schema.Resource {
 
 const (
 	subscriptionStateIssued = "issued"
+	// authorizationStateValid is the state of the authorization of a
+	// validated domain.
+	authorizationStateValid = "valid"
 )
 
 func resourceFastlyTLSSubscriptionValidationCreate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
 	conn := meta.(*APIClient).conn
 
-	err := retry.RetryContext(ctx, d.Timeout(schema.TimeoutCreate), func() *retry.RetryError {
+	timeout := d.Timeout(schema.TimeoutCreate)
+	if v, ok := d.GetOk("wait_timeout"); ok {
+		timeout, _ = time.ParseDuration(v.(string))
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	subscriptionID := d.Get("subscription_id").(string)
+	include := "tls_authorizations"
+	err := retry.RetryContext(ctx, timeout, func() *retry.RetryError {
 		subscription, err := conn.GetTLSSubscription(ctx, &gofastly.GetTLSSubscriptionInput{
-			ID: d.Get("subscription_id").(string),
+			ID:      subscriptionID,
+			Include: &include,
 		})
 		if err != nil {
 			return retry.NonRetryableError(err)
 		}
 
 		if subscription.State != subscriptionStateIssued {
-			return retry.RetryableError(fmt.Errorf("expected subscription state to be %s but it was %s", subscriptionStateIssued, subscription.State))
+			pending := strings.Join(tlsSubscriptionPendingDomains(subscription), ", ")
+			log.Printf("[INFO] Waiting for TLS Subscription (%s) to be issued, state %s, domains pending validation: %s", subscriptionID, subscription.State, pending)
+			return retry.RetryableError(fmt.Errorf("expected subscription state to be %s but it was %s, domains pending validation: %s", subscriptionStateIssued, subscription.State, pending))
 		}
 
 		err = diagToErr(resourceFastlyTLSSubscriptionValidationRead(ctx, d, meta))
@@ -72,8 +99,10 @@ func resourceFastlyTLSSubscriptionValidationRead(ctx context.Context, d *schema.
 	conn := meta.(*APIClient).conn
 
 	subscriptionID := d.Get("subscription_id").(string)
+	include := "tls_authorizations"
 	subscription, err := conn.GetTLSSubscription(ctx, &gofastly.GetTLSSubscriptionInput{
-		ID: subscriptionID,
+		ID:      subscriptionID,
+		Include: &include,
 	})
 	if err, ok := err.(*gofastly.HTTPError); ok && err.IsNotFound() {
 		id := d.Id()
@@ -90,7 +119,17 @@ func resourceFastlyTLSSubscriptionValidationRead(ctx context.Context, d *schema.
 	}
 
 	if subscription.State != subscriptionStateIssued {
+		id := d.Id()
 		d.SetId("")
+		if id != "" {
+			return diag.Diagnostics{
+				diag.Diagnostic{
+					Severity: diag.Warning,
+					Summary:  fmt.Sprintf("TLS subscription (%s) is %s - removing validation from state", subscriptionID, subscription.State),
+					Detail:   fmt.Sprintf("Domains pending validation: %s", strings.Join(tlsSubscriptionPendingDomains(subscription), ", ")),
+				},
+			}
+		}
 	} else {
 		d.SetId(subscriptionID)
 	}
@@ -102,3 +141,34 @@ func resourceFastlyTLSSubscriptionValidationDelete(_ context.Context, _ *schema.
 	// Virtual resource so doesn't need deleting
 	return nil
 }
+
+// tlsSubscriptionPendingDomains returns the domains of a subscription, with
+// the state of their authorization, whose authorization isn't valid yet. The
+// authorizations are matched to domains by the record of their managed DNS
+// challenge, so an apex domain and its wildcard are pending together.
+func tlsSubscriptionPendingDomains(subscription *gofastly.TLSSubscription) []string {
+	states := make(map[string]string)
+	for _, authorization := range subscription.Authorizations {
+		for _, challenge := range authorization.Challenges {
+			if challenge.Type != "managed-dns" {
+				continue
+			}
+			if state, ok := states[challenge.RecordName]; !ok || state == authorizationStateValid {
+				states[challenge.RecordName] = authorization.State
+			}
+		}
+	}
+
+	var pending []string
+	for _, domain := range subscription.Domains {
+		state, ok := states[tlsSubscriptionDNSRecordName(domain.ID)]
+		switch {
+		case !ok:
+			pending = append(pending, fmt.Sprintf("%s (no authorization)", domain.ID))
+		case state != authorizationStateValid:
+			pending = append(pending, fmt.Sprintf("%s (%s)", domain.ID, state))
+		}
+	}
+	sort.Strings(pending)
+	return pending
+}
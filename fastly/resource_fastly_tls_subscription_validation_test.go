@@ -0,0 +1,83 @@
+package fastly
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+
+	gofastly "github.com/fastly/go-fastly/v12/fastly"
+)
+
+func TestTLSSubscriptionPendingDomains(t *testing.T) {
+	authorization := func(state string, domain string) *gofastly.TLSAuthorizations {
+		return &gofastly.TLSAuthorizations{
+			State: state,
+			Challenges: []gofastly.TLSChallenge{
+				{Type: "managed-dns", RecordName: tlsSubscriptionDNSRecordName(domain), RecordType: "CNAME", Values: []string{"abc.fastly-validations.com"}},
+				{Type: "managed-http-cname", RecordName: domain, RecordType: "CNAME", Values: []string{"j.sni.global.fastly.net"}},
+			},
+		}
+	}
+
+	subscription := &gofastly.TLSSubscription{
+		Domains: []*gofastly.TLSDomain{
+			{ID: "www.example.org"},
+			{ID: "example.com"},
+			{ID: "*.example.com"},
+			{ID: "api.example.org"},
+		},
+		Authorizations: []*gofastly.TLSAuthorizations{
+			authorization(authorizationStateValid, "www.example.org"),
+			authorization(authorizationStateValid, "example.com"),
+			authorization("pending", "*.example.com"),
+		},
+	}
+
+	want := []string{"*.example.com (pending)", "api.example.org (no authorization)", "example.com (pending)"}
+	if got := tlsSubscriptionPendingDomains(subscription); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected pending domains %v, got %v", want, got)
+	}
+}
+
+func TestResourceFastlyTLSSubscriptionValidation_waitTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/tls/subscriptions/sub-id" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		fmt.Fprint(w, `{"data":{"id":"sub-id","type":"tls_subscription","attributes":{"state":"issued"}}}`)
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := gofastly.NewClientForEndpoint("someapikey", server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	meta := &APIClient{conn: client}
+
+	// `wait_timeout` overrides the create timeout, which would otherwise
+	// cancel the wait.
+	r := resourceFastlyTLSSubscriptionValidation()
+	diff, err := r.Diff(context.Background(), nil, terraform.NewResourceConfigRaw(map[string]any{
+		"subscription_id": "sub-id",
+		"wait_timeout":    "1m",
+		"timeouts":        map[string]any{"create": "1ns"},
+	}), meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state, diags := r.Apply(context.Background(), nil, diff, meta)
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if state.ID != "sub-id" {
+		t.Errorf("expected the validation to be created, got %q", state.ID)
+	}
+}
@@ -0,0 +1,284 @@
+package fastly
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultRetryMaxAttempts is the number of times a request is attempted.
+	DefaultRetryMaxAttempts = 3
+	// DefaultRetryMinBackoff is the delay before the first retry.
+	DefaultRetryMinBackoff = 1 * time.Second
+	// DefaultRetryMaxBackoff is the maximum delay between retries.
+	DefaultRetryMaxBackoff = 30 * time.Second
+
+	// headerRateLimitRemaining reports the number of mutating requests left in
+	// the current rate limit window.
+	headerRateLimitRemaining = "Fastly-RateLimit-Remaining"
+	// headerRateLimitReset reports when the current rate limit window resets,
+	// as a Unix timestamp.
+	headerRateLimitReset = "Fastly-RateLimit-Reset"
+	// headerRetryAfter is the standard header indicating how long to wait.
+	headerRetryAfter = "Retry-After"
+)
+
+// RetryConfig controls how failed API requests are retried.
+//
+// NOTE: Zero values are replaced with the defaults above.
+type RetryConfig struct {
+	MaxAttempts int
+	MaxBackoff  time.Duration
+	MinBackoff  time.Duration
+}
+
+// withDefaults returns a copy of the configuration with zero values replaced
+// by the defaults.
+func (c RetryConfig) withDefaults() RetryConfig {
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = DefaultRetryMaxAttempts
+	}
+	if c.MinBackoff <= 0 {
+		c.MinBackoff = DefaultRetryMinBackoff
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = DefaultRetryMaxBackoff
+	}
+	if c.MaxBackoff < c.MinBackoff {
+		c.MaxBackoff = c.MinBackoff
+	}
+	return c
+}
+
+// retryTransport is a http.RoundTripper that retries requests rejected by
+// the Fastly API due to rate limiting (429) or transient server errors (5xx).
+//
+// Requests using a non-idempotent method (POST, PATCH) are only retried when
+// the API explicitly rejected them with a 429, as any other failure may have
+// happened after the request was processed.
+type retryTransport struct {
+	transport http.RoundTripper
+	config    RetryConfig
+
+	// mu guards rateLimitedUntil.
+	mu sync.Mutex
+	// rateLimitedUntil is set when the API reports that no mutating requests
+	// remain in the current rate limit window.
+	rateLimitedUntil time.Time
+}
+
+// newRetryTransport wraps the given transport with retry behaviour.
+func newRetryTransport(t http.RoundTripper, config RetryConfig) *retryTransport {
+	return &retryTransport{
+		transport: t,
+		config:    config.withDefaults(),
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	// A request body can only be replayed if it can be recreated.
+	replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+
+	for attempt := 1; ; attempt++ {
+		// Fastly only rate limits requests that modify state.
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			if err := t.waitForRateLimit(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		r := req
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r = req.Clone(ctx)
+			r.Body = body
+		}
+
+		resp, err := t.transport.RoundTrip(r)
+		if resp != nil {
+			t.recordRateLimit(resp)
+		}
+
+		final := attempt >= t.config.MaxAttempts || !replayable || !shouldRetry(req.Method, resp, err)
+		if final {
+			return resp, err
+		}
+
+		wait := t.backoff(attempt, resp)
+
+		if err != nil {
+			log.Printf("[WARN] Fastly API request %s %s failed (attempt %d/%d), retrying in %s: %s", req.Method, req.URL.Path, attempt, t.config.MaxAttempts, wait, err)
+		} else {
+			// Drain and close the body so the connection can be reused.
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+
+			// The API can ask for waits of up to an hour, which would
+			// otherwise silently stall the apply.
+			if wait > t.config.MaxBackoff {
+				return nil, fmt.Errorf("fastly API request %s %s returned %d and asked to wait %s before retrying, which exceeds the retry max_backoff (%s)", req.Method, req.URL.Path, resp.StatusCode, wait.Round(time.Second), t.config.MaxBackoff)
+			}
+			log.Printf("[WARN] Fastly API request %s %s returned %d (attempt %d/%d), retrying in %s", req.Method, req.URL.Path, resp.StatusCode, attempt, t.config.MaxAttempts, wait)
+		}
+
+		if err := sleepContext(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// backoff returns how long to wait before the next attempt. Retry-After and
+// Fastly-RateLimit-Reset headers take precedence over exponential backoff,
+// so the wait can exceed MaxBackoff.
+func (t *retryTransport) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if d, ok := parseRetryAfter(resp.Header.Get(headerRetryAfter), time.Now()); ok {
+			return d
+		}
+		if resp.StatusCode == http.StatusTooManyRequests {
+			if reset, ok := parseRateLimitReset(resp.Header.Get(headerRateLimitReset)); ok {
+				if d := time.Until(reset); d > 0 {
+					return d
+				}
+			}
+		}
+	}
+
+	d := t.config.MinBackoff
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if d >= t.config.MaxBackoff {
+			return t.config.MaxBackoff
+		}
+	}
+	return d
+}
+
+// recordRateLimit remembers when the rate limit window resets if the API
+// reports that no mutating requests remain.
+func (t *retryTransport) recordRateLimit(resp *http.Response) {
+	remaining := resp.Header.Get(headerRateLimitRemaining)
+	if remaining == "" {
+		return
+	}
+	if n, err := strconv.Atoi(remaining); err != nil || n > 0 {
+		return
+	}
+	reset, ok := parseRateLimitReset(resp.Header.Get(headerRateLimitReset))
+	if !ok {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if reset.After(t.rateLimitedUntil) {
+		log.Printf("[DEBUG] Fastly API rate limit exhausted, delaying mutating requests until %s", reset.Format(time.RFC3339))
+		t.rateLimitedUntil = reset
+	}
+}
+
+// waitForRateLimit blocks until the rate limit window resets, if exhausted.
+// It fails instead if the window resets later than MaxBackoff from now.
+func (t *retryTransport) waitForRateLimit(ctx context.Context) error {
+	t.mu.Lock()
+	until := t.rateLimitedUntil
+	t.mu.Unlock()
+
+	d := time.Until(until)
+	if d <= 0 {
+		return nil
+	}
+	if d > t.config.MaxBackoff {
+		return fmt.Errorf("fastly API rate limit exhausted until %s, which is later than the retry max_backoff (%s) allows waiting", until.Format(time.RFC3339), t.config.MaxBackoff)
+	}
+	log.Printf("[WARN] Fastly API rate limit exhausted, waiting %s until %s", d.Round(time.Second), until.Format(time.RFC3339))
+	return sleepContext(ctx, d)
+}
+
+// shouldRetry reports whether a request should be attempted again.
+func shouldRetry(method string, resp *http.Response, err error) bool {
+	if err != nil {
+		// The request may have reached the API, so only retry if it is safe.
+		return isIdempotentMethod(method)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return isIdempotentMethod(method)
+	}
+	return false
+}
+
+// isIdempotentMethod reports whether requests using the method can be safely
+// repeated.
+func isIdempotentMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// parseRetryAfter parses a Retry-After header, which is either a number of
+// seconds or a HTTP date.
+func parseRetryAfter(v string, now time.Time) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(v); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		d := t.Sub(now)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+// parseRateLimitReset parses the Fastly-RateLimit-Reset header, which is a
+// Unix timestamp in seconds.
+func parseRateLimitReset(v string) (time.Time, bool) {
+	if v == "" {
+		return time.Time{}, false
+	}
+	seconds, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(seconds, 0), true
+}
+
+// sleepContext sleeps for the given duration or until the context is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,225 @@
+package fastly
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestRetryServer returns a server that responds with the given status
+// codes in sequence (the last one is repeated), along with a request counter.
+func newTestRetryServer(t *testing.T, headers http.Header, statuses ...int) (*httptest.Server, *int32) {
+	t.Helper()
+
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(&requests, 1))
+		status := statuses[len(statuses)-1]
+		if n <= len(statuses) {
+			status = statuses[n-1]
+		}
+		if r.Body != nil {
+			body, _ := io.ReadAll(r.Body)
+			if r.Method == http.MethodPut && string(body) != "payload" {
+				t.Errorf("unexpected body on attempt %d: %q", n, body)
+			}
+		}
+		if status != http.StatusOK {
+			for k, v := range headers {
+				w.Header()[k] = v
+			}
+		}
+		w.WriteHeader(status)
+		fmt.Fprintf(w, "attempt %d", n)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &requests
+}
+
+func testRetryClient(config RetryConfig) *http.Client {
+	return &http.Client{Transport: newRetryTransport(http.DefaultTransport, config)}
+}
+
+func TestRetryTransport_retriesSequence(t *testing.T) {
+	for name, testcase := range map[string]struct {
+		method           string
+		statuses         []int
+		expectedStatus   int
+		expectedRequests int32
+	}{
+		"429 then success":            {http.MethodGet, []int{429, 200}, 200, 2},
+		"503 503 then success":        {http.MethodGet, []int{503, 503, 200}, 200, 3},
+		"503 exhausts attempts":       {http.MethodGet, []int{503}, 503, 3},
+		"PUT is retried on 503":       {http.MethodPut, []int{503, 200}, 200, 2},
+		"POST is retried on 429":      {http.MethodPost, []int{429, 200}, 200, 2},
+		"POST is not retried on 503":  {http.MethodPost, []int{503, 200}, 503, 1},
+		"PATCH is not retried on 502": {http.MethodPatch, []int{502, 200}, 502, 1},
+		"404 is not retried":          {http.MethodGet, []int{404, 200}, 404, 1},
+	} {
+		t.Run(name, func(t *testing.T) {
+			server, requests := newTestRetryServer(t, nil, testcase.statuses...)
+			client := testRetryClient(RetryConfig{
+				MaxAttempts: 3,
+				MinBackoff:  time.Millisecond,
+				MaxBackoff:  2 * time.Millisecond,
+			})
+
+			req, err := http.NewRequest(testcase.method, server.URL, strings.NewReader("payload"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != testcase.expectedStatus {
+				t.Errorf("expected status %d, got %d", testcase.expectedStatus, resp.StatusCode)
+			}
+			if got := atomic.LoadInt32(requests); got != testcase.expectedRequests {
+				t.Errorf("expected %d requests, got %d", testcase.expectedRequests, got)
+			}
+		})
+	}
+}
+
+func TestRetryTransport_honoursRetryAfter(t *testing.T) {
+	server, requests := newTestRetryServer(t, http.Header{headerRetryAfter: []string{"1"}}, 429, 200)
+	client := testRetryClient(RetryConfig{
+		MaxAttempts: 2,
+		MinBackoff:  time.Millisecond,
+		MaxBackoff:  2 * time.Second,
+	})
+
+	start := time.Now()
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	resp.Body.Close()
+
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("expected to wait for Retry-After, took %s", elapsed)
+	}
+	if got := atomic.LoadInt32(requests); got != 2 {
+		t.Errorf("expected 2 requests, got %d", got)
+	}
+}
+
+func TestRetryTransport_honoursRateLimitRemaining(t *testing.T) {
+	reset := time.Now().Add(1500 * time.Millisecond).Truncate(time.Second).Add(time.Second)
+
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.Header().Set(headerRateLimitRemaining, "0")
+			w.Header().Set(headerRateLimitReset, strconv.FormatInt(reset.Unix(), 10))
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	client := testRetryClient(RetryConfig{})
+
+	for i := 0; i < 2; i++ {
+		req, err := http.NewRequest(http.MethodPost, server.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		resp.Body.Close()
+	}
+
+	if now := time.Now(); now.Before(reset) {
+		t.Errorf("expected second request to wait until %s, finished at %s", reset, now)
+	}
+}
+
+func TestRetryTransport_waitExceedsMaxBackoff(t *testing.T) {
+	reset := strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+	server, requests := newTestRetryServer(t, http.Header{headerRateLimitReset: []string{reset}}, 429, 200)
+	client := testRetryClient(RetryConfig{MaxAttempts: 3})
+
+	start := time.Now()
+	req, err := http.NewRequest(http.MethodPost, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Do(req)
+	if err == nil {
+		resp.Body.Close()
+		t.Fatal("expected an error")
+	}
+	if !strings.Contains(err.Error(), "exceeds the retry max_backoff (30s)") {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected to fail without waiting, took %s", elapsed)
+	}
+	if got := atomic.LoadInt32(requests); got != 1 {
+		t.Errorf("expected 1 request, got %d", got)
+	}
+
+	// The exhausted window also fails later mutating requests immediately.
+	rt := newRetryTransport(http.DefaultTransport, RetryConfig{})
+	rt.rateLimitedUntil = time.Now().Add(time.Hour)
+	if err := rt.waitForRateLimit(context.Background()); err == nil || !strings.Contains(err.Error(), "rate limit exhausted") {
+		t.Errorf("expected a rate limit error, got %v", err)
+	}
+}
+
+func TestRetryTransport_contextCancelled(t *testing.T) {
+	server, requests := newTestRetryServer(t, http.Header{headerRetryAfter: []string{"60"}}, 503)
+	client := testRetryClient(RetryConfig{MaxAttempts: 3, MaxBackoff: 2 * time.Minute})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp, err := client.Do(req); err == nil {
+		resp.Body.Close()
+		t.Fatal("expected an error")
+	}
+	if got := atomic.LoadInt32(requests); got != 1 {
+		t.Errorf("expected 1 request, got %d", got)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, testcase := range []struct {
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{"", 0, false},
+		{"5", 5 * time.Second, true},
+		{"-1", 0, false},
+		{"Wed, 01 Jan 2025 00:00:10 GMT", 10 * time.Second, true},
+		{"Tue, 31 Dec 2024 23:59:00 GMT", 0, true},
+		{"soon", 0, false},
+	} {
+		t.Run(testcase.value, func(t *testing.T) {
+			d, ok := parseRetryAfter(testcase.value, now)
+			if ok != testcase.ok || d != testcase.expected {
+				t.Errorf("expected (%s, %t), got (%s, %t)", testcase.expected, testcase.ok, d, ok)
+			}
+		})
+	}
+}
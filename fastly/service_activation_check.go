@@ -0,0 +1,326 @@
+package fastly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	gofastly "github.com/fastly/go-fastly/v12/fastly"
+)
+
+const (
+	// activationCheckMaxBodySize limits how much of a probe response is read
+	// when matching `expected_body`.
+	activationCheckMaxBodySize = 1 << 20
+)
+
+// activationCheck is the expanded `activation_check` block.
+type activationCheck struct {
+	AttemptInterval time.Duration
+	InitialDelay    time.Duration
+	MaxAttempts     int
+	Probes          []activationProbe
+}
+
+// activationProbe is a HTTP request expected to succeed once a service
+// version has been activated.
+type activationProbe struct {
+	ExpectedBody    *regexp.Regexp
+	ExpectedHeaders map[string]*regexp.Regexp
+	ExpectedStatus  int
+	Method          string
+	RequestHeaders  map[string]string
+	Timeout         time.Duration
+	URL             string
+}
+
+// activationCheckSchema returns the schema of the `activation_check` block
+// shared by the VCL and Compute service resources.
+func activationCheckSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		MaxItems:    1,
+		Description: "HTTP probes to run after a new version is activated. If any probe fails, the previously active version is activated again and the apply fails.",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"attempt_interval": {
+					Type:             schema.TypeString,
+					Optional:         true,
+					Default:          "5s",
+					Description:      "How long to wait between attempts. Default `5s`.",
+					ValidateDiagFunc: validateDuration(),
+				},
+				"initial_delay": {
+					Type:             schema.TypeString,
+					Optional:         true,
+					Default:          "0s",
+					Description:      "How long to wait after activation before the first attempt, to allow the new version to propagate. Default `0s`.",
+					ValidateDiagFunc: validateDuration(),
+				},
+				"max_attempts": {
+					Type:             schema.TypeInt,
+					Optional:         true,
+					Default:          1,
+					Description:      "How many times the probes are attempted before the activation is considered to have failed. All probes must succeed in the same attempt. Default `1`.",
+					ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(1)),
+				},
+				"probe": {
+					Type:        schema.TypeList,
+					Required:    true,
+					MinItems:    1,
+					Description: "A HTTP request that must succeed once the new version is active.",
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"expected_body": {
+								Type:             schema.TypeString,
+								Optional:         true,
+								Description:      "A regular expression the response body must match.",
+								ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsValidRegExp),
+							},
+							"expected_headers": {
+								Type:        schema.TypeMap,
+								Optional:    true,
+								Elem:        &schema.Schema{Type: schema.TypeString},
+								Description: "A map of response header names to regular expressions the header value must match.",
+							},
+							"expected_status": {
+								Type:        schema.TypeInt,
+								Optional:    true,
+								Default:     http.StatusOK,
+								Description: "The expected HTTP status code of the response. Redirects aren't followed, so a redirect status (e.g. `301`) can be expected. Default `200`.",
+							},
+							"method": {
+								Type:        schema.TypeString,
+								Optional:    true,
+								Default:     http.MethodGet,
+								Description: "The HTTP method of the request. Default `GET`.",
+							},
+							"request_headers": {
+								Type:        schema.TypeMap,
+								Optional:    true,
+								Elem:        &schema.Schema{Type: schema.TypeString},
+								Description: "A map of headers to send with the request. A `Host` header overrides the host of the request (e.g. to probe a domain via a Fastly IP address).",
+							},
+							"timeout": {
+								Type:             schema.TypeString,
+								Optional:         true,
+								Default:          "10s",
+								Description:      "The timeout of the request. Default `10s`.",
+								ValidateDiagFunc: validateDuration(),
+							},
+							"url": {
+								Type:             schema.TypeString,
+								Required:         true,
+								Description:      "The URL to request.",
+								ValidateDiagFunc: validation.ToDiagFunc(validation.IsURLWithHTTPorHTTPS),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// expandActivationCheck converts the `activation_check` block into an
+// activationCheck. It returns nil if the block isn't set.
+func expandActivationCheck(l []any) (*activationCheck, error) {
+	if len(l) == 0 || l[0] == nil {
+		return nil, nil
+	}
+	m := l[0].(map[string]any)
+
+	c := &activationCheck{
+		MaxAttempts: m["max_attempts"].(int),
+	}
+	c.AttemptInterval, _ = time.ParseDuration(m["attempt_interval"].(string))
+	c.InitialDelay, _ = time.ParseDuration(m["initial_delay"].(string))
+
+	for _, v := range m["probe"].([]any) {
+		pm := v.(map[string]any)
+		p := activationProbe{
+			ExpectedHeaders: map[string]*regexp.Regexp{},
+			ExpectedStatus:  pm["expected_status"].(int),
+			Method:          pm["method"].(string),
+			RequestHeaders:  map[string]string{},
+			URL:             pm["url"].(string),
+		}
+		p.Timeout, _ = time.ParseDuration(pm["timeout"].(string))
+
+		if s := pm["expected_body"].(string); s != "" {
+			re, err := regexp.Compile(s)
+			if err != nil {
+				return nil, fmt.Errorf("invalid expected_body for probe %s: %w", p.URL, err)
+			}
+			p.ExpectedBody = re
+		}
+		for k, v := range pm["expected_headers"].(map[string]any) {
+			re, err := regexp.Compile(v.(string))
+			if err != nil {
+				return nil, fmt.Errorf("invalid expected_headers value for header %s of probe %s: %w", k, p.URL, err)
+			}
+			p.ExpectedHeaders[k] = re
+		}
+		for k, v := range pm["request_headers"].(map[string]any) {
+			p.RequestHeaders[k] = v.(string)
+		}
+
+		c.Probes = append(c.Probes, p)
+	}
+
+	return c, nil
+}
+
+// newActivationCheckHTTPClient returns the HTTP client used to send probes,
+// using the given transport (http.DefaultTransport if nil). Redirects aren't
+// followed, so that probes can expect a redirect status.
+func newActivationCheckHTTPClient(transport http.RoundTripper) *http.Client {
+	return &http.Client{
+		Transport: transport,
+		CheckRedirect: func(_ *http.Request, _ []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// run executes the probes until they all succeed in the same attempt, or
+// the attempts are exhausted. It returns the error of the last attempt.
+func (c *activationCheck) run(ctx context.Context, client *http.Client) error {
+	if err := sleepContext(ctx, c.InitialDelay); err != nil {
+		return err
+	}
+
+	var err error
+	for attempt := 1; attempt <= c.MaxAttempts; attempt++ {
+		if attempt > 1 {
+			if err := sleepContext(ctx, c.AttemptInterval); err != nil {
+				return err
+			}
+		}
+
+		var errs []error
+		for _, p := range c.Probes {
+			if err := p.run(ctx, client); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if len(errs) == 0 {
+			log.Printf("[DEBUG] Activation check passed (attempt %d/%d)", attempt, c.MaxAttempts)
+			return nil
+		}
+
+		err = errors.Join(errs...)
+		log.Printf("[DEBUG] Activation check failed (attempt %d/%d): %s", attempt, c.MaxAttempts, err)
+	}
+	return err
+}
+
+// run sends the probe request and checks the response.
+func (p activationProbe) run(ctx context.Context, client *http.Client) error {
+	if p.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.Timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, p.Method, p.URL, nil)
+	if err != nil {
+		return fmt.Errorf("probe %s %s: %w", p.Method, p.URL, err)
+	}
+	for k, v := range p.RequestHeaders {
+		if strings.EqualFold(k, "Host") {
+			req.Host = v
+			continue
+		}
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("probe %s %s: %w", p.Method, p.URL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != p.ExpectedStatus {
+		return fmt.Errorf("probe %s %s: expected status %d, got %d", p.Method, p.URL, p.ExpectedStatus, resp.StatusCode)
+	}
+	for k, re := range p.ExpectedHeaders {
+		if v := resp.Header.Get(k); !re.MatchString(v) {
+			return fmt.Errorf("probe %s %s: expected header %s to match %q, got %q", p.Method, p.URL, k, re, v)
+		}
+	}
+	if p.ExpectedBody != nil {
+		body, err := io.ReadAll(io.LimitReader(resp.Body, activationCheckMaxBodySize))
+		if err != nil {
+			return fmt.Errorf("probe %s %s: error reading body: %w", p.Method, p.URL, err)
+		}
+		if !p.ExpectedBody.Match(body) {
+			return fmt.Errorf("probe %s %s: expected body to match %q", p.Method, p.URL, p.ExpectedBody)
+		}
+	}
+
+	return nil
+}
+
+// checkServiceActivation runs the configured activation checks against the
+// newly activated version. If they fail, the previously active version is
+// activated again and the failure is returned as a diagnostic.
+func checkServiceActivation(ctx context.Context, d *schema.ResourceData, conn *gofastly.Client, client *http.Client, activated, previous int) diag.Diagnostics {
+	check, err := expandActivationCheck(d.Get("activation_check").([]any))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if check == nil {
+		return nil
+	}
+
+	log.Printf("[DEBUG] Running activation check for Fastly Service (%s), Version (%d)", d.Id(), activated)
+	checkErr := check.run(ctx, client)
+	if checkErr == nil {
+		return nil
+	}
+
+	if previous == 0 {
+		return diag.Diagnostics{{
+			Severity: diag.Error,
+			Summary:  "Activation check failed",
+			Detail:   fmt.Sprintf("Version %d of Fastly Service (%s) failed the activation check, and there is no previously active version to roll back to: %s", activated, d.Id(), checkErr),
+		}}
+	}
+
+	log.Printf("[DEBUG] Rolling back Fastly Service (%s) from Version (%d) to Version (%d)", d.Id(), activated, previous)
+	_, err = conn.ActivateVersion(gofastly.NewContextForResourceID(ctx, d.Id()), &gofastly.ActivateVersionInput{
+		ServiceID:      d.Id(),
+		ServiceVersion: previous,
+	})
+	if err != nil {
+		return diag.Diagnostics{{
+			Severity: diag.Error,
+			Summary:  "Activation check failed and rollback failed",
+			Detail:   fmt.Sprintf("Version %d of Fastly Service (%s) failed the activation check: %s\n\nError activating the previous version (%d): %s", activated, d.Id(), checkErr, previous, err),
+		}}
+	}
+
+	// The previous version is active again, so the next plan recreates the
+	// changes in a new version.
+	if err := d.Set("active_version", previous); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diag.Diagnostics{{
+		Severity: diag.Error,
+		Summary:  "Activation check failed",
+		Detail:   fmt.Sprintf("Version %d of Fastly Service (%s) failed the activation check and version %d has been activated again: %s", activated, d.Id(), previous, checkErr),
+	}}
+}
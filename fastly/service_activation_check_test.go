@@ -0,0 +1,216 @@
+package fastly
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	gofastly "github.com/fastly/go-fastly/v12/fastly"
+)
+
+// newTestEdgeServer returns a server standing in for the Fastly edge, which
+// responds with the given status after the given number of failed requests.
+func newTestEdgeServer(t *testing.T, failures int32) *httptest.Server {
+	t.Helper()
+
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("X-Served-By", "cache-"+r.Host)
+		fmt.Fprint(w, "version: 2")
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func testActivationCheckData(t *testing.T, probe map[string]any) *schema.ResourceData {
+	t.Helper()
+
+	s := map[string]*schema.Schema{
+		"activation_check": activationCheckSchema(),
+		"active_version": {
+			Type:     schema.TypeInt,
+			Computed: true,
+		},
+	}
+	d := schema.TestResourceDataRaw(t, s, map[string]any{
+		"activation_check": []any{
+			map[string]any{
+				"attempt_interval": "10ms",
+				"max_attempts":     2,
+				"probe":            []any{probe},
+			},
+		},
+	})
+	d.SetId("123")
+	return d
+}
+
+func TestActivationCheck(t *testing.T) {
+	cases := []struct {
+		name     string
+		failures int32
+		probe    map[string]any
+		wantErr  string
+	}{
+		{
+			name: "pass",
+			probe: map[string]any{
+				"expected_body":    "version: 2",
+				"expected_headers": map[string]any{"X-Served-By": "^cache-example.com$"},
+				"request_headers":  map[string]any{"Host": "example.com"},
+			},
+		},
+		{
+			name:     "pass after retry",
+			failures: 1,
+			probe:    map[string]any{},
+		},
+		{
+			name:     "status mismatch",
+			failures: 2,
+			probe:    map[string]any{},
+			wantErr:  "expected status 200, got 503",
+		},
+		{
+			name: "header mismatch",
+			probe: map[string]any{
+				"expected_headers": map[string]any{"X-Served-By": "^cache-example.net$"},
+			},
+			wantErr: "expected header X-Served-By",
+		},
+		{
+			name: "body mismatch",
+			probe: map[string]any{
+				"expected_body": "version: 3",
+			},
+			wantErr: "expected body to match",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			server := newTestEdgeServer(t, c.failures)
+			c.probe["url"] = server.URL
+
+			check, err := expandActivationCheck(testActivationCheckData(t, c.probe).Get("activation_check").([]any))
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			err = check.run(context.Background(), server.Client())
+			switch {
+			case c.wantErr == "" && err != nil:
+				t.Fatalf("unexpected error: %s", err)
+			case c.wantErr != "" && err == nil:
+				t.Fatalf("expected error containing %q", c.wantErr)
+			case c.wantErr != "" && !strings.Contains(err.Error(), c.wantErr):
+				t.Fatalf("expected error containing %q, got %s", c.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestActivationCheck_timeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	t.Cleanup(server.Close)
+
+	probe := activationProbe{
+		ExpectedStatus: http.StatusOK,
+		Method:         http.MethodGet,
+		Timeout:        10 * time.Millisecond,
+		URL:            server.URL,
+	}
+	if err := probe.run(context.Background(), server.Client()); err == nil {
+		t.Fatal("expected a timeout error")
+	}
+}
+
+func TestActivationCheck_redirect(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/new", http.StatusMovedPermanently)
+			return
+		}
+		fmt.Fprint(w, "new")
+	}))
+	t.Cleanup(server.Close)
+
+	probe := activationProbe{
+		ExpectedHeaders: map[string]*regexp.Regexp{"Location": regexp.MustCompile("^/new$")},
+		ExpectedStatus:  http.StatusMovedPermanently,
+		Method:          http.MethodGet,
+		URL:             server.URL + "/old",
+	}
+	if err := probe.run(context.Background(), newActivationCheckHTTPClient(nil)); err != nil {
+		t.Fatalf("expected the redirect not to be followed: %s", err)
+	}
+}
+
+func TestCheckServiceActivation_rollback(t *testing.T) {
+	edge := newTestEdgeServer(t, 2)
+
+	var activated int32
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != "/service/123/version/1/activate" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		atomic.AddInt32(&activated, 1)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"service_id":"123","number":1,"active":true}`)
+	}))
+	t.Cleanup(api.Close)
+
+	conn, err := gofastly.NewClientForEndpoint("someapikey", api.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := testActivationCheckData(t, map[string]any{"url": edge.URL})
+	if err := d.Set("active_version", 2); err != nil {
+		t.Fatal(err)
+	}
+
+	diags := checkServiceActivation(context.Background(), d, conn, edge.Client(), 2, 1)
+	if !diags.HasError() {
+		t.Fatal("expected the activation check to fail")
+	}
+	if !strings.Contains(diags[0].Detail, "version 1 has been activated again") {
+		t.Errorf("unexpected diagnostic: %s", diags[0].Detail)
+	}
+	if got := atomic.LoadInt32(&activated); got != 1 {
+		t.Errorf("expected the previous version to be activated once, got %d", got)
+	}
+	if got := d.Get("active_version").(int); got != 1 {
+		t.Errorf("expected active_version 1, got %d", got)
+	}
+}
+
+func TestCheckServiceActivation_noPreviousVersion(t *testing.T) {
+	edge := newTestEdgeServer(t, 2)
+	d := testActivationCheckData(t, map[string]any{"url": edge.URL})
+
+	// NOTE: No API requests are expected, so no client is needed.
+	diags := checkServiceActivation(context.Background(), d, nil, edge.Client(), 1, 0)
+	if !diags.HasError() {
+		t.Fatal("expected the activation check to fail")
+	}
+	if !strings.Contains(diags[0].Detail, "no previously active version") {
+		t.Errorf("unexpected diagnostic: %s", diags[0].Detail)
+	}
+}
@@ -0,0 +1,204 @@
+package fastly
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	gofastly "github.com/fastly/go-fastly/v12/fastly"
+)
+
+// DefaultMaxConcurrentRequests is the default number of API requests made
+// concurrently when applying the nested blocks of a service, which processes
+// them one at a time.
+const DefaultMaxConcurrentRequests = 1
+
+// orderedServiceAttributes are the nested blocks that other blocks depend on
+// (e.g. a backend referencing a healthcheck, or a director referencing a
+// backend). They are processed on their own, in the order the attribute
+// handlers are defined, rather than concurrently with other blocks.
+var orderedServiceAttributes = map[string]bool{
+	"backend":            true,
+	"condition":          true,
+	"director":           true,
+	"healthcheck":        true,
+	"product_enablement": true,
+}
+
+// serviceOperation is a single create, update or delete of a nested block.
+type serviceOperation func(ctx context.Context) error
+
+// processServiceAttributes applies the changes of every attribute handler that
+// must be processed to the given service version.
+//
+// Handlers are split into stages (see serviceAttributeStages). The stages run
+// one after another, and within a stage the deletions, creations and updates
+// of all handlers run in turn, with up to maxConcurrent API requests in
+// flight at once.
+func processServiceAttributes(ctx context.Context, d *schema.ResourceData, handlers []ServiceAttributeDefinition, initialVersion bool, serviceVersion, maxConcurrent int, conn *gofastly.Client) error {
+	var pending []ServiceAttributeDefinition
+	for _, a := range handlers {
+		if a.MustProcess(d, initialVersion) {
+			pending = append(pending, a)
+		}
+	}
+
+	for _, stage := range serviceAttributeStages(pending) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// NOTE: Handlers that don't expose their operations manage their own
+		// requests, and are always in a stage on their own.
+		if len(stage) == 1 {
+			if _, ok := stage[0].(*blockSetAttributeHandler); !ok {
+				if err := stage[0].Process(ctx, d, serviceVersion, conn); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		// The operations are planned up front, as the resource data can't be
+		// read concurrently.
+		var phases [3][]serviceOperation
+		for _, a := range stage {
+			ops, err := a.(*blockSetAttributeHandler).plan(d, serviceVersion, conn)
+			if err != nil {
+				return err
+			}
+			for i, phase := range ops {
+				phases[i] = append(phases[i], phase...)
+			}
+		}
+
+		for _, phase := range phases {
+			if err := runServiceOperations(ctx, maxConcurrent, phase); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// serviceAttributeStages groups the handlers into stages that can be processed
+// concurrently. Consecutive nested blocks that nothing else depends on share a
+// stage, while ordered blocks (see orderedServiceAttributes) and handlers that
+// aren't nested blocks (e.g. settings and package) get a stage of their own.
+func serviceAttributeStages(handlers []ServiceAttributeDefinition) [][]ServiceAttributeDefinition {
+	var (
+		stages  [][]ServiceAttributeDefinition
+		current []ServiceAttributeDefinition
+	)
+	for _, a := range handlers {
+		if h, ok := a.(*blockSetAttributeHandler); ok && !orderedServiceAttributes[h.handler.Key()] {
+			current = append(current, a)
+			continue
+		}
+		if len(current) > 0 {
+			stages = append(stages, current)
+			current = nil
+		}
+		stages = append(stages, []ServiceAttributeDefinition{a})
+	}
+	if len(current) > 0 {
+		stages = append(stages, current)
+	}
+	return stages
+}
+
+// runServiceOperations runs the operations with at most maxConcurrent running
+// at once. After an operation fails no further operations are started, and
+// the errors of the operations already running are returned together.
+func runServiceOperations(ctx context.Context, maxConcurrent int, ops []serviceOperation) error {
+	if maxConcurrent < 1 {
+		maxConcurrent = 1
+	}
+
+	var (
+		errs      []error
+		failed    bool
+		cancelled error
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+	)
+	sem := make(chan struct{}, maxConcurrent)
+
+	for _, op := range ops {
+		sem <- struct{}{}
+
+		mu.Lock()
+		stop := failed
+		mu.Unlock()
+		if stop {
+			<-sem
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			<-sem
+			cancelled = err
+			break
+		}
+
+		wg.Add(1)
+		go func(op serviceOperation) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if err := op(ctx); err != nil {
+				mu.Lock()
+				failed = true
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}(op)
+	}
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	return cancelled
+}
+
+// createServiceBlocks creates the given nested blocks, keyed by block type, in
+// a new service version using the Create operation of each block's attribute
+// handler, in the same stages as processServiceAttributes. Handlers that
+// aren't nested blocks (e.g. settings and package) are processed from the
+// resource data instead.
+func createServiceBlocks(ctx context.Context, d *schema.ResourceData, handlers []ServiceAttributeDefinition, blocks map[string][]map[string]any, serviceVersion, maxConcurrent int, conn *gofastly.Client) error {
+	for _, stage := range serviceAttributeStages(handlers) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var ops []serviceOperation
+		for _, a := range stage {
+			bh, ok := a.(*blockSetAttributeHandler)
+			if !ok {
+				if err := a.Process(ctx, d, serviceVersion, conn); err != nil {
+					return err
+				}
+				continue
+			}
+			h := bh.handler
+			for _, block := range blocks[h.Key()] {
+				block := block
+				ops = append(ops, func(ctx context.Context) error {
+					log.Printf("[DEBUG] Creating %s (%v) in version %d of Fastly Service (%s)", h.Key(), block["name"], serviceVersion, d.Id())
+					return h.Create(ctx, d, block, serviceVersion, conn)
+				})
+			}
+		}
+		if err := runServiceOperations(ctx, maxConcurrent, ops); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,182 @@
+package fastly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	gofastly "github.com/fastly/go-fastly/v12/fastly"
+)
+
+// testConcurrentHandler is a ServiceCRUDAttributeDefinition that records the
+// blocks it creates and the peak number of concurrent requests.
+type testConcurrentHandler struct {
+	key string
+
+	mu       sync.Mutex
+	created  []string
+	inFlight int32
+	peak     int32
+	fail     string
+}
+
+func (h *testConcurrentHandler) Key() string { return h.key }
+
+func (h *testConcurrentHandler) GetSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeSet,
+		Optional: true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"name": {
+					Type:     schema.TypeString,
+					Required: true,
+				},
+			},
+		},
+	}
+}
+
+func (h *testConcurrentHandler) Create(_ context.Context, _ *schema.ResourceData, resource map[string]any, _ int, _ *gofastly.Client) error {
+	n := atomic.AddInt32(&h.inFlight, 1)
+	defer atomic.AddInt32(&h.inFlight, -1)
+	for {
+		peak := atomic.LoadInt32(&h.peak)
+		if n <= peak || atomic.CompareAndSwapInt32(&h.peak, peak, n) {
+			break
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	name := resource["name"].(string)
+	if h.fail != "" && strings.HasPrefix(name, h.fail) {
+		return fmt.Errorf("error creating %s", name)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.created = append(h.created, name)
+	return nil
+}
+
+func (h *testConcurrentHandler) Read(_ context.Context, _ *schema.ResourceData, _ map[string]any, _ int, _ *gofastly.Client) error {
+	return nil
+}
+
+func (h *testConcurrentHandler) Update(_ context.Context, _ *schema.ResourceData, _, _ map[string]any, _ int, _ *gofastly.Client) error {
+	return nil
+}
+
+func (h *testConcurrentHandler) Delete(_ context.Context, _ *schema.ResourceData, _ map[string]any, _ int, _ *gofastly.Client) error {
+	return nil
+}
+
+func testConcurrentHandlerData(t *testing.T, handler *testConcurrentHandler, blocks int) *schema.ResourceData {
+	t.Helper()
+
+	var l []any
+	for i := 0; i < blocks; i++ {
+		l = append(l, map[string]any{"name": fmt.Sprintf("%s-%02d", handler.key, i)})
+	}
+	d := schema.TestResourceDataRaw(t, map[string]*schema.Schema{
+		handler.key: handler.GetSchema(),
+	}, map[string]any{
+		handler.key: l,
+	})
+	d.SetId("123")
+	return d
+}
+
+func TestServiceAttributeStages(t *testing.T) {
+	var keys []string
+	for _, stage := range serviceAttributeStages(vclService.Attributes[:12]) {
+		var s []string
+		for _, a := range stage {
+			switch h := a.(type) {
+			case *blockSetAttributeHandler:
+				s = append(s, h.handler.Key())
+			default:
+				s = append(s, fmt.Sprintf("%T", a))
+			}
+		}
+		keys = append(keys, strings.Join(s, ","))
+	}
+
+	want := []string{
+		"*fastly.SettingsServiceAttributeHandler",
+		"condition",
+		"domain",
+		"healthcheck",
+		"backend",
+		"product_enablement",
+		"image_optimizer_default_settings",
+		"director",
+		"header,gzip,logging_s3,logging_papertrail",
+	}
+	if got := strings.Join(keys, "\n"); got != strings.Join(want, "\n") {
+		t.Errorf("expected stages:\n%s\ngot:\n%s", strings.Join(want, "\n"), got)
+	}
+}
+
+func TestProcessServiceAttributes(t *testing.T) {
+	for _, maxConcurrent := range []int32{1, 4} {
+		t.Run(fmt.Sprintf("max %d", maxConcurrent), func(t *testing.T) {
+			handler := &testConcurrentHandler{key: "testblock"}
+			d := testConcurrentHandlerData(t, handler, 10)
+
+			err := processServiceAttributes(context.Background(), d, []ServiceAttributeDefinition{ToServiceAttributeDefinition(handler)}, false, 2, int(maxConcurrent), nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if len(handler.created) != 10 {
+				t.Errorf("expected 10 blocks to be created, got %d", len(handler.created))
+			}
+			if handler.peak != maxConcurrent {
+				t.Errorf("expected at most %d concurrent requests, got %d", maxConcurrent, handler.peak)
+			}
+		})
+	}
+}
+
+func TestProcessServiceAttributes_error(t *testing.T) {
+	handler := &testConcurrentHandler{key: "testblock", fail: "testblock"}
+	d := testConcurrentHandlerData(t, handler, 10)
+
+	err := processServiceAttributes(context.Background(), d, []ServiceAttributeDefinition{ToServiceAttributeDefinition(handler)}, false, 2, 2, nil)
+	if err == nil || !strings.Contains(err.Error(), "error creating testblock-") {
+		t.Fatalf("expected an error creating a block, got %v", err)
+	}
+
+	// No further requests are started after the first failure, but the
+	// request already in flight completes.
+	if n := strings.Count(err.Error(), "error creating"); n > 2 {
+		t.Errorf("expected processing to stop after the first failure, got %d errors", n)
+	}
+}
+
+func TestRunServiceOperations_cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var ran int32
+	ops := []serviceOperation{
+		func(context.Context) error {
+			atomic.AddInt32(&ran, 1)
+			return nil
+		},
+	}
+	if err := runServiceOperations(ctx, 2, ops); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if ran != 0 {
+		t.Errorf("expected no operations to run, ran %d", ran)
+	}
+}
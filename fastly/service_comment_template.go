@@ -0,0 +1,137 @@
+package fastly
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// commentTemplatePlaceholder matches a placeholder of a comment template, e.g.
+// `{{timestamp}}` or `{{env.GIT_SHA}}`.
+var commentTemplatePlaceholder = regexp.MustCompile(`\{\{\s*([^{}]*?)\s*\}\}`)
+
+// commentTemplateEnvVar matches the name of an environment variable
+// referenced by an `env.NAME` placeholder.
+var commentTemplateEnvVar = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// renderCommentTemplate replaces the placeholders of a comment template:
+//
+//   - `{{workspace}}` is the Terraform workspace, taken from the TF_WORKSPACE
+//     or TFC_WORKSPACE_NAME (HCP Terraform) environment variable. Terraform
+//     doesn't set either for `terraform workspace select`, so the placeholder
+//     is an error if neither is set.
+//   - `{{env.NAME}}` is the value of the NAME environment variable, e.g. the
+//     git SHA of a CI pipeline run.
+//   - `{{timestamp}}` is the given time in RFC 3339 format (UTC).
+func renderCommentTemplate(tmpl string, now time.Time, getenv func(string) string) (string, error) {
+	var errs []string
+	out := commentTemplatePlaceholder.ReplaceAllStringFunc(tmpl, func(m string) string {
+		name := commentTemplatePlaceholder.FindStringSubmatch(m)[1]
+		switch {
+		case name == "workspace":
+			for _, k := range []string{"TF_WORKSPACE", "TFC_WORKSPACE_NAME"} {
+				if ws := getenv(k); ws != "" {
+					return ws
+				}
+			}
+			errs = append(errs, fmt.Sprintf("%s requires the TF_WORKSPACE or TFC_WORKSPACE_NAME environment variable to be set (or interpolate terraform.workspace instead)", m))
+			return m
+		case name == "timestamp":
+			return now.UTC().Format(time.RFC3339)
+		case strings.HasPrefix(name, "env.") && commentTemplateEnvVar.MatchString(strings.TrimPrefix(name, "env.")):
+			return getenv(strings.TrimPrefix(name, "env."))
+		}
+		errs = append(errs, fmt.Sprintf("unknown placeholder %q", m))
+		return m
+	})
+	if len(errs) > 0 {
+		return "", fmt.Errorf("invalid comment template %q: %s", tmpl, strings.Join(errs, ", "))
+	}
+	return out, nil
+}
+
+// validateCommentTemplate validates the placeholders of a comment template.
+func validateCommentTemplate() schema.SchemaValidateDiagFunc {
+	return func(v any, path cty.Path) diag.Diagnostics {
+		if _, err := renderCommentTemplate(v.(string), time.Now(), os.Getenv); err != nil {
+			return diag.Diagnostics{{
+				Severity:      diag.Error,
+				Summary:       err.Error(),
+				AttributePath: path,
+			}}
+		}
+		return nil
+	}
+}
+
+// configIsNull reports whether an attribute isn't set in the configuration of
+// a resource.
+func configIsNull(config cty.Value, key string) bool {
+	return config.IsNull() || !config.IsKnown() || config.GetAttr(key).IsNull()
+}
+
+// customizeDiffServiceComments applies the provider's default service and
+// version comments to service resources that don't set `comment` or
+// `version_comment`.
+//
+// The default service comment is only rendered when the service is created,
+// so that placeholders such as `{{timestamp}}` don't cause a diff on every
+// plan. Without a provider default, an unset `comment` is reset to "Managed
+// by Terraform" and an unset `version_comment` is cleared, as they were
+// before the provider defaults existed.
+func customizeDiffServiceComments(_ context.Context, rd *schema.ResourceDiff, meta any) error {
+	var serviceTemplate, versionTemplate string
+	if client, ok := meta.(*APIClient); ok {
+		serviceTemplate = client.defaultServiceComment
+		versionTemplate = client.defaultVersionComment
+	}
+	config := rd.GetRawConfig()
+
+	if configIsNull(config, "comment") {
+		switch {
+		case serviceTemplate != "" && rd.Id() == "":
+			comment, err := renderCommentTemplate(serviceTemplate, time.Now(), os.Getenv)
+			if err != nil {
+				return err
+			}
+			if err := rd.SetNew("comment", comment); err != nil {
+				return err
+			}
+		case serviceTemplate == "" && (rd.Id() == "" || rd.Get("comment").(string) != ManagedByTerraform):
+			if err := rd.SetNew("comment", ManagedByTerraform); err != nil {
+				return err
+			}
+		}
+	}
+
+	if configIsNull(config, "version_comment") {
+		switch {
+		case versionTemplate != "" && rd.HasChange("cloned_version"):
+			// The comment is rendered when the new version is created.
+			return rd.SetNewComputed("version_comment")
+		case versionTemplate == "" && (rd.Id() == "" || rd.Get("version_comment").(string) != ""):
+			return rd.SetNew("version_comment", "")
+		}
+	}
+
+	return nil
+}
+
+// serviceVersionComment returns the comment of new versions of a service: the
+// configured `version_comment`, or else the rendered default version comment
+// of the provider, in which case isDefault is true.
+func serviceVersionComment(d *schema.ResourceData, meta any) (comment string, isDefault bool, err error) {
+	client, ok := meta.(*APIClient)
+	if !ok || client.defaultVersionComment == "" || d.Get("version_comment").(string) != "" || !configIsNull(d.GetRawConfig(), "version_comment") {
+		return d.Get("version_comment").(string), false, nil
+	}
+	comment, err = renderCommentTemplate(client.defaultVersionComment, time.Now(), os.Getenv)
+	return comment, true, err
+}
@@ -0,0 +1,75 @@
+package fastly
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestRenderCommentTemplate(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 30, 0, 0, time.FixedZone("CEST", 2*60*60))
+	env := map[string]string{
+		"GIT_SHA":      "0a1b2c3",
+		"TF_WORKSPACE": "staging",
+	}
+	getenv := func(k string) string { return env[k] }
+
+	for tmpl, want := range map[string]string{
+		"":                                "",
+		"Managed by Terraform":            "Managed by Terraform",
+		"{{workspace}}@{{ env.GIT_SHA }}": "staging@0a1b2c3",
+		"Applied at {{timestamp}}":        "Applied at 2024-05-01T10:30:00Z",
+		"{{env.UNSET}}":                   "",
+	} {
+		got, err := renderCommentTemplate(tmpl, now, getenv)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", tmpl, err)
+		}
+		if got != want {
+			t.Errorf("%q: expected %q, got %q", tmpl, want, got)
+		}
+	}
+
+	// The workspace of HCP Terraform runs is set in TFC_WORKSPACE_NAME.
+	if got, _ := renderCommentTemplate("{{workspace}}", now, func(k string) string {
+		return map[string]string{"TFC_WORKSPACE_NAME": "production"}[k]
+	}); got != "production" {
+		t.Errorf("expected the HCP Terraform workspace, got %q", got)
+	}
+
+	// `terraform workspace select` doesn't set any environment variable.
+	if _, err := renderCommentTemplate("{{workspace}}", now, func(string) string { return "" }); err == nil || !strings.Contains(err.Error(), "TFC_WORKSPACE_NAME") {
+		t.Errorf("expected an error without a workspace, got %v", err)
+	}
+
+	for _, tmpl := range []string{"{{sha}}", "{{env.}}", "{{env.A-B}}"} {
+		if _, err := renderCommentTemplate(tmpl, now, getenv); err == nil {
+			t.Errorf("%q: expected an error", tmpl)
+		}
+	}
+}
+
+func TestServiceVersionComment(t *testing.T) {
+	t.Setenv("GIT_SHA", "0a1b2c3")
+	client := &APIClient{defaultVersionComment: "Deployed {{env.GIT_SHA}}"}
+	s := resourceService(vclService).Schema
+
+	d := schema.TestResourceDataRaw(t, s, map[string]any{"name": "example"})
+	comment, isDefault, err := serviceVersionComment(d, client)
+	if err != nil || !isDefault || comment != "Deployed 0a1b2c3" {
+		t.Errorf("expected the default version comment, got %q, %t, %v", comment, isDefault, err)
+	}
+
+	d = schema.TestResourceDataRaw(t, s, map[string]any{"name": "example", "version_comment": "hotfix"})
+	comment, isDefault, err = serviceVersionComment(d, client)
+	if err != nil || isDefault || comment != "hotfix" {
+		t.Errorf("expected the configured version comment, got %q, %t, %v", comment, isDefault, err)
+	}
+
+	d = schema.TestResourceDataRaw(t, s, map[string]any{"name": "example"})
+	if comment, isDefault, _ = serviceVersionComment(d, &APIClient{}); isDefault || comment != "" {
+		t.Errorf("expected no version comment without a provider default, got %q", comment)
+	}
+}
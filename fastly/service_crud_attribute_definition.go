@@ -79,6 +79,25 @@ func (h *blockSetAttributeHandler) Read(ctx context.Context, d *schema.ResourceD
 }
 
 func (h *blockSetAttributeHandler) Process(ctx context.Context, d *schema.ResourceData, serviceVersion int, conn *gofastly.Client) error {
+	ops, err := h.plan(d, serviceVersion, conn)
+	if err != nil {
+		return err
+	}
+	for _, phase := range ops {
+		if err := runServiceOperations(ctx, 1, phase); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// plan diffs the nested blocks and returns the operations required to apply
+// the changes, grouped into phases: deletions, then creations, then updates.
+// Operations in the same phase are independent of each other.
+//
+// NOTE: plan reads from d, which is not safe for concurrent use, so only the
+// returned operations may be run concurrently.
+func (h *blockSetAttributeHandler) plan(d *schema.ResourceData, serviceVersion int, conn *gofastly.Client) ([][]serviceOperation, error) {
 	oldVal, newVal := d.GetChange(h.handler.Key())
 	if oldVal == nil {
 		oldVal = new(schema.Set)
@@ -100,35 +119,34 @@ func (h *blockSetAttributeHandler) Process(ctx context.Context, d *schema.Resour
 
 	diffResult, err := setDiff.Diff(oldSet, newSet)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	var deletes, creates, updates []serviceOperation
+
 	for _, resource := range diffResult.Deleted {
 		resource := resource.(map[string]any)
-		err := h.handler.Delete(ctx, d, resource, serviceVersion, conn)
-		if err != nil {
-			return err
-		}
+		deletes = append(deletes, func(ctx context.Context) error {
+			return h.handler.Delete(ctx, d, resource, serviceVersion, conn)
+		})
 	}
 
 	for _, resource := range diffResult.Added {
 		resource := resource.(map[string]any)
-		err := h.handler.Create(ctx, d, resource, serviceVersion, conn)
-		if err != nil {
-			return err
-		}
+		creates = append(creates, func(ctx context.Context) error {
+			return h.handler.Create(ctx, d, resource, serviceVersion, conn)
+		})
 	}
 
 	for _, resource := range diffResult.Modified {
 		resource := resource.(map[string]any)
 		modified := setDiff.Filter(resource, oldSet)
-		err := h.handler.Update(ctx, d, resource, modified, serviceVersion, conn)
-		if err != nil {
-			return err
-		}
+		updates = append(updates, func(ctx context.Context) error {
+			return h.handler.Update(ctx, d, resource, modified, serviceVersion, conn)
+		})
 	}
 
-	return nil
+	return [][]serviceOperation{deletes, creates, updates}, nil
 }
 
 func (h *blockSetAttributeHandler) HasChange(d *schema.ResourceData) bool {
@@ -0,0 +1,199 @@
+package fastly
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	gofastly "github.com/fastly/go-fastly/v12/fastly"
+)
+
+const (
+	// DriftPolicyAdopt silently adopts versions activated outside of Terraform.
+	DriftPolicyAdopt = "adopt"
+	// DriftPolicyWarn adopts versions activated outside of Terraform, with a
+	// warning.
+	DriftPolicyWarn = "warn"
+	// DriftPolicyError fails when a version was activated outside of Terraform.
+	DriftPolicyError = "error"
+
+	// versionActivateEvent is the event type logged when a version is
+	// activated.
+	versionActivateEvent = "version.activate"
+)
+
+// driftPolicySchema returns the schema of the `drift_policy` attribute shared
+// by the VCL and Compute service resources.
+func driftPolicySchema() *schema.Schema {
+	return &schema.Schema{
+		Type:             schema.TypeString,
+		Optional:         true,
+		Default:          DriftPolicyAdopt,
+		Description:      "What to do when a version of the service was activated outside of Terraform (e.g. in the Fastly UI or CLI). One of `adopt` (the new version is used as the base of further changes), `warn` (as `adopt`, with a warning naming the version, who activated it and when) or `error` (the plan fails, so that further changes don't overwrite the new version unknowingly). Only applies when `activate` is `true`. Default `adopt`",
+		ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice([]string{DriftPolicyAdopt, DriftPolicyWarn, DriftPolicyError}, false)),
+	}
+}
+
+// driftSchema returns the schema of the `drift` attribute, which records a
+// version activated outside of Terraform.
+func driftSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "Describes the version activated outside of Terraform found by the last refresh, if any. Used to apply `drift_policy` at plan time",
+	}
+}
+
+// serviceActivation describes an activation of a service version.
+type serviceActivation struct {
+	By string
+	At *time.Time
+}
+
+// checkServiceDrift describes a version activated outside of Terraform, which
+// is recorded in `drift` so that the drift policy of the configuration can be
+// applied at plan time, and returns a warning if the policy of the prior state
+// is `warn`. previous is the active version known to Terraform.
+func checkServiceDrift(ctx context.Context, d *schema.ResourceData, conn *gofastly.Client, version *gofastly.Version, previous int) (string, diag.Diagnostics) {
+	activation := serviceActivation{At: version.UpdatedAt}
+	if event := lookupActivationEvent(ctx, conn, d.Id(), gofastly.ToValue(version.Number)); event != nil {
+		if event.CreatedAt != nil {
+			activation.At = event.CreatedAt
+		}
+		activation.By = lookupEventUser(ctx, conn, event)
+	}
+
+	drift := serviceDriftDetail(d.Id(), previous, gofastly.ToValue(version.Number), activation)
+	policy, _ := d.Get("drift_policy").(string)
+	return drift, serviceDriftDiagnostics(policy, drift)
+}
+
+// serviceDriftDetail describes a version activated outside of Terraform.
+func serviceDriftDetail(serviceID string, previous, current int, activation serviceActivation) string {
+	by := "an unknown user"
+	if activation.By != "" {
+		by = activation.By
+	}
+	at := "an unknown time"
+	if activation.At != nil {
+		at = activation.At.UTC().Format(time.RFC3339)
+	}
+	return fmt.Sprintf("Version %d of Fastly Service (%s) was activated outside of Terraform by %s at %s, replacing version %d.", current, serviceID, by, at, previous)
+}
+
+// serviceDriftDiagnostics returns the warning of a version activated outside
+// of Terraform for the `warn` drift policy. The `error` policy is applied by
+// customizeDiffServiceDrift.
+func serviceDriftDiagnostics(policy, drift string) diag.Diagnostics {
+	if policy != DriftPolicyWarn || drift == "" {
+		return nil
+	}
+	return diag.Diagnostics{{
+		Severity: diag.Warning,
+		Summary:  "Service version activated outside of Terraform",
+		Detail:   drift + " Further changes will be applied to a clone of the new version.",
+	}}
+}
+
+// customizeDiffServiceDrift fails the plan if the refresh found a version
+// activated outside of Terraform and the configured `drift_policy` is
+// `error`. The policy is read from the configuration rather than the prior
+// state, so that changing it takes effect in the same plan.
+func customizeDiffServiceDrift(_ context.Context, rd *schema.ResourceDiff, _ any) error {
+	policy, _ := rd.Get("drift_policy").(string)
+	drift, _ := rd.Get("drift").(string)
+	return serviceDriftError(policy, drift)
+}
+
+// serviceDriftError returns the error of a version activated outside of
+// Terraform for the `error` drift policy.
+func serviceDriftError(policy, drift string) error {
+	if policy != DriftPolicyError || drift == "" {
+		return nil
+	}
+	return fmt.Errorf("%s Review the changes (e.g. with the fastly_service_version_diff data source) and update the configuration to include them, then set drift_policy to \"adopt\" or \"warn\" to use the new version", drift)
+}
+
+// lookupActivationEvent returns the latest event logged for the activation of
+// the given version, if any. Errors are logged, as the event only adds detail
+// to the diagnostic.
+func lookupActivationEvent(ctx context.Context, conn *gofastly.Client, serviceID string, version int) *gofastly.Event {
+	resp, err := conn.GetAPIEvents(gofastly.NewContextForResourceID(ctx, serviceID), &gofastly.GetAPIEventsFilterInput{
+		EventType:  versionActivateEvent,
+		MaxResults: 100,
+		ServiceID:  serviceID,
+	})
+	if err != nil {
+		log.Printf("[WARN] Error looking up activation events for Fastly Service (%s): %s", serviceID, err)
+		return nil
+	}
+	return findActivationEvent(resp.Events, version)
+}
+
+// findActivationEvent returns the latest activation event of the given
+// version. Events that don't record a version are only used if none do.
+func findActivationEvent(events []*gofastly.Event, version int) *gofastly.Event {
+	var match, fallback *gofastly.Event
+	later := func(e, than *gofastly.Event) bool {
+		return than == nil || (e.CreatedAt != nil && (than.CreatedAt == nil || e.CreatedAt.After(*than.CreatedAt)))
+	}
+	for _, e := range events {
+		if e == nil || e.EventType != versionActivateEvent {
+			continue
+		}
+		v, ok := eventVersion(e)
+		switch {
+		case !ok:
+			if later(e, fallback) {
+				fallback = e
+			}
+		case v == version:
+			if later(e, match) {
+				match = e
+			}
+		}
+	}
+	if match != nil {
+		return match
+	}
+	return fallback
+}
+
+// eventVersion returns the service version recorded in the metadata of an
+// event.
+func eventVersion(e *gofastly.Event) (int, bool) {
+	switch v := e.Metadata["version"].(type) {
+	case float64:
+		return int(v), true
+	case int:
+		return v, true
+	case string:
+		if i, err := strconv.Atoi(v); err == nil {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// lookupEventUser returns the login of the user that caused an event, falling
+// back to the user ID.
+func lookupEventUser(ctx context.Context, conn *gofastly.Client, e *gofastly.Event) string {
+	if e.UserID == "" {
+		return ""
+	}
+	user, err := conn.GetUser(ctx, &gofastly.GetUserInput{UserID: e.UserID})
+	if err != nil || user.Login == nil {
+		log.Printf("[WARN] Error looking up user (%s): %v", e.UserID, err)
+		return e.UserID
+	}
+	if user.Name != nil && *user.Name != "" {
+		return fmt.Sprintf("%s (%s)", *user.Name, *user.Login)
+	}
+	return *user.Login
+}
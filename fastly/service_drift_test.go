@@ -0,0 +1,75 @@
+package fastly
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+
+	gofastly "github.com/fastly/go-fastly/v12/fastly"
+)
+
+func TestServiceDriftDiagnostics(t *testing.T) {
+	at := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	drift := serviceDriftDetail("abc", 3, 5, serviceActivation{By: "Jane Doe (jane@example.com)", At: &at})
+	want := "Version 5 of Fastly Service (abc) was activated outside of Terraform by Jane Doe (jane@example.com) at 2026-03-04T05:06:07Z, replacing version 3."
+	if drift != want {
+		t.Errorf("expected %q, got %q", want, drift)
+	}
+	if drift := serviceDriftDetail("abc", 3, 5, serviceActivation{}); !strings.Contains(drift, "by an unknown user at an unknown time") {
+		t.Errorf("unexpected detail %q", drift)
+	}
+
+	for _, policy := range []string{DriftPolicyAdopt, DriftPolicyError} {
+		if diags := serviceDriftDiagnostics(policy, drift); len(diags) != 0 {
+			t.Errorf("%s: expected no diagnostics, got %v", policy, diags)
+		}
+	}
+	diags := serviceDriftDiagnostics(DriftPolicyWarn, drift)
+	if len(diags) != 1 || diags[0].Severity != diag.Warning || !strings.HasPrefix(diags[0].Detail, want) {
+		t.Errorf("unexpected diagnostics %v", diags)
+	}
+	if diags := serviceDriftDiagnostics(DriftPolicyWarn, ""); len(diags) != 0 {
+		t.Errorf("expected no diagnostics without drift, got %v", diags)
+	}
+}
+
+func TestServiceDriftError(t *testing.T) {
+	drift := "Version 5 of Fastly Service (abc) was activated outside of Terraform by an unknown user at an unknown time, replacing version 3."
+	if err := serviceDriftError(DriftPolicyError, drift); err == nil || !strings.HasPrefix(err.Error(), drift) {
+		t.Errorf("expected the drift to fail the plan, got %v", err)
+	}
+	// Changing the policy in configuration recovers from the error.
+	for _, policy := range []string{DriftPolicyAdopt, DriftPolicyWarn} {
+		if err := serviceDriftError(policy, drift); err != nil {
+			t.Errorf("%s: unexpected error %s", policy, err)
+		}
+	}
+	if err := serviceDriftError(DriftPolicyError, ""); err != nil {
+		t.Errorf("unexpected error without drift: %s", err)
+	}
+}
+
+func TestFindActivationEvent(t *testing.T) {
+	t1 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Hour)
+
+	events := []*gofastly.Event{
+		{ID: "old", EventType: versionActivateEvent, CreatedAt: &t1, Metadata: map[string]any{"version": float64(5)}},
+		{ID: "other", EventType: versionActivateEvent, CreatedAt: &t2, Metadata: map[string]any{"version": float64(4)}},
+		{ID: "latest", EventType: versionActivateEvent, CreatedAt: &t2, Metadata: map[string]any{"version": "5"}},
+		{ID: "unversioned", EventType: versionActivateEvent, CreatedAt: &t2},
+		{ID: "clone", EventType: "version.clone", CreatedAt: &t2, Metadata: map[string]any{"version": float64(5)}},
+	}
+
+	if e := findActivationEvent(events, 5); e == nil || e.ID != "latest" {
+		t.Errorf("expected the latest activation of version 5, got %v", e)
+	}
+	if e := findActivationEvent(events, 6); e == nil || e.ID != "unversioned" {
+		t.Errorf("expected the event without a version, got %v", e)
+	}
+	if e := findActivationEvent(events[:2], 6); e != nil {
+		t.Errorf("expected no event, got %v", e)
+	}
+}
@@ -0,0 +1,405 @@
+package fastly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	gofastly "github.com/fastly/go-fastly/v12/fastly"
+)
+
+const (
+	// failedVersionCommentMaxLength limits the length of the comment added to
+	// a draft version that failed to be applied.
+	failedVersionCommentMaxLength = 512
+)
+
+var (
+	// vclTableReference matches the dictionary (edge dictionary or VCL table)
+	// passed to a `table.*` function.
+	vclTableReference = regexp.MustCompile(`\btable\.(?:lookup(?:_\w+)?|contains)\s*\(\s*([A-Za-z_][\w-]*)`)
+	// vclACLReference matches the ACL on the right-hand side of a match
+	// operator. Regular expressions are string literals, and so don't match.
+	vclACLReference = regexp.MustCompile(`~\s*([A-Za-z_][\w-]*)`)
+	// vclTableDeclaration and vclACLDeclaration match tables and ACLs declared
+	// in VCL.
+	vclTableDeclaration = regexp.MustCompile(`(?m)^\s*table\s+([A-Za-z_][\w-]*)`)
+	vclACLDeclaration   = regexp.MustCompile(`(?m)^\s*acl\s+([A-Za-z_][\w-]*)`)
+)
+
+// preflightChecks are the checks enabled by the `preflight_checks` block.
+type preflightChecks struct {
+	Domains     bool
+	References  bool
+	Shields     bool
+	TLSCoverage bool
+}
+
+// preflightChecksSchema returns the schema of the `preflight_checks` block
+// shared by the VCL and Compute service resources.
+func preflightChecksSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		MaxItems:    1,
+		Description: "Read-only checks run at plan time, before a new version is cloned. Without this block, only the `shields` check is enabled.",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"domains": {
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Default:     false,
+					Description: "Check that added domains aren't already used by the active version of another service in the account. This lists the domains of every service in the account. Default `false`.",
+				},
+				"references": {
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Default:     false,
+					Description: "Check that the dictionaries and ACLs referenced by VCL, snippets and conditions are declared by a `dictionary` or `acl` block, or in VCL. Default `false`.",
+				},
+				"shields": {
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Default:     true,
+					Description: "Check that the `shield` of backends and directors is a shield POP listed by the `fastly_datacenters` data source. Default `true`.",
+				},
+				"tls_coverage": {
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Default:     false,
+					Description: "Check that added domains are covered by a TLS certificate (including wildcard certificates) in the account. Default `false`.",
+				},
+			},
+		},
+	}
+}
+
+// expandPreflightChecks returns the enabled checks, which are the defaults of
+// the `preflight_checks` block when it isn't declared.
+func expandPreflightChecks(v any) preflightChecks {
+	l, _ := v.([]any)
+	if len(l) == 0 || l[0] == nil {
+		return preflightChecks{Shields: true}
+	}
+	m := l[0].(map[string]any)
+	return preflightChecks{
+		Domains:     m["domains"].(bool),
+		References:  m["references"].(bool),
+		Shields:     m["shields"].(bool),
+		TLSCoverage: m["tls_coverage"].(bool),
+	}
+}
+
+// preflightServiceChecks is a CustomizeDiff function that runs read-only
+// checks against the configuration and the Fastly API, so that problems are
+// reported by the plan rather than after a new version has been cloned.
+//
+// NOTE: The API checks only apply to values that are changing, so that
+// unchanged services don't make any requests.
+func preflightServiceChecks(ctx context.Context, rd *schema.ResourceDiff, meta any) error {
+	checks := expandPreflightChecks(rd.Get("preflight_checks"))
+
+	var errs []error
+	if checks.References {
+		if c := rd.GetRawConfig(); c.IsKnown() && !c.IsNull() {
+			errs = append(errs, checkServiceReferences(c.AsValueMap())...)
+		}
+	}
+
+	client, ok := meta.(*APIClient)
+	if !ok || client == nil || client.conn == nil {
+		return errors.Join(errs...)
+	}
+	conn := client.conn
+
+	var addedDomains []string
+	if rd.NewValueKnown("domain") {
+		addedDomains = addedBlockValues(rd, "domain", "name")
+	}
+
+	// NOTE: Lookup failures (e.g. a token without access to every service)
+	// skip a check rather than failing the plan.
+	if checks.Domains && len(addedDomains) > 0 {
+		used, err := listServiceDomains(ctx, conn, rd.Id())
+		if err != nil {
+			log.Printf("[WARN] Preflight: skipping the domains check, as the domains of the account's services couldn't be listed: %s", err)
+		} else {
+			errs = append(errs, checkDomainsAvailable(addedDomains, used)...)
+		}
+	}
+
+	if checks.Shields {
+		var shields []string
+		for _, block := range []string{"backend", "director"} {
+			if _, ok := rd.GetOk(block); ok && rd.NewValueKnown(block) {
+				shields = append(shields, addedBlockValues(rd, block, "shield")...)
+			}
+		}
+		if len(shields) > 0 {
+			log.Printf("[DEBUG] Preflight: looking up shield POPs for %v", shields)
+			datacenters, err := conn.AllDatacenters(ctx)
+			if err != nil {
+				log.Printf("[WARN] Preflight: skipping the shields check, as the Fastly datacenters couldn't be listed: %s", err)
+			} else {
+				errs = append(errs, checkShields(shields, datacenters)...)
+			}
+		}
+	}
+
+	if checks.TLSCoverage && len(addedDomains) > 0 {
+		tlsDomains, err := listAllTLSDomains(ctx, conn)
+		if err != nil {
+			log.Printf("[WARN] Preflight: skipping the tls_coverage check, as the TLS domains couldn't be listed: %s", err)
+		} else {
+			for _, name := range addedDomains {
+				if !tlsDomainsCover(tlsDomains, name) {
+					errs = append(errs, fmt.Errorf("domain %q is not covered by a TLS certificate", name))
+				}
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// addedBlockValues returns the non-empty values of an attribute of a set of
+// nested blocks that aren't in the prior state, sorted.
+func addedBlockValues(rd *schema.ResourceDiff, block, attr string) []string {
+	o, n := rd.GetChange(block)
+	values := func(v any) map[string]bool {
+		result := map[string]bool{}
+		set, ok := v.(*schema.Set)
+		if !ok {
+			return result
+		}
+		for _, e := range set.List() {
+			if s, _ := e.(map[string]any)[attr].(string); s != "" {
+				result[s] = true
+			}
+		}
+		return result
+	}
+
+	old := values(o)
+	var added []string
+	for v := range values(n) {
+		if !old[v] {
+			added = append(added, v)
+		}
+	}
+	sort.Strings(added)
+	return added
+}
+
+// listServiceDomains returns the services using each domain (in lower case)
+// in their active version, other than the given service.
+func listServiceDomains(ctx context.Context, conn *gofastly.Client, serviceID string) (map[string]string, error) {
+	log.Printf("[DEBUG] Preflight: listing services")
+	services, err := conn.ListServices(ctx, &gofastly.ListServicesInput{})
+	if err != nil {
+		return nil, err
+	}
+
+	used := map[string]string{}
+	for _, service := range services {
+		if service.ServiceID == nil || *service.ServiceID == serviceID || service.ActiveVersion == nil || *service.ActiveVersion == 0 {
+			continue
+		}
+		log.Printf("[DEBUG] Preflight: listing domains of service %s", *service.ServiceID)
+		domains, err := conn.ListServiceDomains(ctx, &gofastly.ListServiceDomainInput{
+			ServiceID: *service.ServiceID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range domains {
+			if d.Name == nil || (d.ServiceVersion != nil && *d.ServiceVersion != int64(*service.ActiveVersion)) {
+				continue
+			}
+			used[strings.ToLower(*d.Name)] = *service.ServiceID
+		}
+	}
+	return used, nil
+}
+
+// checkDomainsAvailable returns an error for each domain that is used by
+// another service.
+func checkDomainsAvailable(names []string, used map[string]string) []error {
+	var errs []error
+	for _, name := range names {
+		if serviceID, ok := used[strings.ToLower(name)]; ok {
+			errs = append(errs, fmt.Errorf("domain %q is already used by service %s", name, serviceID))
+		}
+	}
+	return errs
+}
+
+// checkShields returns an error for each shield that isn't a shield POP.
+func checkShields(shields []string, datacenters []gofastly.Datacenter) []error {
+	valid := map[string]bool{}
+	for _, dc := range datacenters {
+		if dc.Shield != nil && *dc.Shield != "" {
+			valid[*dc.Shield] = true
+		}
+	}
+
+	var errs []error
+	for _, s := range shields {
+		if !valid[s] {
+			errs = append(errs, fmt.Errorf("shield %q is not a valid shield POP (see the fastly_datacenters data source)", s))
+		}
+	}
+	return errs
+}
+
+// listAllTLSDomains returns the names of every TLS domain of the account.
+func listAllTLSDomains(ctx context.Context, conn *gofastly.Client) ([]string, error) {
+	const pageSize = 100
+
+	var names []string
+	for page := 1; ; page++ {
+		log.Printf("[DEBUG] Preflight: listing TLS domains, page %d", page)
+		l, err := conn.ListTLSDomains(ctx, &gofastly.ListTLSDomainsInput{
+			PageNumber: page,
+			PageSize:   pageSize,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range l {
+			names = append(names, d.ID)
+		}
+		if len(l) < pageSize {
+			return names, nil
+		}
+	}
+}
+
+// tlsDomainsCover reports whether a domain matches one of the TLS domains,
+// where a wildcard covers a single label.
+func tlsDomainsCover(tlsDomains []string, name string) bool {
+	name = strings.ToLower(name)
+	for _, d := range tlsDomains {
+		d = strings.ToLower(d)
+		if d == name {
+			return true
+		}
+		if suffix, ok := strings.CutPrefix(d, "*."); ok {
+			if label, ok := strings.CutSuffix(name, "."+suffix); ok && label != "" && !strings.Contains(label, ".") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkServiceReferences returns an error for each dictionary or ACL that is
+// referenced by VCL, snippets or condition statements, but isn't declared.
+func checkServiceReferences(m map[string]cty.Value) []error {
+	declared := map[string]bool{}
+	for _, block := range []string{"dictionary", "acl"} {
+		for _, b := range rawConfigBlocks(m, block) {
+			name, ok := rawConfigString(b, "name")
+			if !ok {
+				// NOTE: References can't be checked against unknown names.
+				return nil
+			}
+			declared[name] = true
+		}
+	}
+
+	type source struct {
+		label   string
+		content string
+	}
+	var sources []source
+	for _, block := range []string{"vcl", "snippet", "dynamicsnippet"} {
+		for _, b := range rawConfigBlocks(m, block) {
+			name, _ := rawConfigString(b, "name")
+			content, ok := rawConfigString(b, "content")
+			if !ok {
+				// NOTE: Content that isn't known (e.g. dynamic snippets managed
+				// separately) may declare tables and ACLs.
+				return nil
+			}
+			sources = append(sources, source{fmt.Sprintf("%s %q", block, name), content})
+		}
+	}
+	for _, b := range rawConfigBlocks(m, "condition") {
+		name, _ := rawConfigString(b, "name")
+		if statement, ok := rawConfigString(b, "statement"); ok {
+			sources = append(sources, source{fmt.Sprintf("condition %q", name), statement})
+		}
+	}
+
+	for _, s := range sources {
+		content := stripVCLComments(s.content)
+		for _, re := range []*regexp.Regexp{vclTableDeclaration, vclACLDeclaration} {
+			for _, match := range re.FindAllStringSubmatch(content, -1) {
+				declared[match[1]] = true
+			}
+		}
+	}
+
+	var errs []error
+	for _, s := range sources {
+		content := stripVCLComments(s.content)
+		reported := map[string]bool{}
+		report := func(kind, name string) {
+			if declared[name] || reported[name] {
+				return
+			}
+			reported[name] = true
+			errs = append(errs, fmt.Errorf("%s: references %s %q, which is not declared", s.label, kind, name))
+		}
+		for _, match := range vclTableReference.FindAllStringSubmatch(content, -1) {
+			report("dictionary", match[1])
+		}
+		for _, match := range vclACLReference.FindAllStringSubmatch(content, -1) {
+			report("ACL", match[1])
+		}
+	}
+	return errs
+}
+
+// annotateFailedVersion adds the reason a draft version failed to be applied
+// to its comment, as versions can't be deleted. Errors are logged, so that the
+// original failure is reported.
+func annotateFailedVersion(ctx context.Context, conn *gofastly.Client, serviceID string, serviceVersion int, comment string, reason error) {
+	annotation := fmt.Sprintf("Terraform apply failed: %s", reason)
+	if comment != "" {
+		annotation = comment + " (" + annotation + ")"
+	}
+	annotation = truncateComment(annotation, failedVersionCommentMaxLength)
+
+	log.Printf("[DEBUG] Annotating failed version (%d) of Fastly Service (%s)", serviceVersion, serviceID)
+	_, err := conn.UpdateVersion(gofastly.NewContextForResourceID(ctx, serviceID), &gofastly.UpdateVersionInput{
+		ServiceID:      serviceID,
+		ServiceVersion: serviceVersion,
+		Comment:        gofastly.ToPointer(annotation),
+	})
+	if err != nil {
+		log.Printf("[WARN] Error annotating failed version (%d) of Fastly Service (%s): %s", serviceVersion, serviceID, err)
+	}
+}
+
+// truncateComment truncates a comment to at most maxLength bytes, on a rune
+// boundary, marking the truncation with an ellipsis.
+func truncateComment(comment string, maxLength int) string {
+	if len(comment) <= maxLength {
+		return comment
+	}
+	end := maxLength - len("...")
+	for end > 0 && !utf8.RuneStart(comment[end]) {
+		end--
+	}
+	return comment[:end] + "..."
+}
@@ -0,0 +1,248 @@
+package fastly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+
+	gofastly "github.com/fastly/go-fastly/v12/fastly"
+)
+
+func TestCheckServiceReferences(t *testing.T) {
+	named := func(name string) cty.Value {
+		return cty.ObjectVal(map[string]cty.Value{"name": cty.StringVal(name)})
+	}
+	content := func(name string, content cty.Value) cty.Value {
+		return cty.ObjectVal(map[string]cty.Value{
+			"content": content,
+			"name":    cty.StringVal(name),
+		})
+	}
+	condition := func(name, statement string) cty.Value {
+		return cty.ObjectVal(map[string]cty.Value{
+			"name":      cty.StringVal(name),
+			"statement": cty.StringVal(statement),
+		})
+	}
+
+	cases := []struct {
+		name   string
+		config map[string]cty.Value
+		want   []string
+	}{
+		{
+			name: "declared by blocks",
+			config: map[string]cty.Value{
+				"dictionary": cty.SetVal([]cty.Value{named("redirects")}),
+				"acl":        cty.SetVal([]cty.Value{named("office")}),
+				"snippet": cty.SetVal([]cty.Value{content("recv", cty.StringVal(`
+if (client.ip ~ office && table.contains(redirects, req.url.path)) {
+  set req.http.Location = table.lookup(redirects, req.url.path);
+}`))}),
+			},
+		},
+		{
+			name: "declared in VCL",
+			config: map[string]cty.Value{
+				"vcl": cty.SetVal([]cty.Value{content("main", cty.StringVal(`
+table paths STRING {
+  "/a": "/b",
+}
+acl internal {
+  "10.0.0.0"/8;
+}
+sub vcl_recv {
+  if (client.ip !~ internal) {
+    set req.url = table.lookup_string(paths, req.url, req.url);
+  }
+}`))}),
+			},
+		},
+		{
+			name: "undeclared",
+			config: map[string]cty.Value{
+				"snippet": cty.SetVal([]cty.Value{content("recv", cty.StringVal(`
+# table.lookup(commented, req.url)
+if (req.url ~ "^/table.lookup(quoted" && client.ip ~ office) {
+  set req.http.X = table.lookup(redirects, req.url, "");
+  set req.http.Y = table.lookup(redirects, req.url, "");
+}`))}),
+				"condition": cty.SetVal([]cty.Value{condition("geo", `table.contains(countries, client.geo.country_code)`)}),
+			},
+			want: []string{
+				`snippet "recv": references ACL "office", which is not declared`,
+				`snippet "recv": references dictionary "redirects", which is not declared`,
+				`condition "geo": references dictionary "countries", which is not declared`,
+			},
+		},
+		{
+			name: "unknown content",
+			config: map[string]cty.Value{
+				"snippet":        cty.SetVal([]cty.Value{content("recv", cty.StringVal(`set req.http.X = table.lookup(redirects, req.url);`))}),
+				"dynamicsnippet": cty.SetVal([]cty.Value{content("dynamic", cty.NullVal(cty.String))}),
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var got []string
+			for _, err := range checkServiceReferences(c.config) {
+				got = append(got, err.Error())
+			}
+			for _, want := range c.want {
+				if !strings.Contains(strings.Join(got, "\n"), want) {
+					t.Errorf("expected error %q, got %v", want, got)
+				}
+			}
+			if len(got) != len(c.want) {
+				t.Errorf("expected %d errors, got %d: %v", len(c.want), len(got), got)
+			}
+		})
+	}
+}
+
+func TestCheckShields(t *testing.T) {
+	datacenters := []gofastly.Datacenter{
+		{Code: gofastly.ToPointer("IAD"), Shield: gofastly.ToPointer("iad-va-us")},
+		{Code: gofastly.ToPointer("LCY"), Shield: gofastly.ToPointer("london_city-uk")},
+		{Code: gofastly.ToPointer("XYZ")},
+	}
+
+	errs := checkShields([]string{"iad-va-us", "IAD", "london_city-uk"}, datacenters)
+	if err := errors.Join(errs...); len(errs) != 1 || !strings.Contains(err.Error(), `shield "IAD" is not a valid shield POP`) {
+		t.Errorf("unexpected errors: %v", err)
+	}
+}
+
+func TestListServiceDomains(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/service":
+			fmt.Fprint(w, `[{"id":"self","version":1},{"id":"other","version":3},{"id":"inactive","version":0}]`)
+		case "/service/other/domain":
+			fmt.Fprint(w, `[{"name":"Www.Example.com","version":3},{"name":"old.example.com","version":2}]`)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := gofastly.NewClientForEndpoint("someapikey", server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	used, err := listServiceDomains(context.Background(), client, "self")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(used) != 1 || used["www.example.com"] != "other" {
+		t.Errorf("unexpected domains: %v", used)
+	}
+
+	errs := checkDomainsAvailable([]string{"WWW.example.com", "old.example.com", "new.example.com"}, used)
+	if err := errors.Join(errs...); len(errs) != 1 || !strings.Contains(err.Error(), `domain "WWW.example.com" is already used by service other`) {
+		t.Errorf("unexpected errors: %v", err)
+	}
+}
+
+func TestTLSDomainsCover(t *testing.T) {
+	tlsDomains := []string{"example.com", "*.example.net"}
+	for name, want := range map[string]bool{
+		"example.com":       true,
+		"EXAMPLE.com":       true,
+		"www.example.com":   false,
+		"www.example.net":   true,
+		"example.net":       false,
+		"a.www.example.net": false,
+	} {
+		if got := tlsDomainsCover(tlsDomains, name); got != want {
+			t.Errorf("%s: expected %t, got %t", name, want, got)
+		}
+	}
+}
+
+func TestTruncateComment(t *testing.T) {
+	for _, tc := range []struct {
+		comment string
+		want    string
+	}{
+		{comment: "short", want: "short"},
+		{comment: "exactly10!", want: "exactly10!"},
+		{comment: "truncated comment", want: "truncat..."},
+		// The 3-byte € rune is dropped rather than split.
+		{comment: "abcdef€gh", want: "abcdef..."},
+		{comment: "abcde€fgh", want: "abcde..."},
+	} {
+		got := truncateComment(tc.comment, 10)
+		if got != tc.want {
+			t.Errorf("truncateComment(%q): expected %q, got %q", tc.comment, tc.want, got)
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("truncateComment(%q): invalid UTF-8 %q", tc.comment, got)
+		}
+	}
+}
+
+func TestExpandPreflightChecks(t *testing.T) {
+	if got := expandPreflightChecks([]any{}); got != (preflightChecks{Shields: true}) {
+		t.Errorf("unexpected defaults: %#v", got)
+	}
+	got := expandPreflightChecks([]any{map[string]any{
+		"domains":      false,
+		"references":   true,
+		"shields":      false,
+		"tls_coverage": true,
+	}})
+	if got != (preflightChecks{References: true, TLSCoverage: true}) {
+		t.Errorf("unexpected checks: %#v", got)
+	}
+}
+
+func TestAccFastlyServiceVCL_preflightShield(t *testing.T) {
+	name := fmt.Sprintf("tf-test-%s", acctest.RandString(10))
+	domain := fmt.Sprintf("fastly-test.tf-%s.test", acctest.RandString(10))
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      testAccCheckServiceVCLDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+resource "fastly_service_vcl" "foo" {
+  name = "%s"
+
+  domain {
+    name = "%s"
+  }
+
+  backend {
+    address = "httpbin.org"
+    name    = "origin"
+    shield  = "not-a-pop"
+  }
+
+  force_destroy = true
+}`, name, domain),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`shield "not-a-pop" is not a valid shield POP`),
+			},
+		},
+	})
+}
@@ -0,0 +1,328 @@
+package fastly
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	gofastly "github.com/fastly/go-fastly/v12/fastly"
+)
+
+// serviceSnapshotSchema returns the schema of the `source_snapshot` and
+// `source_snapshot_blocks` attributes shared by the service resources.
+func serviceSnapshotSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"source_snapshot": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "A JSON document of a service version's configuration, used to populate the first version when the service is created (e.g. to restore a deleted service). The document is an object of nested blocks (e.g. `backend`, `domain`, `condition`, logging and `snippet` blocks), each a list of objects with the same attributes as the block, such as the `values` of a service in the output of `terraform show -json`. Other attributes of the service are ignored. Block types that are declared in configuration are not restored. Changes are ignored once the service has been created.",
+			// NOTE: The snapshot only affects the creation of the service.
+			DiffSuppressFunc: func(_, _, _ string, d *schema.ResourceData) bool {
+				return d.Id() != ""
+			},
+			ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsJSON),
+		},
+		"source_snapshot_blocks": {
+			Type:        schema.TypeSet,
+			Computed:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Description: "The types of nested blocks restored from `source_snapshot`, which are not managed by Terraform until they are declared in configuration. Until then, changes to the restored blocks are ignored.",
+		},
+	}
+}
+
+// parseServiceSnapshot parses a snapshot document into the nested blocks of
+// each handler, converted to the types the handlers expect, keyed by block
+// type.
+func parseServiceSnapshot(content string, serviceDef ServiceDefinition) (map[string][]map[string]any, error) {
+	dec := json.NewDecoder(bytes.NewBufferString(content))
+	dec.UseNumber()
+
+	var raw map[string]any
+	if err := dec.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("error parsing source_snapshot: %w", err)
+	}
+
+	// NOTE: The schema of the whole resource is needed to tell the other
+	// attributes of the service apart from unsupported blocks.
+	s := resourceService(serviceDef).Schema
+
+	blocks := map[string]*schema.Resource{}
+	for _, a := range serviceDef.GetAttributeHandler() {
+		if key, ok := serviceAttributeKey(a); ok {
+			if r, ok := s[key].Elem.(*schema.Resource); ok {
+				blocks[key] = r
+			}
+		}
+	}
+
+	result := map[string][]map[string]any{}
+	for _, key := range sortedKeys(raw, nil) {
+		v := raw[key]
+		r, ok := blocks[key]
+		if !ok {
+			// Other attributes of the service (e.g. `name`) are ignored, so
+			// that the state of an existing service can be used directly.
+			if _, ok := s[key]; ok || v == nil {
+				continue
+			}
+			return nil, fmt.Errorf("source_snapshot: %s is not a nested block of this service type", key)
+		}
+		if v == nil {
+			continue
+		}
+
+		l, ok := v.([]any)
+		if !ok {
+			return nil, fmt.Errorf("source_snapshot: %s must be a list of objects", key)
+		}
+		for i, e := range l {
+			m, ok := e.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("source_snapshot: %s.%d must be an object", key, i)
+			}
+			block, err := expandSnapshotObject(fmt.Sprintf("%s.%d", key, i), r.Schema, m)
+			if err != nil {
+				return nil, fmt.Errorf("source_snapshot: %w", err)
+			}
+			if name, _ := block["name"].(string); name == "" {
+				return nil, fmt.Errorf("source_snapshot: %s.%d.name is required", key, i)
+			}
+			result[key] = append(result[key], block)
+		}
+	}
+
+	return result, nil
+}
+
+// expandSnapshotObject converts a JSON object into the map of a nested block,
+// as returned by schema.ResourceData. Missing attributes are set to their
+// default, and computed-only attributes are ignored.
+func expandSnapshotObject(path string, s map[string]*schema.Schema, raw map[string]any) (map[string]any, error) {
+	for k := range raw {
+		if _, ok := s[k]; !ok {
+			return nil, fmt.Errorf("%s: unsupported attribute %s", path, k)
+		}
+	}
+
+	keys := make([]string, 0, len(s))
+	for k := range s {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	result := map[string]any{}
+	for _, k := range keys {
+		as := s[k]
+		if as.Computed && !as.Optional {
+			continue
+		}
+		v, err := expandSnapshotValue(path+"."+k, as, raw[k])
+		if err != nil {
+			return nil, err
+		}
+		result[k] = v
+	}
+	return result, nil
+}
+
+// expandSnapshotValue converts a JSON value into the type of the given
+// schema. A nil value returns the default of the schema.
+func expandSnapshotValue(path string, s *schema.Schema, v any) (any, error) {
+	if v == nil {
+		def, err := s.DefaultValue()
+		if err != nil {
+			return nil, err
+		}
+		if def != nil {
+			return def, nil
+		}
+		if s.Required {
+			return nil, fmt.Errorf("%s is required", path)
+		}
+		return s.ZeroValue(), nil
+	}
+
+	switch s.Type {
+	case schema.TypeString:
+		if str, ok := v.(string); ok {
+			return str, nil
+		}
+	case schema.TypeBool:
+		if b, ok := v.(bool); ok {
+			return b, nil
+		}
+	case schema.TypeInt:
+		if n, ok := v.(json.Number); ok {
+			if i, err := n.Int64(); err == nil {
+				return int(i), nil
+			}
+		}
+	case schema.TypeFloat:
+		if n, ok := v.(json.Number); ok {
+			if f, err := n.Float64(); err == nil {
+				return f, nil
+			}
+		}
+	case schema.TypeMap:
+		if m, ok := v.(map[string]any); ok {
+			result := map[string]any{}
+			for k, e := range m {
+				str, ok := e.(string)
+				if !ok {
+					return nil, fmt.Errorf("%s.%s must be a string", path, k)
+				}
+				result[k] = str
+			}
+			return result, nil
+		}
+	case schema.TypeList, schema.TypeSet:
+		l, ok := v.([]any)
+		if !ok {
+			break
+		}
+		var result []any
+		for i, e := range l {
+			ep := fmt.Sprintf("%s.%d", path, i)
+			switch elem := s.Elem.(type) {
+			case *schema.Schema:
+				ev, err := expandSnapshotValue(ep, elem, e)
+				if err != nil {
+					return nil, err
+				}
+				result = append(result, ev)
+			case *schema.Resource:
+				m, ok := e.(map[string]any)
+				if !ok {
+					return nil, fmt.Errorf("%s must be an object", ep)
+				}
+				ev, err := expandSnapshotObject(ep, elem.Schema, m)
+				if err != nil {
+					return nil, err
+				}
+				result = append(result, ev)
+			}
+		}
+		if s.Type == schema.TypeSet {
+			set := s.ZeroValue().(*schema.Set)
+			for _, e := range result {
+				set.Add(e)
+			}
+			return set, nil
+		}
+		return result, nil
+	}
+
+	return nil, fmt.Errorf("%s: unexpected value %v for attribute of type %s", path, v, s.Type)
+}
+
+// restoreServiceSnapshot creates the nested blocks of `source_snapshot` in the
+// given (new) service version, using the Create operation of each block's
+// attribute handler. Block types that are declared in configuration are
+// skipped, so that the configuration takes precedence.
+func restoreServiceSnapshot(ctx context.Context, d *schema.ResourceData, serviceDef ServiceDefinition, serviceVersion, maxConcurrent int, conn *gofastly.Client) error {
+	snapshot, err := parseServiceSnapshot(d.Get("source_snapshot").(string), serviceDef)
+	if err != nil {
+		return err
+	}
+
+	var (
+		restored []string
+		pending  []ServiceAttributeDefinition
+	)
+	for _, a := range serviceDef.GetAttributeHandler() {
+		key, _ := serviceAttributeKey(a)
+		if len(snapshot[key]) == 0 {
+			continue
+		}
+		if d.Get(key).(*schema.Set).Len() > 0 {
+			log.Printf("[WARN] Ignoring %s blocks in source_snapshot of Fastly Service (%s), as they are declared in configuration", key, d.Id())
+			continue
+		}
+		restored = append(restored, key)
+		pending = append(pending, a)
+	}
+
+	if err := createServiceBlocks(ctx, d, pending, snapshot, serviceVersion, maxConcurrent, conn); err != nil {
+		return err
+	}
+
+	return d.Set("source_snapshot_blocks", restored)
+}
+
+// customizeDiffServiceSnapshot validates `source_snapshot` when a service is
+// created, and stops tracking block types from the snapshot once they are
+// declared in configuration, at which point they are managed by Terraform.
+func customizeDiffServiceSnapshot(serviceDef ServiceDefinition) schema.CustomizeDiffFunc {
+	return func(_ context.Context, rd *schema.ResourceDiff, _ any) error {
+		if rd.Id() == "" {
+			if content, ok := rd.GetOk("source_snapshot"); ok && rd.NewValueKnown("source_snapshot") {
+				_, err := parseServiceSnapshot(content.(string), serviceDef)
+				return err
+			}
+			return nil
+		}
+
+		owned := rd.Get("source_snapshot_blocks").(*schema.Set)
+		if owned.Len() == 0 {
+			return nil
+		}
+		// NOTE: The restored blocks are in state, so the configuration has to
+		// be checked to tell whether their type is declared.
+		var remaining []any
+		for _, key := range owned.List() {
+			if serviceBlockDeclared(rd.GetRawConfig(), key.(string)) {
+				continue
+			}
+			remaining = append(remaining, key)
+		}
+		if len(remaining) == owned.Len() {
+			return nil
+		}
+		return rd.SetNew("source_snapshot_blocks", remaining)
+	}
+}
+
+// suppressServiceSnapshotBlockDiff suppresses the diff of the nested blocks
+// of type key while they are listed in `source_snapshot_blocks` and aren't
+// declared in configuration, so that restored blocks aren't removed. Once the
+// type is declared, the restored blocks in state are diffed as usual.
+func suppressServiceSnapshotBlockDiff(key string) schema.SchemaDiffSuppressFunc {
+	return func(_, _, _ string, d *schema.ResourceData) bool {
+		owned, ok := d.Get("source_snapshot_blocks").(*schema.Set)
+		if !ok || !owned.Contains(key) {
+			return false
+		}
+		return !serviceBlockDeclared(d.GetRawConfig(), key)
+	}
+}
+
+// serviceBlockDeclared reports whether nested blocks of type key are
+// declared in config. Blocks that aren't known yet (e.g. dynamic blocks) are
+// considered declared.
+func serviceBlockDeclared(config cty.Value, key string) bool {
+	if config.IsNull() || !config.IsKnown() || !config.Type().IsObjectType() || !config.Type().HasAttribute(key) {
+		return false
+	}
+	v := config.GetAttr(key)
+	if v.IsNull() {
+		return false
+	}
+	return !v.IsKnown() || v.LengthInt() > 0
+}
+
+// serviceAttributeKey returns the key of the nested block managed by a
+// handler, if the handler manages a set of nested blocks.
+func serviceAttributeKey(a ServiceAttributeDefinition) (string, bool) {
+	if h, ok := a.(*blockSetAttributeHandler); ok {
+		return h.handler.Key(), true
+	}
+	return "", false
+}
@@ -0,0 +1,185 @@
+package fastly
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	ctyjson "github.com/hashicorp/go-cty/cty/json"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestParseServiceSnapshot(t *testing.T) {
+	snapshot, err := parseServiceSnapshot(`{
+  "name": "restored",
+  "active_version": 3,
+  "activation_check": [],
+  "backend": [{"name": "origin", "address": "example.com", "port": 443, "use_ssl": true}],
+  "condition": null,
+  "director": [{"name": "dir", "backends": ["origin"], "quorum": 50}],
+  "domain": [{"name": "example.com", "comment": ""}]
+}`, vclService)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	backend := snapshot["backend"][0]
+	if backend["address"] != "example.com" || backend["port"] != 443 || backend["use_ssl"] != true {
+		t.Errorf("unexpected backend: %#v", backend)
+	}
+	// Missing attributes are set to their default.
+	if backend["connect_timeout"] != 1000 || backend["auto_loadbalance"] != false {
+		t.Errorf("expected backend defaults, got connect_timeout %v and auto_loadbalance %v", backend["connect_timeout"], backend["auto_loadbalance"])
+	}
+
+	director := snapshot["director"][0]
+	if backends, ok := director["backends"].(*schema.Set); !ok || !backends.Contains("origin") {
+		t.Errorf("unexpected director backends: %#v", director["backends"])
+	}
+
+	if len(snapshot["domain"]) != 1 || len(snapshot["condition"]) != 0 {
+		t.Errorf("unexpected blocks: %#v", snapshot)
+	}
+}
+
+func TestParseServiceSnapshot_errors(t *testing.T) {
+	cases := map[string]string{
+		`{"package": [{"filename": "package.tar.gz"}]}`:                            "package is not a nested block of this service type",
+		`{"backend": {"name": "origin"}}`:                                          "backend must be a list of objects",
+		`{"backend": [{"name": "origin", "prot": 80}]}`:                            "backend.0: unsupported attribute prot",
+		`{"backend": [{"name": "origin", "address": "example.com", "port": "a"}]}`: "backend.0.port: unexpected value a",
+		`{"domain": [{"comment": "no name"}]}`:                                     "domain.0.name is required",
+		`[]`:                                                                       "error parsing source_snapshot",
+	}
+	for content, want := range cases {
+		_, err := parseServiceSnapshot(content, vclService)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: expected error containing %q, got %v", content, want, err)
+		}
+	}
+}
+
+func TestRestoreServiceSnapshot(t *testing.T) {
+	handler := &testConcurrentHandler{key: "testblock"}
+	def := &BaseServiceDefinition{
+		Type:       ServiceTypeVCL,
+		Attributes: []ServiceAttributeDefinition{ToServiceAttributeDefinition(handler)},
+	}
+	snapshot := `{"testblock": [{"name": "a"}, {"name": "b"}]}`
+
+	d := schema.TestResourceDataRaw(t, resourceService(def).Schema, map[string]any{
+		"name":            "restored",
+		"source_snapshot": snapshot,
+	})
+	d.SetId("123")
+
+	if err := restoreServiceSnapshot(context.Background(), d, def, 1, 2, nil); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(handler.created) != 2 {
+		t.Errorf("expected 2 blocks to be restored, got %v", handler.created)
+	}
+	if owned := d.Get("source_snapshot_blocks").(*schema.Set); owned.Len() != 1 || !owned.Contains("testblock") {
+		t.Errorf("unexpected source_snapshot_blocks: %v", owned.List())
+	}
+
+	// Blocks declared in configuration take precedence.
+	handler = &testConcurrentHandler{key: "testblock"}
+	def.Attributes = []ServiceAttributeDefinition{ToServiceAttributeDefinition(handler)}
+	d = schema.TestResourceDataRaw(t, resourceService(def).Schema, map[string]any{
+		"name":            "restored",
+		"source_snapshot": snapshot,
+		"testblock":       []any{map[string]any{"name": "c"}},
+	})
+	d.SetId("123")
+
+	if err := restoreServiceSnapshot(context.Background(), d, def, 1, 2, nil); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(handler.created) != 0 {
+		t.Errorf("expected no blocks to be restored, got %v", handler.created)
+	}
+	if owned := d.Get("source_snapshot_blocks").(*schema.Set); owned.Len() != 0 {
+		t.Errorf("unexpected source_snapshot_blocks: %v", owned.List())
+	}
+}
+
+func TestServiceSnapshotTakeover(t *testing.T) {
+	r := resourceServiceVCL()
+	backend := map[string]any{"name": "origin", "address": "origin.example.com"}
+
+	// The state of a service whose backends were restored from a snapshot.
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]any{
+		"name":    "restored",
+		"backend": []any{backend},
+	})
+	d.SetId("123")
+	if err := d.Set("source_snapshot_blocks", []any{"backend"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	state := d.State()
+
+	diff := func(config map[string]any) *terraform.InstanceDiff {
+		t.Helper()
+		// Terraform sends undeclared nested blocks as empty sets.
+		for key := range r.CoreConfigSchema().BlockTypes {
+			if _, ok := config[key]; !ok {
+				config[key] = []any{}
+			}
+		}
+		b, err := json.Marshal(config)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		raw, err := ctyjson.Unmarshal(b, r.CoreConfigSchema().ImpliedType())
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		s := state.DeepCopy()
+		s.RawConfig = raw
+		diff, err := r.Diff(context.Background(), s, terraform.NewResourceConfigShimmed(raw, r.CoreConfigSchema()), nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		return diff
+	}
+	changed := func(diff *terraform.InstanceDiff, prefix string) []string {
+		var keys []string
+		if diff == nil {
+			return keys
+		}
+		for k, v := range diff.Attributes {
+			if strings.HasPrefix(k, prefix) && v.Old != v.New {
+				keys = append(keys, k)
+			}
+		}
+		return keys
+	}
+
+	// Restored backends aren't removed while they aren't declared.
+	got := diff(map[string]any{"name": "restored"})
+	if keys := append(changed(got, "backend"), changed(got, "source_snapshot_blocks")...); len(keys) > 0 {
+		t.Errorf("expected no changes, got %v", keys)
+	}
+
+	// Declaring a restored backend takes it over without adding it again.
+	got = diff(map[string]any{"name": "restored", "backend": []any{backend}})
+	if keys := changed(got, "backend"); len(keys) > 0 {
+		t.Errorf("expected no backend changes, got %v", keys)
+	}
+	if v, ok := got.Attributes["source_snapshot_blocks.#"]; !ok || v.New != "0" {
+		t.Errorf("expected source_snapshot_blocks to be emptied, got %#v", v)
+	}
+	if keys := changed(got, "cloned_version"); len(keys) > 0 {
+		t.Errorf("expected no new version, got %v", keys)
+	}
+
+	// Restored backends that aren't declared are removed once the type is
+	// declared.
+	got = diff(map[string]any{"name": "restored", "backend": []any{map[string]any{"name": "other", "address": "other.example.com"}}})
+	if keys := changed(got, "backend"); len(keys) == 0 {
+		t.Error("expected backend changes")
+	}
+}
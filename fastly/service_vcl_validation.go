@@ -0,0 +1,466 @@
+package fastly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// vclMacros are the names of the `#FASTLY <name>` macros, each of which must
+// be placed in the matching `vcl_<name>` subroutine.
+var vclMacros = map[string]bool{
+	"recv":    true,
+	"hash":    true,
+	"hit":     true,
+	"miss":    true,
+	"pass":    true,
+	"fetch":   true,
+	"error":   true,
+	"deliver": true,
+	"log":     true,
+}
+
+// conditionReferences maps the attributes that reference a condition to the
+// type of condition they expect.
+var conditionReferences = map[string]string{
+	"cache_condition":    "CACHE",
+	"request_condition":  "REQUEST",
+	"response_condition": "RESPONSE",
+}
+
+// vclBackendReference matches the VCL name of a backend in a condition
+// statement (e.g. `req.backend == F_origin`), excluding fields and header
+// names such as `req.http.F_origin` or `req.http.X-F_Debug`. The name must
+// start at a token boundary, so it can't follow any character allowed in a
+// header name (other than the `!`, `&` and `|` operators). String literals
+// and comments must be stripped first (see stripVCLComments).
+var vclBackendReference = regexp.MustCompile(`(?:^|[^\w.:#$%'*+^~-])(F_\w+)`)
+
+// vclNonIdentifier matches the characters Fastly replaces with `_` when
+// naming backends in the generated VCL.
+var vclNonIdentifier = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// vclProblem is a problem found in VCL content, with its line number.
+type vclProblem struct {
+	line int
+	msg  string
+}
+
+func (p vclProblem) String() string {
+	return fmt.Sprintf("line %d: %s", p.line, p.msg)
+}
+
+// vclLintResult is the outcome of linting VCL content.
+type vclLintResult struct {
+	problems []vclProblem
+	// backends are the names of backends and directors declared in the VCL.
+	backends []string
+}
+
+// validateServiceVCL is a CustomizeDiff function that validates VCL and
+// snippet content, and the references between blocks, at plan time rather
+// than waiting for the Fastly API to validate the version.
+//
+// NOTE: Only known values are validated. Values that are unknown during the
+// plan (e.g. computed by other resources) are validated by the API.
+func validateServiceVCL(_ context.Context, rd *schema.ResourceDiff, _ any) error {
+	c := rd.GetRawConfig()
+	if !c.IsKnown() || c.IsNull() {
+		return nil
+	}
+	return validateServiceVCLConfig(c.AsValueMap())
+}
+
+// validateServiceVCLConfig validates the raw configuration of a service
+// resource, returning all problems found as a single error.
+func validateServiceVCLConfig(m map[string]cty.Value) error {
+	var errs []error
+
+	backends := map[string]bool{}
+	backendsKnown := true
+	lint := func(label, content string, allowSubs bool) {
+		r := lintVCL(content, allowSubs)
+		for _, p := range r.problems {
+			errs = append(errs, fmt.Errorf("%s: %s", label, p))
+		}
+		for _, b := range r.backends {
+			backends[b] = true
+		}
+	}
+
+	for _, b := range rawConfigBlocks(m, "vcl") {
+		name, _ := rawConfigString(b, "name")
+		content, ok := rawConfigString(b, "content")
+		if !ok {
+			backendsKnown = false
+			continue
+		}
+		lint(fmt.Sprintf("vcl %q", name), content, true)
+	}
+	for _, block := range []string{"snippet", "dynamicsnippet"} {
+		for _, b := range rawConfigBlocks(m, block) {
+			name, _ := rawConfigString(b, "name")
+			typ, _ := rawConfigString(b, "type")
+			content, ok := rawConfigString(b, "content")
+			if !ok {
+				// NOTE: Dynamic snippet content is usually managed separately,
+				// so it may declare backends that aren't known here.
+				backendsKnown = false
+				continue
+			}
+			// Snippets are inserted into the matching subroutine, except for
+			// `init` snippets (inserted at the top level) and `none` snippets
+			// (only included explicitly).
+			lint(fmt.Sprintf("%s %q", block, name), content, typ == "init" || typ == "none")
+		}
+	}
+
+	backendNames := map[string]bool{}
+	for _, b := range rawConfigBlocks(m, "backend") {
+		name, ok := rawConfigString(b, "name")
+		if !ok {
+			backendsKnown = false
+			continue
+		}
+		backendNames[name] = true
+		backends[vclBackendName(name)] = true
+	}
+	for _, b := range rawConfigBlocks(m, "director") {
+		name, ok := rawConfigString(b, "name")
+		if !ok {
+			backendsKnown = false
+			continue
+		}
+		backends[vclBackendName(name)] = true
+
+		if v, ok := b["backends"]; ok && v.IsWhollyKnown() && !v.IsNull() {
+			for _, e := range v.AsValueSlice() {
+				if e.IsNull() {
+					continue
+				}
+				if backend := e.AsString(); !backendNames[backend] {
+					errs = append(errs, fmt.Errorf("director %q: backend %q is not defined", name, backend))
+				}
+			}
+		}
+	}
+
+	conditions := map[string]string{}
+	conditionsKnown := true
+	for _, b := range rawConfigBlocks(m, "condition") {
+		name, nameOK := rawConfigString(b, "name")
+		typ, typeOK := rawConfigString(b, "type")
+		if !nameOK || !typeOK {
+			conditionsKnown = false
+			continue
+		}
+		conditions[name] = strings.ToUpper(typ)
+
+		if statement, ok := rawConfigString(b, "statement"); ok && backendsKnown {
+			for _, match := range vclBackendReference.FindAllStringSubmatch(stripVCLComments(statement), -1) {
+				if ref := match[1]; !backends[ref] {
+					errs = append(errs, fmt.Errorf("condition %q: statement references %s, which is not a backend or director defined in this service", name, ref))
+				}
+			}
+		}
+	}
+
+	if conditionsKnown {
+		for _, block := range sortedValueMapKeys(m) {
+			for _, b := range rawConfigBlocks(m, block) {
+				name, _ := rawConfigString(b, "name")
+				for _, attr := range sortedConditionReferences() {
+					ref, ok := rawConfigString(b, attr)
+					if !ok || ref == "" {
+						continue
+					}
+					typ, exists := conditions[ref]
+					switch {
+					case !exists:
+						errs = append(errs, fmt.Errorf("%s %q: %s references condition %q, which is not defined", block, name, attr, ref))
+					case typ != "PREFETCH" && typ != conditionReferences[attr]:
+						errs = append(errs, fmt.Errorf("%s %q: %s references condition %q of type %s, expected type %s", block, name, attr, ref, typ, conditionReferences[attr]))
+					}
+				}
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// lintVCL checks that braces, strings and comments in VCL content are
+// balanced, that subroutines are only defined where allowed (and not nested),
+// and that each `#FASTLY` macro is placed in its matching subroutine.
+//
+// NOTE: This is not a full VCL parser; it only detects problems that would
+// otherwise be reported once a version has been created and validated.
+func lintVCL(content string, allowSubs bool) vclLintResult {
+	type frame struct {
+		line int
+		sub  string
+	}
+
+	var (
+		r      vclLintResult
+		stack  []frame
+		words  []string
+		line   = 1
+		macros = map[string]int{}
+	)
+
+	currentSub := func() string {
+		for i := len(stack) - 1; i >= 0; i-- {
+			if stack[i].sub != "" {
+				return stack[i].sub
+			}
+		}
+		return ""
+	}
+	problem := func(line int, format string, args ...any) {
+		r.problems = append(r.problems, vclProblem{line: line, msg: fmt.Sprintf(format, args...)})
+	}
+
+	for i := 0; i < len(content); {
+		kind, end, ok := scanVCLSpan(content, i)
+		start := line
+		line += strings.Count(content[i:end], "\n")
+		switch kind {
+		case vclComment:
+			if comment := content[i:end]; strings.HasPrefix(comment, "#FASTLY") {
+				fields := strings.Fields(comment)
+				switch {
+				case len(fields) < 2:
+					problem(start, "#FASTLY macro is missing a subroutine name")
+				case !vclMacros[strings.ToLower(fields[1])]:
+					problem(start, "unknown macro #FASTLY %s", fields[1])
+				default:
+					name := strings.ToLower(fields[1])
+					if sub := currentSub(); sub != "vcl_"+name {
+						problem(start, "#FASTLY %s must be placed in sub vcl_%s", fields[1], name)
+					}
+					macros[name]++
+					if macros[name] == 2 {
+						problem(start, "duplicate #FASTLY %s macro", fields[1])
+					}
+				}
+			}
+			i = end
+			continue
+		case vclBlockComment:
+			if !ok {
+				problem(start, "unterminated comment")
+			}
+			i = end
+			continue
+		case vclLongString, vclString:
+			if !ok {
+				if kind == vclLongString {
+					problem(start, "unterminated long string")
+				} else {
+					problem(start, "unterminated string")
+				}
+			}
+			words = nil
+			i = end
+			continue
+		}
+
+		c := content[i]
+		switch {
+		case c == '{':
+			f := frame{line: line}
+			if len(words) == 2 && words[0] == "sub" {
+				f.sub = words[1]
+				switch {
+				case !allowSubs:
+					problem(line, "subroutine %s cannot be defined in a snippet of this type", f.sub)
+				case currentSub() != "":
+					problem(line, "subroutine %s is defined inside subroutine %s", f.sub, currentSub())
+				}
+			}
+			if len(stack) == 0 && len(words) >= 2 && (words[0] == "backend" || words[0] == "director") {
+				r.backends = append(r.backends, words[1])
+			}
+			stack = append(stack, f)
+			words = nil
+			i++
+		case c == '}':
+			if len(stack) == 0 {
+				problem(line, "unexpected '}'")
+			} else {
+				f := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				if name := strings.TrimPrefix(f.sub, "vcl_"); f.sub != name && vclMacros[name] && macros[name] == 0 {
+					log.Printf("[WARN] sub %s (line %d) does not contain the #FASTLY %s macro", f.sub, f.line, name)
+				}
+			}
+			words = nil
+			i++
+		case isVCLIdentifierChar(c):
+			j := i
+			for j < len(content) && (isVCLIdentifierChar(content[j]) || content[j] == '.' || content[j] == '-' || content[j] == ':') {
+				j++
+			}
+			words = append(words, content[i:j])
+			if len(words) > 3 {
+				words = words[1:]
+			}
+			i = j
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			i++
+		default:
+			words = nil
+			i++
+		}
+	}
+
+	for _, f := range stack {
+		if f.sub != "" {
+			problem(f.line, "subroutine %s is not closed", f.sub)
+		} else {
+			problem(f.line, "unclosed '{'")
+		}
+	}
+
+	sort.SliceStable(r.problems, func(i, j int) bool { return r.problems[i].line < r.problems[j].line })
+	return r
+}
+
+// vclSpanKind is the kind of a span of VCL content found by scanVCLSpan.
+type vclSpanKind int
+
+const (
+	vclCode         vclSpanKind = iota
+	vclComment                  // `#` or `//` to the end of the line
+	vclBlockComment             // `/* ... */`
+	vclLongString               // `{" ... "}`
+	vclString                   // `" ... "`, which can't span lines
+)
+
+// scanVCLSpan returns the kind of the span of VCL content starting at index
+// i, the index just past its end, and whether it's terminated. Code spans are
+// a single byte. Comments and unterminated strings end before the newline, and
+// unterminated block comments and long strings at the end of the content.
+//
+// NOTE: This is shared by lintVCL and stripVCLComments, so that both agree on
+// what is code.
+func scanVCLSpan(content string, i int) (kind vclSpanKind, end int, ok bool) {
+	rest := content[i:]
+	switch {
+	case strings.HasPrefix(rest, "#"), strings.HasPrefix(rest, "//"):
+		if j := strings.IndexByte(rest, '\n'); j >= 0 {
+			return vclComment, i + j, true
+		}
+		return vclComment, len(content), true
+	case strings.HasPrefix(rest, "/*"):
+		if j := strings.Index(rest[2:], "*/"); j >= 0 {
+			return vclBlockComment, i + 2 + j + 2, true
+		}
+		return vclBlockComment, len(content), false
+	case strings.HasPrefix(rest, `{"`):
+		if j := strings.Index(rest[2:], `"}`); j >= 0 {
+			return vclLongString, i + 2 + j + 2, true
+		}
+		return vclLongString, len(content), false
+	case strings.HasPrefix(rest, `"`):
+		j := strings.IndexAny(rest[1:], "\"\n")
+		switch {
+		case j < 0:
+			return vclString, len(content), false
+		case rest[1+j] == '\n':
+			return vclString, i + 1 + j, false
+		}
+		return vclString, i + 1 + j + 1, true
+	}
+	return vclCode, i + 1, true
+}
+
+// stripVCLComments removes comments and string literals from VCL, so that
+// references are only matched in code. Strings are replaced with `""`, and
+// block comments with a space.
+func stripVCLComments(content string) string {
+	var b strings.Builder
+	for i := 0; i < len(content); {
+		kind, end, _ := scanVCLSpan(content, i)
+		switch kind {
+		case vclCode:
+			b.WriteString(content[i:end])
+		case vclBlockComment:
+			b.WriteByte(' ')
+		case vclLongString, vclString:
+			b.WriteString(`""`)
+		}
+		i = end
+	}
+	return b.String()
+}
+
+func isVCLIdentifierChar(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// vclBackendName returns the name of a backend in the VCL generated by
+// Fastly.
+func vclBackendName(name string) string {
+	return "F_" + vclNonIdentifier.ReplaceAllString(name, "_")
+}
+
+// rawConfigBlocks returns the known elements of a set or list block in the
+// raw configuration.
+func rawConfigBlocks(m map[string]cty.Value, block string) []map[string]cty.Value {
+	v, ok := m[block]
+	if !ok || v.IsNull() || !v.IsKnown() {
+		return nil
+	}
+	t := v.Type()
+	if !t.IsSetType() && !t.IsListType() {
+		return nil
+	}
+	if !t.ElementType().IsObjectType() {
+		return nil
+	}
+
+	var blocks []map[string]cty.Value
+	for _, e := range v.AsValueSlice() {
+		if e.IsNull() || !e.IsKnown() {
+			continue
+		}
+		blocks = append(blocks, e.AsValueMap())
+	}
+	return blocks
+}
+
+// rawConfigString returns a known, non-null string attribute of a block.
+func rawConfigString(m map[string]cty.Value, attr string) (string, bool) {
+	v, ok := m[attr]
+	if !ok || v.IsNull() || !v.IsKnown() || !v.Type().Equals(cty.String) {
+		return "", false
+	}
+	return v.AsString(), true
+}
+
+func sortedValueMapKeys(m map[string]cty.Value) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedConditionReferences() []string {
+	keys := make([]string, 0, len(conditionReferences))
+	for k := range conditionReferences {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,258 @@
+package fastly
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+)
+
+func TestLintVCL(t *testing.T) {
+	cases := []struct {
+		name      string
+		content   string
+		allowSubs bool
+		want      []string
+	}{
+		{
+			name: "valid",
+			content: `backend F_origin { .host = "example.com"; }
+sub vcl_recv {
+#FASTLY recv
+  if (req.url ~ "^/{") {
+    set req.http.X-Test = {"a "quoted" } string"};
+  }
+  /* a { comment */
+  // another { comment
+  # and another {
+}
+sub custom_error { return(deliver); }`,
+			allowSubs: true,
+		},
+		{
+			name:    "snippet",
+			content: "if ( req.url ) {\n set req.http.x = \"true\";\n}",
+		},
+		{
+			name:      "unclosed sub",
+			content:   "sub vcl_recv {\n#FASTLY recv\n  if (req.url) {\n}",
+			allowSubs: true,
+			want:      []string{"line 1: subroutine vcl_recv is not closed"},
+		},
+		{
+			name:      "unexpected brace",
+			content:   "sub vcl_recv {\n#FASTLY recv\n}\n}",
+			allowSubs: true,
+			want:      []string{"line 4: unexpected '}'"},
+		},
+		{
+			name:      "nested sub",
+			content:   "sub vcl_recv {\n#FASTLY recv\nsub vcl_hash {\n}\n}",
+			allowSubs: true,
+			want:      []string{"line 3: subroutine vcl_hash is defined inside subroutine vcl_recv"},
+		},
+		{
+			name:    "sub in snippet",
+			content: "sub custom {\n}",
+			want:    []string{"line 1: subroutine custom cannot be defined in a snippet of this type"},
+		},
+		{
+			name:      "misplaced macro",
+			content:   "sub vcl_recv {\n#FASTLY deliver\n}",
+			allowSubs: true,
+			want:      []string{"line 2: #FASTLY deliver must be placed in sub vcl_deliver"},
+		},
+		{
+			name:      "unknown macro",
+			content:   "sub vcl_recv {\n#FASTLY receive\n}",
+			allowSubs: true,
+			want:      []string{"line 2: unknown macro #FASTLY receive"},
+		},
+		{
+			name:      "duplicate macro",
+			content:   "sub vcl_recv {\n#FASTLY recv\n#FASTLY recv\n}",
+			allowSubs: true,
+			want:      []string{"line 3: duplicate #FASTLY recv macro"},
+		},
+		{
+			name:    "unterminated string",
+			content: "set req.http.X = \"abc;\nset req.http.Y = \"def\";",
+			want:    []string{"line 1: unterminated string"},
+		},
+		{
+			name:    "unterminated comment",
+			content: "\n/* abc\n",
+			want:    []string{"line 2: unterminated comment"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := lintVCL(c.content, c.allowSubs)
+
+			var got []string
+			for _, p := range r.problems {
+				got = append(got, p.String())
+			}
+			if strings.Join(got, "\n") != strings.Join(c.want, "\n") {
+				t.Errorf("expected problems:\n%s\ngot:\n%s", strings.Join(c.want, "\n"), strings.Join(got, "\n"))
+			}
+		})
+	}
+}
+
+func TestLintVCL_backends(t *testing.T) {
+	r := lintVCL("backend F_a { .port = \"443\"; }\ndirector my_director random {\n  { .backend = F_a; .weight = 1; }\n}", true)
+	if got := strings.Join(r.backends, ","); got != "F_a,my_director" {
+		t.Errorf("expected backends F_a,my_director, got %s", got)
+	}
+}
+
+func TestValidateServiceVCLConfig(t *testing.T) {
+	condition := func(name, typ, statement string) cty.Value {
+		return cty.ObjectVal(map[string]cty.Value{
+			"name":      cty.StringVal(name),
+			"priority":  cty.NumberIntVal(10),
+			"statement": cty.StringVal(statement),
+			"type":      cty.StringVal(typ),
+		})
+	}
+	header := func(name, requestCondition, cacheCondition string) cty.Value {
+		v := map[string]cty.Value{
+			"name":               cty.StringVal(name),
+			"request_condition":  cty.NullVal(cty.String),
+			"cache_condition":    cty.NullVal(cty.String),
+			"response_condition": cty.NullVal(cty.String),
+		}
+		if requestCondition != "" {
+			v["request_condition"] = cty.StringVal(requestCondition)
+		}
+		if cacheCondition != "" {
+			v["cache_condition"] = cty.StringVal(cacheCondition)
+		}
+		return cty.ObjectVal(v)
+	}
+	backend := func(name string) cty.Value {
+		return cty.ObjectVal(map[string]cty.Value{
+			"name": cty.StringVal(name),
+		})
+	}
+	vcl := func(name string, content cty.Value) cty.Value {
+		return cty.ObjectVal(map[string]cty.Value{
+			"content": content,
+			"main":    cty.True,
+			"name":    cty.StringVal(name),
+		})
+	}
+
+	cases := []struct {
+		name   string
+		config map[string]cty.Value
+		want   []string
+	}{
+		{
+			name: "valid",
+			config: map[string]cty.Value{
+				"backend": cty.SetVal([]cty.Value{backend("my-origin")}),
+				"condition": cty.SetVal([]cty.Value{
+					condition("is-origin", "REQUEST", "req.backend == F_my_origin || req.backend == F_custom || req.http.F_header"),
+					condition("is-cached", "CACHE", "beresp.status == 200"),
+				}),
+				"header": cty.SetVal([]cty.Value{header("x", "is-origin", "is-cached")}),
+				"vcl":    cty.SetVal([]cty.Value{vcl("main", cty.StringVal("backend F_custom {}\nsub vcl_recv {\n#FASTLY recv\n}"))}),
+			},
+		},
+		{
+			name: "missing condition",
+			config: map[string]cty.Value{
+				"header": cty.SetVal([]cty.Value{header("x", "missing", "")}),
+			},
+			want: []string{`header "x": request_condition references condition "missing", which is not defined`},
+		},
+		{
+			name: "condition type mismatch",
+			config: map[string]cty.Value{
+				"condition": cty.SetVal([]cty.Value{condition("is-cached", "CACHE", "beresp.status == 200")}),
+				"header":    cty.SetVal([]cty.Value{header("x", "is-cached", "")}),
+			},
+			want: []string{`header "x": request_condition references condition "is-cached" of type CACHE, expected type REQUEST`},
+		},
+		{
+			name: "missing backend",
+			config: map[string]cty.Value{
+				"backend":   cty.SetVal([]cty.Value{backend("origin")}),
+				"condition": cty.SetVal([]cty.Value{condition("is-other", "REQUEST", "req.backend == F_other")}),
+			},
+			want: []string{`condition "is-other": statement references F_other, which is not a backend or director defined in this service`},
+		},
+		{
+			name: "backend name in string",
+			config: map[string]cty.Value{
+				"backend": cty.SetVal([]cty.Value{backend("origin")}),
+				"condition": cty.SetVal([]cty.Value{
+					condition("has-session", "REQUEST", `req.http.Cookie ~ "F_session" || req.http.X ~ {"F_long"} /* F_comment */`),
+				}),
+			},
+		},
+		{
+			name: "backend name in header name",
+			config: map[string]cty.Value{
+				"backend": cty.SetVal([]cty.Value{backend("origin")}),
+				"condition": cty.SetVal([]cty.Value{
+					condition("is-debug", "REQUEST", `req.http.X-F_Debug == "1" && req.http.F_Debug:x && (req.backend == F_origin)`),
+				}),
+			},
+		},
+		{
+			name: "unknown vcl content",
+			config: map[string]cty.Value{
+				"condition": cty.SetVal([]cty.Value{condition("is-other", "REQUEST", "req.backend == F_other")}),
+				"vcl":       cty.SetVal([]cty.Value{vcl("main", cty.UnknownVal(cty.String))}),
+			},
+		},
+		{
+			name: "director backend",
+			config: map[string]cty.Value{
+				"backend": cty.SetVal([]cty.Value{backend("origin")}),
+				"director": cty.SetVal([]cty.Value{cty.ObjectVal(map[string]cty.Value{
+					"backends": cty.SetVal([]cty.Value{cty.StringVal("origin"), cty.StringVal("other")}),
+					"name":     cty.StringVal("dir"),
+				})}),
+			},
+			want: []string{`director "dir": backend "other" is not defined`},
+		},
+		{
+			name: "vcl and snippet problems",
+			config: map[string]cty.Value{
+				"snippet": cty.ListVal([]cty.Value{cty.ObjectVal(map[string]cty.Value{
+					"content": cty.StringVal("if (req.url) {\n  set req.http.X = \"1\";\n"),
+					"name":    cty.StringVal("snip"),
+					"type":    cty.StringVal("recv"),
+				})}),
+				"vcl": cty.SetVal([]cty.Value{vcl("main", cty.StringVal("sub vcl_recv {\n#FASTLY recv\n}\n}"))}),
+			},
+			want: []string{
+				`vcl "main": line 4: unexpected '}'`,
+				`snippet "snip": line 1: unclosed '{'`,
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := validateServiceVCLConfig(c.config)
+			if len(c.want) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected error: %s", strings.Join(c.want, "\n"))
+			}
+			if got := err.Error(); got != strings.Join(c.want, "\n") {
+				t.Errorf("expected error:\n%s\ngot:\n%s", strings.Join(c.want, "\n"), got)
+			}
+		})
+	}
+}
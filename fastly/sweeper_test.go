@@ -17,16 +17,35 @@ import (
 
 const testResourcePrefix = "tf-test"
 
-var sweeperClients map[string]*fastly.Client
+var sweeperClients map[string][]*fastly.Client
 
 func TestMain(m *testing.M) {
-	sweeperClients = make(map[string]*fastly.Client)
+	sweeperClients = make(map[string][]*fastly.Client)
 	resource.TestMain(m)
 }
 
-func sharedClientForRegion(region string) (*fastly.Client, diag.Diagnostics) {
-	if client, ok := sweeperClients[region]; ok {
-		return client, nil
+// sweepAccounts runs sweep with the client of every account that the
+// acceptance tests create resources in.
+func sweepAccounts(region string, sweep func(*fastly.Client) error) error {
+	clients, diagnostics := sharedClientsForRegion(region)
+	if diagnostics.HasError() {
+		return diagToErr(diagnostics)
+	}
+
+	for _, client := range clients {
+		if err := sweep(client); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sharedClientsForRegion returns the clients of the default account and, if
+// configured, the secondary account of the acceptance tests.
+func sharedClientsForRegion(region string) ([]*fastly.Client, diag.Diagnostics) {
+	if clients, ok := sweeperClients[region]; ok {
+		return clients, nil
 	}
 
 	url := fastly.DefaultEndpoint
@@ -42,8 +61,9 @@ func sharedClientForRegion(region string) (*fastly.Client, diag.Diagnostics) {
 		}
 	}
 	c := Config{
-		APIKey:  os.Getenv("FASTLY_API_KEY"),
-		BaseURL: url,
+		APIKey:      os.Getenv("FASTLY_API_KEY"),
+		BaseURL:     url,
+		Credentials: testAccSecondaryCredentials(),
 		UserAgent: fmt.Sprintf(
 			"HashiCorp Terraform/%s (+https://www.terraform.io) Terraform Plugin SDK/%s %s/%s",
 			"test-sweepers",
@@ -58,7 +78,11 @@ func sharedClientForRegion(region string) (*fastly.Client, diag.Diagnostics) {
 		return nil, diagnostics
 	}
 
-	sweeperClients[region] = client.conn
+	clients := []*fastly.Client{client.conn}
+	for _, account := range client.accounts {
+		clients = append(clients, account.conn)
+	}
+	sweeperClients[region] = clients
 
-	return client.conn, nil
+	return clients, nil
 }
@@ -0,0 +1,201 @@
+package fastly
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	// tlsCertificateExpirySeverityWarning and tlsCertificateExpirySeverityError
+	// are the values of `expiry_warning_severity`.
+	tlsCertificateExpirySeverityWarning = "warning"
+	tlsCertificateExpirySeverityError   = "error"
+)
+
+// tlsCertificateDetails are the details of a certificate chain that the
+// provider parses from its PEM encoding, rather than reading from the API.
+type tlsCertificateDetails struct {
+	// ChainFingerprint is the hex-encoded SHA-256 digest of the DER-encoded
+	// certificates of the chain, in order.
+	ChainFingerprint string
+	// KeyAlgorithm is the algorithm and size of the leaf certificate's
+	// public key, e.g. `RSA-2048` or `ECDSA-P256`.
+	KeyAlgorithm string
+	NotAfter     time.Time
+	NotBefore    time.Time
+	// PublicKeySHA256 is the publicKeySHA256 of the leaf certificate.
+	PublicKeySHA256         string
+	SubjectAlternativeNames []string
+}
+
+// parseTLSCertificateDetails parses a PEM-encoded certificate chain. The
+// first certificate is the leaf certificate.
+func parseTLSCertificateDetails(body string) (*tlsCertificateDetails, error) {
+	var (
+		der    []byte
+		leaf   *x509.Certificate
+		remain = []byte(body)
+	)
+	for {
+		block, rest := pem.Decode(remain)
+		if block == nil {
+			break
+		}
+		remain = rest
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		if leaf == nil {
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing certificate: %w", err)
+			}
+			leaf = cert
+		}
+		der = append(der, block.Bytes...)
+	}
+	if leaf == nil {
+		return nil, errors.New("no PEM-encoded certificate found")
+	}
+
+	sans := append([]string{}, leaf.DNSNames...)
+	for _, ip := range leaf.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+
+	fingerprint := sha256.Sum256(der)
+	publicKey, err := publicKeySHA256(leaf.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tlsCertificateDetails{
+		ChainFingerprint:        hex.EncodeToString(fingerprint[:]),
+		KeyAlgorithm:            publicKeyAlgorithm(leaf),
+		NotAfter:                leaf.NotAfter,
+		NotBefore:               leaf.NotBefore,
+		PublicKeySHA256:         publicKey,
+		SubjectAlternativeNames: sans,
+	}, nil
+}
+
+// publicKeyAlgorithm describes the public key of a certificate.
+func publicKeyAlgorithm(cert *x509.Certificate) string {
+	switch key := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		return fmt.Sprintf("RSA-%d", key.N.BitLen())
+	case *ecdsa.PublicKey:
+		return "ECDSA-" + key.Curve.Params().Name
+	case ed25519.PublicKey:
+		return "Ed25519"
+	}
+	return cert.PublicKeyAlgorithm.String()
+}
+
+// setTLSCertificateDetails sets the attributes parsed from the certificate
+// chain, with d.Set or, at plan time, rd.SetNew.
+func setTLSCertificateDetails(details *tlsCertificateDetails, set func(string, any) error) error {
+	for key, value := range map[string]any{
+		"chain_fingerprint":         details.ChainFingerprint,
+		"key_algorithm":             details.KeyAlgorithm,
+		"not_after":                 details.NotAfter.UTC().Format(time.RFC3339),
+		"not_before":                details.NotBefore.UTC().Format(time.RFC3339),
+		"public_key_sha256":         details.PublicKeySHA256,
+		"subject_alternative_names": details.SubjectAlternativeNames,
+	} {
+		if err := set(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// customizeDiffTLSCertificateDetails plans the attributes parsed from
+// `certificate_body` when it changes, so that the new expiry is shown in the
+// plan.
+func customizeDiffTLSCertificateDetails(_ context.Context, rd *schema.ResourceDiff, _ any) error {
+	if !rd.HasChange("certificate_body") {
+		return nil
+	}
+	if !rd.NewValueKnown("certificate_body") {
+		for _, key := range []string{"chain_fingerprint", "key_algorithm", "not_after", "not_before", "public_key_sha256", "subject_alternative_names"} {
+			if err := rd.SetNewComputed(key); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	details, err := parseTLSCertificateDetails(rd.Get("certificate_body").(string))
+	if err != nil {
+		return fmt.Errorf("certificate_body: %w", err)
+	}
+	return setTLSCertificateDetails(details, rd.SetNew)
+}
+
+// validateTLSCertificateExpiry reports a diagnostic at plan time when the
+// configured certificate expires within `expiry_warning_days`.
+//
+// Certificates that are only known once applied, e.g. when issued by another
+// resource, are checked by the next plan.
+func validateTLSCertificateExpiry(_ context.Context, req schema.ValidateResourceConfigFuncRequest, resp *schema.ValidateResourceConfigFuncResponse) {
+	config := req.RawConfig
+	if config.IsNull() || !config.IsKnown() {
+		return
+	}
+	body, days := config.GetAttr("certificate_body"), config.GetAttr("expiry_warning_days")
+	if body.IsNull() || !body.IsKnown() || days.IsNull() || !days.IsKnown() {
+		return
+	}
+	d, _ := days.AsBigFloat().Int64()
+	if d <= 0 {
+		return
+	}
+
+	severity := diag.Warning
+	if v := config.GetAttr("expiry_warning_severity"); v.IsKnown() && !v.IsNull() && v.AsString() == tlsCertificateExpirySeverityError {
+		severity = diag.Error
+	}
+
+	details, err := parseTLSCertificateDetails(body.AsString())
+	if err != nil {
+		// The error is reported by the plan.
+		return
+	}
+	if diagnostic := tlsCertificateExpiryDiagnostic(details.NotAfter, time.Now(), int(d), severity); diagnostic != nil {
+		diagnostic.AttributePath = cty.GetAttrPath("certificate_body")
+		resp.Diagnostics = append(resp.Diagnostics, *diagnostic)
+	}
+}
+
+// tlsCertificateExpiryDiagnostic returns a diagnostic if a certificate
+// expiring at notAfter has expired or expires within days of now.
+func tlsCertificateExpiryDiagnostic(notAfter, now time.Time, days int, severity diag.Severity) *diag.Diagnostic {
+	if notAfter.After(now.AddDate(0, 0, days)) {
+		return nil
+	}
+
+	var summary string
+	if remaining := notAfter.Sub(now); remaining <= 0 {
+		summary = fmt.Sprintf("The certificate expired on %s", notAfter.UTC().Format(time.RFC3339))
+	} else {
+		summary = fmt.Sprintf("The certificate expires in %d day(s), on %s", int(remaining.Hours()/24), notAfter.UTC().Format(time.RFC3339))
+	}
+	return &diag.Diagnostic{
+		Severity: severity,
+		Summary:  summary,
+		Detail:   fmt.Sprintf("The certificate expires within `expiry_warning_days` (%d). Upload a renewed certificate in `certificate_body`.", days),
+	}
+}
@@ -0,0 +1,127 @@
+package fastly
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTLSCertificateDetails(t *testing.T) {
+	_, cert, caPEM, err := generateKeyAndCertWithCA("www.example.com", "example.com")
+	require.NoError(t, err)
+
+	details, err := parseTLSCertificateDetails(cert + "\n" + caPEM)
+	require.NoError(t, err)
+
+	if want := []string{"www.example.com", "example.com"}; !reflect.DeepEqual(details.SubjectAlternativeNames, want) {
+		t.Errorf("expected SANs %v, got %v", want, details.SubjectAlternativeNames)
+	}
+	if details.KeyAlgorithm != "RSA-2048" {
+		t.Errorf("expected key algorithm RSA-2048, got %q", details.KeyAlgorithm)
+	}
+	if got := details.NotAfter.Sub(details.NotBefore); got != 90*24*time.Hour {
+		t.Errorf("expected the certificate to be valid for 90 days, got %s", got)
+	}
+
+	var der []byte
+	for _, p := range []string{cert, caPEM} {
+		block, _ := pem.Decode([]byte(p))
+		der = append(der, block.Bytes...)
+	}
+	fingerprint := sha256.Sum256(der)
+	if want := hex.EncodeToString(fingerprint[:]); details.ChainFingerprint != want {
+		t.Errorf("expected chain fingerprint %q, got %q", want, details.ChainFingerprint)
+	}
+
+	leafOnly, err := parseTLSCertificateDetails(cert)
+	require.NoError(t, err)
+	if leafOnly.ChainFingerprint == details.ChainFingerprint {
+		t.Errorf("expected the fingerprint to depend on the intermediates")
+	}
+
+	for _, body := range []string{"", "-----BEGIN CERTIFICATE-----\nbm90IGEgY2VydGlmaWNhdGU=\n-----END CERTIFICATE-----"} {
+		if _, err := parseTLSCertificateDetails(body); err == nil {
+			t.Errorf("expected an error parsing %q", body)
+		}
+	}
+}
+
+func TestPublicKeyAlgorithm(t *testing.T) {
+	block, _ := pem.Decode([]byte(certificate(t)))
+	cert, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+
+	if got := publicKeyAlgorithm(cert); got != "RSA-4096" {
+		t.Errorf("expected an RSA-4096 key, got %q", got)
+	}
+}
+
+func TestTLSCertificateExpiryDiagnostic(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	if d := tlsCertificateExpiryDiagnostic(now.AddDate(0, 0, 31), now, 30, diag.Warning); d != nil {
+		t.Errorf("expected no diagnostic outside the window, got %v", d)
+	}
+
+	d := tlsCertificateExpiryDiagnostic(now.AddDate(0, 0, 10), now, 30, diag.Error)
+	if d == nil || d.Severity != diag.Error || !strings.Contains(d.Summary, "expires in 10 day(s)") {
+		t.Errorf("expected an expiry error, got %v", d)
+	}
+
+	d = tlsCertificateExpiryDiagnostic(now.AddDate(0, 0, -1), now, 30, diag.Warning)
+	if d == nil || d.Severity != diag.Warning || !strings.Contains(d.Summary, "expired on 2025-12-31") {
+		t.Errorf("expected an expired warning, got %v", d)
+	}
+}
+
+func TestValidateTLSCertificateExpiry(t *testing.T) {
+	// The certificate is valid for 90 days.
+	_, cert, err := generateKeyAndCert("www.example.com")
+	require.NoError(t, err)
+
+	config := func(body cty.Value, days int64, severity string) cty.Value {
+		sev := cty.NullVal(cty.String)
+		if severity != "" {
+			sev = cty.StringVal(severity)
+		}
+		return cty.ObjectVal(map[string]cty.Value{
+			"certificate_body":        body,
+			"expiry_warning_days":     cty.NumberIntVal(days),
+			"expiry_warning_severity": sev,
+		})
+	}
+
+	for name, tc := range map[string]struct {
+		config cty.Value
+		want   []diag.Severity
+	}{
+		"outside window":  {config(cty.StringVal(cert), 30, ""), nil},
+		"disabled":        {config(cty.StringVal(cert), 0, "error"), nil},
+		"unknown body":    {config(cty.UnknownVal(cty.String), 100, "error"), nil},
+		"warning":         {config(cty.StringVal(cert), 100, ""), []diag.Severity{diag.Warning}},
+		"explicit error":  {config(cty.StringVal(cert), 100, "error"), []diag.Severity{diag.Error}},
+		"invalid content": {config(cty.StringVal("invalid"), 100, "error"), nil},
+	} {
+		var resp schema.ValidateResourceConfigFuncResponse
+		validateTLSCertificateExpiry(context.Background(), schema.ValidateResourceConfigFuncRequest{RawConfig: tc.config}, &resp)
+
+		var got []diag.Severity
+		for _, d := range resp.Diagnostics {
+			got = append(got, d.Severity)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: expected diagnostics %v, got %v", name, tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,329 @@
+package fastly
+
+import (
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// The checks below compare the PEM-encoded keys and certificates of the TLS
+// resources with each other at plan time, so that inconsistencies are
+// reported before anything is uploaded to Fastly. They run locally, without
+// API requests.
+
+// parsePEMCertificates parses the certificates of a PEM bundle, in order.
+func parsePEMCertificates(s string) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	rest := []byte(s)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			return nil, fmt.Errorf("unexpected PEM block of type %q", block.Type)
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing certificate %d: %w", len(certs)+1, err)
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("no PEM-encoded certificate found")
+	}
+	return certs, nil
+}
+
+// publicKeySHA256 returns the hex-encoded SHA-256 digest of the DER-encoded
+// public key (its SubjectPublicKeyInfo).
+func publicKeySHA256(pub any) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// privateKeyPublicKeySHA256 returns the publicKeySHA256 of the public key of
+// a PEM-encoded private key in PKCS #8, PKCS #1 or SEC 1 form.
+func privateKeyPublicKeySHA256(keyPEM string) (string, error) {
+	block, _ := pem.Decode([]byte(keyPEM))
+	if block == nil {
+		return "", errors.New("no PEM-encoded private key found")
+	}
+
+	var key any
+	var err error
+	if key, err = x509.ParsePKCS8PrivateKey(block.Bytes); err != nil {
+		if key, err = x509.ParsePKCS1PrivateKey(block.Bytes); err != nil {
+			if key, err = x509.ParseECPrivateKey(block.Bytes); err != nil {
+				return "", fmt.Errorf("error parsing private key of PEM type %q", block.Type)
+			}
+		}
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return "", fmt.Errorf("unsupported private key type %T", key)
+	}
+	return publicKeySHA256(signer.Public())
+}
+
+// checkCertificatePublicKey checks that the public key of a certificate has
+// the given publicKeySHA256.
+func checkCertificatePublicKey(cert *x509.Certificate, want string) error {
+	got, err := publicKeySHA256(cert.PublicKey)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(got, want) {
+		return fmt.Errorf("the public key of the certificate (SHA-256 %s) doesn't match the private key (SHA-256 %s)", got, want)
+	}
+	return nil
+}
+
+// checkCertificateChain checks that the chain of intermediates verifies to
+// the leaf: the issuer of the leaf must be in the chain, and so must the
+// issuer of every certificate of the path, up to a self-signed root or a
+// certificate whose issuer is expected to be a trusted root. Every
+// certificate of the chain must be part of the path.
+func checkCertificateChain(leaf *x509.Certificate, chain []*x509.Certificate) error {
+	used := make([]bool, len(chain))
+	current := leaf
+	for depth := 0; ; depth++ {
+		issuer := -1
+		for i, c := range chain {
+			if !used[i] && current.CheckSignatureFrom(c) == nil {
+				issuer = i
+				break
+			}
+		}
+		if issuer < 0 {
+			if depth == 0 {
+				return fmt.Errorf("no certificate of the chain signed the certificate (issuer %q)", leaf.Issuer)
+			}
+			for i, c := range chain {
+				if !used[i] {
+					return fmt.Errorf("certificate %d of the chain (subject %q) isn't part of the path to the certificate", i+1, c.Subject)
+				}
+			}
+			return nil
+		}
+		used[issuer] = true
+		current = chain[issuer]
+	}
+}
+
+// checkCACertificates checks that every certificate is a CA certificate.
+func checkCACertificates(certs []*x509.Certificate) error {
+	for i, cert := range certs {
+		if !cert.BasicConstraintsValid || !cert.IsCA {
+			return fmt.Errorf("certificate %d (subject %q) isn't a CA certificate", i+1, cert.Subject)
+		}
+	}
+	return nil
+}
+
+// checkCertificateDomains checks that the domains are the DNS names in the
+// Subject Alternative Names of a certificate.
+func checkCertificateDomains(cert *x509.Certificate, domains []string) error {
+	want := make(map[string]bool, len(domains))
+	for _, d := range domains {
+		want[strings.ToLower(d)] = true
+	}
+	have := make(map[string]bool, len(cert.DNSNames))
+	for _, d := range cert.DNSNames {
+		have[strings.ToLower(d)] = true
+	}
+
+	var missing, extra []string
+	for d := range want {
+		if !have[d] {
+			missing = append(missing, d)
+		}
+	}
+	for d := range have {
+		if !want[d] {
+			extra = append(extra, d)
+		}
+	}
+	sort.Strings(missing)
+	sort.Strings(extra)
+
+	var problems []string
+	if len(missing) > 0 {
+		problems = append(problems, fmt.Sprintf("not in the certificate's SANs: %s", strings.Join(missing, ", ")))
+	}
+	if len(extra) > 0 {
+		problems = append(problems, fmt.Sprintf("in the certificate's SANs but not in domains: %s", strings.Join(extra, ", ")))
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("domains don't match the certificate: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// knownString returns the value of a string attribute of a configuration, if
+// it is set and known.
+func knownString(config cty.Value, key string) (string, bool) {
+	v := config.GetAttr(key)
+	if v.IsNull() || !v.IsKnown() {
+		return "", false
+	}
+	return v.AsString(), true
+}
+
+// knownStringSet returns the values of a set of strings attribute of a
+// configuration, if it is set and wholly known.
+func knownStringSet(config cty.Value, key string) ([]string, bool) {
+	v := config.GetAttr(key)
+	if v.IsNull() || !v.IsWhollyKnown() {
+		return nil, false
+	}
+	var s []string
+	for it := v.ElementIterator(); it.Next(); {
+		_, e := it.Element()
+		if !e.IsNull() {
+			s = append(s, e.AsString())
+		}
+	}
+	return s, true
+}
+
+// pemCheckError returns an error diagnostic for a failed check of attribute
+// key.
+func pemCheckError(key string, err error) diag.Diagnostic {
+	return diag.Diagnostic{
+		Severity:      diag.Error,
+		Summary:       err.Error(),
+		AttributePath: cty.GetAttrPath(key),
+	}
+}
+
+// validateTLSCertificateConsistency checks that the certificate of a
+// `fastly_tls_certificate` matches `expected_public_key_sha256` and
+// `domains`, when they are set.
+func validateTLSCertificateConsistency(_ context.Context, req schema.ValidateResourceConfigFuncRequest, resp *schema.ValidateResourceConfigFuncResponse) {
+	config := req.RawConfig
+	if config.IsNull() || !config.IsKnown() {
+		return
+	}
+	body, ok := knownString(config, "certificate_body")
+	if !ok {
+		return
+	}
+	certs, err := parsePEMCertificates(body)
+	if err != nil {
+		// The error is reported by the plan.
+		return
+	}
+
+	if want, ok := knownString(config, "expected_public_key_sha256"); ok {
+		if err := checkCertificatePublicKey(certs[0], want); err != nil {
+			resp.Diagnostics = append(resp.Diagnostics, pemCheckError("expected_public_key_sha256", err))
+		}
+	}
+	if domains, ok := knownStringSet(config, "domains"); ok {
+		if err := checkCertificateDomains(certs[0], domains); err != nil {
+			resp.Diagnostics = append(resp.Diagnostics, pemCheckError("domains", err))
+		}
+	}
+}
+
+// validateTLSPlatformCertificateConsistency checks that `intermediates_blob`
+// verifies to the certificate of a `fastly_tls_platform_certificate`, and
+// that it matches `domains`, when set.
+func validateTLSPlatformCertificateConsistency(_ context.Context, req schema.ValidateResourceConfigFuncRequest, resp *schema.ValidateResourceConfigFuncResponse) {
+	config := req.RawConfig
+	if config.IsNull() || !config.IsKnown() {
+		return
+	}
+	body, ok := knownString(config, "certificate_body")
+	if !ok {
+		return
+	}
+	certs, err := parsePEMCertificates(body)
+	if err != nil {
+		resp.Diagnostics = append(resp.Diagnostics, pemCheckError("certificate_body", err))
+		return
+	}
+
+	if blob, ok := knownString(config, "intermediates_blob"); ok {
+		chain, err := parsePEMCertificates(blob)
+		if err == nil {
+			err = checkCertificateChain(certs[0], chain)
+		}
+		if err != nil {
+			resp.Diagnostics = append(resp.Diagnostics, pemCheckError("intermediates_blob", err))
+		}
+	}
+	if domains, ok := knownStringSet(config, "domains"); ok {
+		if err := checkCertificateDomains(certs[0], domains); err != nil {
+			resp.Diagnostics = append(resp.Diagnostics, pemCheckError("domains", err))
+		}
+	}
+}
+
+// validateCACertificateBundle returns a schema validation function that
+// checks that a PEM bundle only contains CA certificates.
+func validateCACertificateBundle() schema.SchemaValidateDiagFunc {
+	return func(v any, path cty.Path) diag.Diagnostics {
+		certs, err := parsePEMCertificates(v.(string))
+		if err == nil {
+			err = checkCACertificates(certs)
+		}
+		if err != nil {
+			return diag.Diagnostics{{
+				Severity:      diag.Error,
+				Summary:       err.Error(),
+				AttributePath: path,
+			}}
+		}
+		return nil
+	}
+}
+
+// validateTLSCertificateRotationConsistency checks that the certificate of a
+// `fastly_tls_certificate_rotation` was issued for its private key.
+func validateTLSCertificateRotationConsistency(_ context.Context, req schema.ValidateResourceConfigFuncRequest, resp *schema.ValidateResourceConfigFuncResponse) {
+	config := req.RawConfig
+	if config.IsNull() || !config.IsKnown() {
+		return
+	}
+	body, ok := knownString(config, "certificate_body")
+	if !ok {
+		return
+	}
+	keyPEM, ok := knownString(config, "key_pem")
+	if !ok {
+		return
+	}
+
+	certs, err := parsePEMCertificates(body)
+	if err != nil {
+		resp.Diagnostics = append(resp.Diagnostics, pemCheckError("certificate_body", err))
+		return
+	}
+	sha, err := privateKeyPublicKeySHA256(keyPEM)
+	if err != nil {
+		resp.Diagnostics = append(resp.Diagnostics, pemCheckError("key_pem", err))
+		return
+	}
+	if err := checkCertificatePublicKey(certs[0], sha); err != nil {
+		resp.Diagnostics = append(resp.Diagnostics, pemCheckError("certificate_body", err))
+	}
+}
@@ -0,0 +1,145 @@
+package fastly
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrivateKeyPublicKeySHA256(t *testing.T) {
+	sha, err := privateKeyPublicKeySHA256(privateKey(t))
+	require.NoError(t, err)
+
+	certs, err := parsePEMCertificates(certificate(t))
+	require.NoError(t, err)
+	if err := checkCertificatePublicKey(certs[0], sha); err != nil {
+		t.Errorf("expected the fixture certificate to match the fixture key: %s", err)
+	}
+	if err := checkCertificatePublicKey(certs[0], strings.ToUpper(sha)); err != nil {
+		t.Errorf("expected the fingerprint to be case-insensitive: %s", err)
+	}
+
+	// The keys generated by the acceptance tests are PKCS #1 keys in a
+	// "PRIVATE KEY" block.
+	otherKey, err := generateKey()
+	require.NoError(t, err)
+	otherSHA, err := privateKeyPublicKeySHA256(otherKey)
+	require.NoError(t, err)
+	if err := checkCertificatePublicKey(certs[0], otherSHA); err == nil || !strings.Contains(err.Error(), "doesn't match the private key") {
+		t.Errorf("expected a mismatch error, got %v", err)
+	}
+
+	if _, err := privateKeyPublicKeySHA256(certificate(t)); err == nil {
+		t.Errorf("expected an error parsing a certificate as a private key")
+	}
+}
+
+func TestCheckCertificateChain(t *testing.T) {
+	_, cert, caPEM, err := generateKeyAndCertWithCA("www.example.com")
+	require.NoError(t, err)
+
+	leaf, err := parsePEMCertificates(cert)
+	require.NoError(t, err)
+	chain, err := parsePEMCertificates(caPEM)
+	require.NoError(t, err)
+
+	if err := checkCertificateChain(leaf[0], chain); err != nil {
+		t.Errorf("expected the chain to verify: %s", err)
+	}
+
+	// The fixture CA didn't sign the certificate.
+	unrelated, err := parsePEMCertificates(caCert(t))
+	require.NoError(t, err)
+	if err := checkCertificateChain(leaf[0], unrelated); err == nil || !strings.Contains(err.Error(), "no certificate of the chain signed") {
+		t.Errorf("expected a chain error, got %v", err)
+	}
+	// Certificates that aren't part of the path aren't ignored.
+	if err := checkCertificateChain(leaf[0], append(unrelated, chain...)); err == nil || !strings.Contains(err.Error(), "certificate 1 of the chain") {
+		t.Errorf("expected an error for the unrelated certificate, got %v", err)
+	}
+}
+
+func TestCheckCACertificates(t *testing.T) {
+	ca, err := parsePEMCertificates(caCert(t))
+	require.NoError(t, err)
+	if err := checkCACertificates(ca); err != nil {
+		t.Errorf("expected the fixture CA certificate to pass: %s", err)
+	}
+
+	// The fixture certificate is an X.509 v1 certificate without basic
+	// constraints.
+	leaf, err := parsePEMCertificates(certificate(t))
+	require.NoError(t, err)
+	if err := checkCACertificates(append(ca, leaf...)); err == nil || !strings.Contains(err.Error(), "certificate 2") {
+		t.Errorf("expected the second certificate to fail, got %v", err)
+	}
+
+	if diags := validateCACertificateBundle()(caCert(t)+"\n"+privateKey(t), cty.GetAttrPath("cert_bundle")); !diags.HasError() {
+		t.Errorf("expected a private key in the bundle to fail")
+	}
+}
+
+func TestCheckCertificateDomains(t *testing.T) {
+	_, cert, err := generateKeyAndCert("www.example.com", "*.example.com")
+	require.NoError(t, err)
+	certs, err := parsePEMCertificates(cert)
+	require.NoError(t, err)
+
+	if err := checkCertificateDomains(certs[0], []string{"*.example.com", "WWW.example.com"}); err != nil {
+		t.Errorf("expected the domains to match: %s", err)
+	}
+
+	err = checkCertificateDomains(certs[0], []string{"www.example.com", "api.example.com"})
+	want := "domains don't match the certificate: not in the certificate's SANs: api.example.com; in the certificate's SANs but not in domains: *.example.com"
+	if err == nil || err.Error() != want {
+		t.Errorf("expected error %q, got %v", want, err)
+	}
+}
+
+func TestValidateTLSPlatformCertificateConsistency(t *testing.T) {
+	_, cert, caPEM, err := generateKeyAndCertWithCA("www.example.com")
+	require.NoError(t, err)
+
+	config := func(intermediates string, domains ...string) cty.Value {
+		d := cty.NullVal(cty.Set(cty.String))
+		if len(domains) > 0 {
+			var vals []cty.Value
+			for _, domain := range domains {
+				vals = append(vals, cty.StringVal(domain))
+			}
+			d = cty.SetVal(vals)
+		}
+		return cty.ObjectVal(map[string]cty.Value{
+			"certificate_body":   cty.StringVal(cert),
+			"intermediates_blob": cty.StringVal(intermediates),
+			"domains":            d,
+		})
+	}
+
+	for name, tc := range map[string]struct {
+		config cty.Value
+		paths  []string
+	}{
+		"valid":          {config(caPEM, "www.example.com"), nil},
+		"no domains":     {config(caPEM), nil},
+		"wrong chain":    {config(caCert(t)), []string{"intermediates_blob"}},
+		"wrong domains":  {config(caPEM, "api.example.com"), []string{"domains"}},
+		"invalid chain":  {config("invalid"), []string{"intermediates_blob"}},
+		"chain and name": {config(caCert(t), "api.example.com"), []string{"intermediates_blob", "domains"}},
+	} {
+		var resp schema.ValidateResourceConfigFuncResponse
+		validateTLSPlatformCertificateConsistency(context.Background(), schema.ValidateResourceConfigFuncRequest{RawConfig: tc.config}, &resp)
+
+		var paths []string
+		for _, d := range resp.Diagnostics {
+			paths = append(paths, d.AttributePath[0].(cty.GetAttrStep).Name)
+		}
+		if strings.Join(paths, ",") != strings.Join(tc.paths, ",") {
+			t.Errorf("%s: expected diagnostics for %v, got %v", name, tc.paths, resp.Diagnostics)
+		}
+	}
+}
@@ -3,7 +3,9 @@ package fastly
 import (
 	"encoding/pem"
 	"fmt"
+	"path"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
@@ -188,6 +190,32 @@ func validatePEMBlocks(pemType string) schema.SchemaValidateDiagFunc {
 	})
 }
 
+// validateDuration returns a schema validation function that checks whether a
+// string can be parsed as a positive time.Duration (e.g. `500ms`, `30s`).
+func validateDuration() schema.SchemaValidateDiagFunc {
+	return validation.ToDiagFunc(func(val any, key string) ([]string, []error) {
+		d, err := time.ParseDuration(val.(string))
+		if err != nil {
+			return nil, []error{fmt.Errorf("expected %s to be a valid duration (e.g. 500ms, 30s, 1m): %s", key, err)}
+		}
+		if d <= 0 {
+			return nil, []error{fmt.Errorf("expected %s to be a positive duration, got %s", key, val)}
+		}
+		return nil, nil
+	})
+}
+
+// validateTLSDomainGlob returns a schema validation function that checks
+// whether a string is a valid domain glob (e.g. `*.example.com`).
+func validateTLSDomainGlob() schema.SchemaValidateDiagFunc {
+	return validation.ToDiagFunc(func(val any, key string) ([]string, []error) {
+		if _, err := path.Match(val.(string), ""); err != nil {
+			return nil, []error{fmt.Errorf("expected %s to be a valid glob (e.g. *.example.com): %s", key, err)}
+		}
+		return nil, nil
+	})
+}
+
 func validateStringTrimmed(i any, path cty.Path) diag.Diagnostics {
 	v := i.(string)
 	attr := path[len(path)-1].(cty.GetAttrStep)
@@ -402,3 +402,29 @@ func TestValidateMultiplePEMBlocks(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateDuration(t *testing.T) {
+	for _, testcase := range []struct {
+		value          string
+		expectedWarns  int
+		expectedErrors int
+	}{
+		{"500ms", 0, 0},
+		{"30s", 0, 0},
+		{"1m", 0, 0},
+		{"0s", 0, 1},
+		{"-1s", 0, 1},
+		{"30", 0, 1},
+		{"", 0, 1},
+	} {
+		t.Run(testcase.value, func(t *testing.T) {
+			actualWarns, actualErrors := diagToWarnsAndErrs(validateDuration()(testcase.value, cty.GetAttrPath("max_wait")))
+			if len(actualWarns) != testcase.expectedWarns {
+				t.Errorf("expected %d warnings, actual %d ", testcase.expectedWarns, len(actualWarns))
+			}
+			if len(actualErrors) != testcase.expectedErrors {
+				t.Errorf("expected %d errors, actual %d ", testcase.expectedErrors, len(actualErrors))
+			}
+		})
+	}
+}
@@ -0,0 +1,344 @@
+//go:debug randseednop=0
+
+package fastly
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/dnaeon/go-vcr/cassette"
+	"github.com/dnaeon/go-vcr/recorder"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	gofastly "github.com/fastly/go-fastly/v12/fastly"
+
+	"github.com/fastly/terraform-provider-fastly/version"
+)
+
+const (
+	// vcrModeEnvVar selects whether acceptance tests record API interactions
+	// into cassettes ("record") or replay them without network ("replay").
+	vcrModeEnvVar = "FASTLY_VCR_MODE"
+	// vcrSeed seeds the random names generated by the acceptance tests, so
+	// that replayed requests match the recorded ones.
+	vcrSeed = 1
+	// vcrReplayAPIKey is used in replay mode when no API key is set, as the
+	// provider refuses to configure without one.
+	vcrReplayAPIKey = "vcr-replay"
+)
+
+// vcrCassetteDir is where cassettes are stored, one per acceptance test.
+var vcrCassetteDir = filepath.Join("test_fixtures", "cassettes")
+
+// vcrSensitiveHeaders are removed from recorded interactions.
+var vcrSensitiveHeaders = []string{
+	gofastly.APIKeyHeader,
+	"Authorization",
+	"Cookie",
+	"Set-Cookie",
+}
+
+// vcrVolatileID matches Fastly identifiers (e.g. service, store and resource
+// IDs), which are ignored when matching requests against a cassette.
+var vcrVolatileID = regexp.MustCompile(`\b[0-9A-Za-z]{22}\b`)
+
+// vcrTransport routes API requests to the recorder of the running acceptance
+// test. A single transport is shared by testAccProvider, so only one test can
+// record or replay at a time (i.e. tests must be run with -parallel=1).
+type vcrTransport struct {
+	mu       sync.Mutex
+	recorder *recorder.Recorder
+}
+
+var testAccVCR = &vcrTransport{}
+
+// RoundTrip implements http.RoundTripper.
+func (t *vcrTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	r := t.recorder
+	t.mu.Unlock()
+
+	if r == nil {
+		return nil, fmt.Errorf("%s is set but no cassette is loaded for %s %s (is testAccPreCheck called?)", vcrModeEnvVar, req.Method, req.URL.Path)
+	}
+	return r.RoundTrip(req)
+}
+
+// testAccConfigureVCR routes the API requests of the given provider through
+// the cassette of the running test, if FASTLY_VCR_MODE is set.
+func testAccConfigureVCR(p *schema.Provider) {
+	if testAccVCRMode() == recorder.ModeDisabled {
+		return
+	}
+
+	p.ConfigureContextFunc = func(_ context.Context, d *schema.ResourceData) (any, diag.Diagnostics) {
+		config := expandProviderConfig(d, p.UserAgent(TerraformProviderProductUserAgent, version.ProviderVersion))
+		config.Transport = testAccVCR
+		return config.Client()
+	}
+
+	// NOTE: The acceptance tests generate resource names using math/rand, so
+	// it is seeded with a fixed value to make the names (and therefore the
+	// requests) the same between recording and replaying. This requires the
+	// same set of tests to be selected (i.e. the same -run flag) in both modes.
+	rand.Seed(vcrSeed) //nolint:staticcheck // randseednop=0 is set above.
+}
+
+// testAccVCRMode returns the recorder mode selected by FASTLY_VCR_MODE.
+func testAccVCRMode() recorder.Mode {
+	switch os.Getenv(vcrModeEnvVar) {
+	case "record":
+		return recorder.ModeRecording
+	case "replay":
+		return recorder.ModeReplaying
+	default:
+		return recorder.ModeDisabled
+	}
+}
+
+// testAccStartVCR loads the cassette for the running test, which is saved
+// (in record mode) when the test finishes.
+func testAccStartVCR(t *testing.T, mode recorder.Mode) {
+	name := filepath.Join(vcrCassetteDir, strings.ReplaceAll(t.Name(), "/", "_"))
+
+	r, err := newVCRRecorder(name, mode, http.DefaultTransport)
+	if errors.Is(err, fs.ErrNotExist) {
+		// Skipping would let a replay run pass without testing anything.
+		t.Fatalf("no cassette recorded at %s.yaml, record it with `make testacc-record VCR_TESTARGS='-run ^%s$'`", name, t.Name())
+	}
+	if err != nil {
+		t.Fatalf("failed to load cassette %s: %s", name, err)
+	}
+
+	testAccVCR.mu.Lock()
+	defer testAccVCR.mu.Unlock()
+	if testAccVCR.recorder != nil {
+		t.Fatalf("%s requires acceptance tests to run sequentially (-parallel=1)", vcrModeEnvVar)
+	}
+	testAccVCR.recorder = r
+
+	t.Cleanup(func() {
+		testAccVCR.mu.Lock()
+		testAccVCR.recorder = nil
+		testAccVCR.mu.Unlock()
+
+		if err := r.Stop(); err != nil {
+			t.Errorf("failed to save cassette %s: %s", name, err)
+		}
+	})
+}
+
+// newVCRRecorder returns a recorder for the named cassette that matches
+// requests ignoring volatile IDs and strips credentials before saving.
+func newVCRRecorder(name string, mode recorder.Mode, transport http.RoundTripper) (*recorder.Recorder, error) {
+	// NOTE: go-vcr falls back to recording when a cassette is missing, which
+	// would send requests to the API while replaying.
+	if mode == recorder.ModeReplaying {
+		if _, err := os.Stat(name + ".yaml"); err != nil {
+			return nil, err
+		}
+	}
+
+	r, err := recorder.NewAsMode(name, mode, transport)
+	if err != nil {
+		return nil, err
+	}
+	r.SetMatcher(vcrMatcher)
+	r.AddSaveFilter(vcrSaveFilter)
+	return r, nil
+}
+
+// vcrMatcher matches requests on their method, path, query and body, with
+// volatile IDs masked. Headers (including the API key) are ignored.
+func vcrMatcher(r *http.Request, i cassette.Request) bool {
+	if r.Method != i.Method {
+		return false
+	}
+
+	u, err := url.Parse(i.URL)
+	if err != nil {
+		return false
+	}
+	if r.URL.Host != u.Host || maskVolatileIDs(r.URL.Path) != maskVolatileIDs(u.Path) {
+		return false
+	}
+	if maskVolatileIDs(normalizeQuery(r.URL.Query())) != maskVolatileIDs(normalizeQuery(u.Query())) {
+		return false
+	}
+
+	var body []byte
+	if r.Body != nil && r.Body != http.NoBody {
+		body, err = io.ReadAll(r.Body)
+		if err != nil {
+			return false
+		}
+		// The body is read for every recorded interaction, so it is restored.
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	return maskVolatileIDs(string(body)) == maskVolatileIDs(i.Body)
+}
+
+// vcrSaveFilter removes credentials from an interaction before it is saved.
+func vcrSaveFilter(i *cassette.Interaction) error {
+	for _, h := range vcrSensitiveHeaders {
+		i.Request.Headers.Del(h)
+		i.Response.Headers.Del(h)
+	}
+	return nil
+}
+
+// maskVolatileIDs replaces Fastly IDs with a fixed placeholder.
+func maskVolatileIDs(s string) string {
+	return vcrVolatileID.ReplaceAllString(s, "<id>")
+}
+
+// normalizeQuery encodes query parameters in a stable order.
+func normalizeQuery(v url.Values) string {
+	// NOTE: url.Values.Encode sorts by key.
+	return v.Encode()
+}
+
+func TestVCRMatcher(t *testing.T) {
+	recorded := cassette.Request{
+		Method: http.MethodPut,
+		URL:    "https://api.fastly.com/service/7i6HN3TK9wS159v2gPAZ8A/version/1/backend/tf-test?b=2&a=1",
+		Body:   "address=example.com&service_id=7i6HN3TK9wS159v2gPAZ8A",
+		Headers: http.Header{
+			gofastly.APIKeyHeader: []string{"recorded"},
+		},
+	}
+
+	cases := []struct {
+		name   string
+		method string
+		url    string
+		body   string
+		want   bool
+	}{
+		{
+			name:   "different ids and query order",
+			method: http.MethodPut,
+			url:    "https://api.fastly.com/service/SU1Z0isxPaozGVKXdv0eYA/version/1/backend/tf-test?a=1&b=2",
+			body:   "address=example.com&service_id=SU1Z0isxPaozGVKXdv0eYA",
+			want:   true,
+		},
+		{
+			name:   "different method",
+			method: http.MethodPost,
+			url:    "https://api.fastly.com/service/7i6HN3TK9wS159v2gPAZ8A/version/1/backend/tf-test?a=1&b=2",
+			body:   "address=example.com&service_id=7i6HN3TK9wS159v2gPAZ8A",
+		},
+		{
+			name:   "different name",
+			method: http.MethodPut,
+			url:    "https://api.fastly.com/service/7i6HN3TK9wS159v2gPAZ8A/version/1/backend/tf-other?a=1&b=2",
+			body:   "address=example.com&service_id=7i6HN3TK9wS159v2gPAZ8A",
+		},
+		{
+			name:   "different version",
+			method: http.MethodPut,
+			url:    "https://api.fastly.com/service/7i6HN3TK9wS159v2gPAZ8A/version/2/backend/tf-test?a=1&b=2",
+			body:   "address=example.com&service_id=7i6HN3TK9wS159v2gPAZ8A",
+		},
+		{
+			name:   "different body",
+			method: http.MethodPut,
+			url:    "https://api.fastly.com/service/7i6HN3TK9wS159v2gPAZ8A/version/1/backend/tf-test?a=1&b=2",
+			body:   "address=example.net&service_id=7i6HN3TK9wS159v2gPAZ8A",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := httptest.NewRequest(c.method, c.url, strings.NewReader(c.body))
+			req.Header.Set(gofastly.APIKeyHeader, "live")
+
+			if got := vcrMatcher(req, recorded); got != c.want {
+				t.Fatalf("expected match %t, got %t", c.want, got)
+			}
+			// The body must remain readable for the request to be sent.
+			body, err := io.ReadAll(req.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(body) != c.body {
+				t.Fatalf("expected body %q to be restored, got %q", c.body, body)
+			}
+		})
+	}
+}
+
+func TestVCRRecordAndReplay(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get(gofastly.APIKeyHeader) != "live-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"7i6HN3TK9wS159v2gPAZ8A","name":"tf-test","versions":[]}`))
+	}))
+	defer srv.Close()
+
+	name := filepath.Join(t.TempDir(), "cassette")
+
+	getService := func(apiKey string, mode recorder.Mode) (*gofastly.ServiceDetail, error) {
+		r, err := newVCRRecorder(name, mode, http.DefaultTransport)
+		if err != nil {
+			t.Fatalf("failed to create recorder: %s", err)
+		}
+		defer func() {
+			if err := r.Stop(); err != nil {
+				t.Fatalf("failed to save cassette: %s", err)
+			}
+		}()
+
+		c := Config{APIKey: apiKey, BaseURL: srv.URL, Transport: r}
+		client, diags := c.Client()
+		if diags.HasError() {
+			t.Fatalf("failed to create client: %s", diagToErr(diags))
+		}
+		return client.conn.GetServiceDetails(context.TODO(), &gofastly.GetServiceInput{
+			ServiceID: "7i6HN3TK9wS159v2gPAZ8A",
+		})
+	}
+
+	if _, err := getService("live-key", recorder.ModeRecording); err != nil {
+		t.Fatalf("failed to record: %s", err)
+	}
+
+	cassetteFile, err := os.ReadFile(name + ".yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(cassetteFile, []byte("live-key")) {
+		t.Fatal("expected the API key to be removed from the cassette")
+	}
+
+	service, err := getService(vcrReplayAPIKey, recorder.ModeReplaying)
+	if err != nil {
+		t.Fatalf("failed to replay: %s", err)
+	}
+	if gofastly.ToValue(service.Name) != "tf-test" {
+		t.Fatalf("expected replayed service name tf-test, got %q", gofastly.ToValue(service.Name))
+	}
+	if requests != 1 {
+		t.Fatalf("expected 1 request to reach the API, got %d", requests)
+	}
+}